@@ -136,6 +136,16 @@ func (a *Agent) Run(ctx context.Context) error {
 		}
 	}
 
+	// Serve the internal statistics before connecting the outputs to also
+	// cover outputs stuck while connecting
+	if a.Config.Agent.SelfstatListen != "" {
+		server, err := startSelfstatServer(a.Config.Agent.SelfstatListen)
+		if err != nil {
+			return err
+		}
+		defer server.stop()
+	}
+
 	startTime := time.Now()
 
 	log.Printf("D! [agent] Connecting outputs")
@@ -267,6 +277,13 @@ func (a *Agent) initPersister() error {
 	}
 
 	for _, input := range a.Config.Inputs {
+		// Keep track of scheduled gathers to catch up after restarts
+		if state := input.ScheduleState(); state != nil {
+			if err := a.Config.Persister.Register(input.ID()+"_schedule", state); err != nil {
+				return fmt.Errorf("could not register schedule of input %s: %w", input.LogName(), err)
+			}
+		}
+
 		plugin, ok := input.Input.(telegraf.StatefulPlugin)
 		if !ok {
 			continue
@@ -428,7 +445,16 @@ func (a *Agent) runInputs(ctx context.Context, startTime time.Time, unit *inputU
 			offset = input.Config.CollectionOffset
 		}
 
-		ticker := clock.NewTicker(interval, jitter, offset, options...)
+		// Gather scheduled inputs at the times given by the schedule and
+		// catch up on a gather missed since the last scheduled one
+		var ticker *clock.Ticker
+		if schedule := input.Schedule(); schedule != nil {
+			interval = clock.ScheduleInterval(schedule, startTime)
+			opts := []clock.Option{clock.WithSchedule(schedule), clock.WithLastTick(input.ScheduleState().Last())}
+			ticker = clock.NewTicker(interval, jitter, 0, opts...)
+		} else {
+			ticker = clock.NewTicker(interval, jitter, offset, options...)
+		}
 		tickers = append(tickers, ticker)
 
 		acc := NewAccumulator(input, unit.dst)
@@ -570,7 +596,10 @@ func (a *Agent) gatherLoop(
 ) {
 	for {
 		select {
-		case <-ticker.C:
+		case ts := <-ticker.C:
+			if state := input.ScheduleState(); state != nil {
+				state.Update(ts)
+			}
 			err := a.gatherOnce(acc, input, ticker, interval)
 			if err != nil {
 				acc.AddError(err)
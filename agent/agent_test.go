@@ -1,10 +1,14 @@
 package agent
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"io"
+	"net/http"
 	"os"
 	"path/filepath"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -20,6 +24,7 @@ import (
 	_ "github.com/influxdata/telegraf/plugins/outputs/all"
 	"github.com/influxdata/telegraf/plugins/parsers/influx"
 	_ "github.com/influxdata/telegraf/plugins/processors/all"
+	"github.com/influxdata/telegraf/selfstat"
 	"github.com/influxdata/telegraf/testutil"
 )
 
@@ -118,6 +123,31 @@ func TestAgent_LoadOutput(t *testing.T) {
 	require.Len(t, a.Config.Outputs, 3)
 }
 
+func TestSelfstatServer(t *testing.T) {
+	stat := selfstat.Register("test", "selfstat_server", map[string]string{"test": "server"})
+	defer stat.Unregister()
+	stat.Set(42)
+	counter := selfstat.Register("test", "selfstat_server_errors", map[string]string{"test": "server"})
+	defer counter.Unregister()
+	counter.Incr(3)
+
+	server, err := startSelfstatServer("127.0.0.1:0")
+	require.NoError(t, err)
+	defer server.stop()
+
+	resp, err := http.Get("http://" + server.addr.String() + "/metrics")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, selfstat.OpenMetricsContentType, resp.Header.Get("Content-Type"))
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.Contains(t, string(body), "# TYPE telegraf_test_selfstat_server gauge\n"+`telegraf_test_selfstat_server{test="server"} 42`)
+	require.Contains(t, string(body), "# TYPE telegraf_test_selfstat_server_errors counter\n"+`telegraf_test_selfstat_server_errors_total{test="server"} 3`)
+	require.True(t, strings.HasSuffix(string(body), "# EOF\n"))
+}
+
 func TestWindow(t *testing.T) {
 	parse := func(s string) time.Time {
 		tm, err := time.Parse(time.RFC3339, s)
@@ -195,6 +225,7 @@ func TestCases(t *testing.T) {
 		testdataPath := filepath.Join("testcases", fname)
 		configFilename := filepath.Join(testdataPath, "telegraf.conf")
 		expectedFilename := filepath.Join(testdataPath, "expected.out")
+		replayFilename := filepath.Join(testdataPath, "replay.influx")
 
 		t.Run(fname, func(t *testing.T) {
 			// Get parser to parse input and expected output
@@ -211,6 +242,24 @@ func TestCases(t *testing.T) {
 			require.NoError(t, cfg.LoadAll(configFilename))
 			require.Empty(t, cfg.Outputs, "No output(s) allowed in the config!")
 
+			// Cases with a recording are replayed instead of gathering the
+			// inputs. The results are compared including the timestamps as
+			// those must be deterministic.
+			if _, err := os.Stat(replayFilename); err == nil {
+				input, err := testutil.ParseMetricsFromFile(replayFilename, parser)
+				require.NoError(t, err)
+				require.NotEmpty(t, input)
+				require.Empty(t, cfg.Inputs, "No input(s) allowed in replay cases!")
+
+				var buf bytes.Buffer
+				require.NoError(t, NewAgent(cfg).Replay(t.Context(), input, &buf))
+
+				actual, err := parser.Parse(buf.Bytes())
+				require.NoError(t, err)
+				testutil.RequireMetricsEqual(t, expected, actual)
+				return
+			}
+
 			// Setup the agent and run the agent in "once" mode
 			agent := NewAgent(cfg)
 			ctx, cancel := context.WithTimeout(t.Context(), 5*time.Second)
@@ -0,0 +1,295 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"slices"
+	"sync/atomic"
+	"time"
+
+	"github.com/benbjohnson/clock"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/models"
+	"github.com/influxdata/telegraf/plugins/serializers/influx"
+)
+
+// Replay feeds the given recorded metrics through the configured processors
+// and aggregators and writes the resulting metrics in influx line protocol
+// to the given writer. Inputs and outputs are not used.
+//
+// In contrast to Test and Once, the metrics are processed sequentially using
+// a simulated clock following the metric timestamps. Aggregation windows are
+// derived from the metrics instead of the wall-clock, so the same config and
+// recording always produce the same result.
+func (a *Agent) Replay(ctx context.Context, metrics []telegraf.Metric, w io.Writer) error {
+	if a.Config.Agent.SkipProcessorsAfterAggregators == nil {
+		skipProcessorsAfterAggregators := false
+		a.Config.Agent.SkipProcessorsAfterAggregators = &skipProcessorsAfterAggregators
+	}
+
+	log.Printf("D! [agent] Initializing plugins")
+	if err := a.InitPlugins(); err != nil {
+		return err
+	}
+
+	results, err := a.replay(ctx, metrics)
+	if err != nil {
+		return err
+	}
+
+	s := &influx.Serializer{SortFields: true, UintSupport: true}
+	if err := s.Init(); err != nil {
+		return err
+	}
+	for _, m := range results {
+		octets, err := s.Serialize(m)
+		if err != nil {
+			log.Printf("E! [agent] Serializing metric %q failed: %v", m.Name(), err)
+			continue
+		}
+		if _, err := w.Write(octets); err != nil {
+			return fmt.Errorf("writing result failed: %w", err)
+		}
+	}
+	log.Printf("D! [agent] Replayed %d metrics resulting in %d metrics", len(metrics), len(results))
+
+	return nil
+}
+
+// replay runs the processor and aggregator stages on the given metrics and
+// returns the resulting metrics sorted by time.
+func (a *Agent) replay(ctx context.Context, metrics []telegraf.Metric) ([]telegraf.Metric, error) {
+	// Process the metrics in the order of their timestamps to be able to
+	// advance the simulated clock monotonically.
+	metrics = slices.Clone(metrics)
+	slices.SortStableFunc(metrics, func(a, b telegraf.Metric) int {
+		return a.Time().Compare(b.Time())
+	})
+
+	clk := newReplayClock()
+	if len(metrics) > 0 {
+		clk.advance(metrics[0].Time())
+	}
+
+	processed, err := replayProcessors(ctx, clk, a.Config.Processors, metrics)
+	if err != nil {
+		return nil, err
+	}
+	if len(a.Config.Aggregators) == 0 {
+		return processed, nil
+	}
+
+	passed, aggregated := a.replayAggregators(clk, processed)
+	if len(a.Config.AggProcessors) != 0 && !*a.Config.Agent.SkipProcessorsAfterAggregators {
+		aggregated, err = replayProcessors(ctx, clk, a.Config.AggProcessors, aggregated)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	results := append(passed, aggregated...)
+	slices.SortStableFunc(results, func(a, b telegraf.Metric) int {
+		return a.Time().Compare(b.Time())
+	})
+	return results, nil
+}
+
+// replayProcessors runs the given metrics through the processor chain one
+// processor at a time. Each processor is stopped after handling all metrics
+// to flush any pending state.
+func replayProcessors(
+	ctx context.Context,
+	clk *replayClock,
+	processors models.RunningProcessors,
+	metrics []telegraf.Metric,
+) ([]telegraf.Metric, error) {
+	for _, processor := range processors {
+		dst := make(chan telegraf.Metric, 100)
+		done := make(chan []telegraf.Metric)
+		go func() {
+			var received []telegraf.Metric
+			for m := range dst {
+				received = append(received, m)
+			}
+			done <- received
+		}()
+
+		acc := &replayAccumulator{Accumulator: NewAccumulator(processor, dst), clk: clk}
+		if err := processor.Start(acc); err != nil {
+			close(dst)
+			<-done
+			return nil, fmt.Errorf("starting processor %s: %w", processor.LogName(), err)
+		}
+
+		for _, m := range metrics {
+			if ctx.Err() != nil {
+				break
+			}
+			clk.advance(m.Time())
+			if err := processor.Add(m, acc); err != nil {
+				acc.AddError(err)
+				m.Drop()
+			}
+		}
+		processor.Stop()
+		close(dst)
+		metrics = <-done
+
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+	}
+	return metrics, nil
+}
+
+// replayAggregators adds the given time-sorted metrics to the aggregators and
+// pushes an aggregator whenever a metric passes the end of its window. The
+// first return value contains the original metrics not dropped by any
+// aggregator, the second one the aggregated metrics.
+func (a *Agent) replayAggregators(clk *replayClock, metrics []telegraf.Metric) (passed, aggregated []telegraf.Metric) {
+	dst := make(chan telegraf.Metric, 100)
+	done := make(chan []telegraf.Metric)
+	go func() {
+		var received []telegraf.Metric
+		for m := range dst {
+			received = append(received, m)
+		}
+		done <- received
+	}()
+
+	interval := time.Duration(a.Config.Agent.Interval)
+	precision := time.Duration(a.Config.Agent.Precision)
+
+	accumulators := make([]telegraf.Accumulator, 0, len(a.Config.Aggregators))
+	for _, agg := range a.Config.Aggregators {
+		agg.SetClock(clk)
+		since, until := updateWindow(clk.Now(), a.Config.Agent.RoundInterval, agg.Period())
+		agg.UpdateWindow(since, until)
+
+		acc := NewAccumulator(agg, dst)
+		acc.SetPrecision(getPrecision(precision, interval))
+		accumulators = append(accumulators, &replayAccumulator{Accumulator: acc, clk: clk})
+	}
+
+	// Push all aggregators with windows ending before the given time in the
+	// order of the window ends, just like the agent would do in real-time.
+	pushUntil := func(t time.Time) {
+		for {
+			idx := -1
+			for i, agg := range a.Config.Aggregators {
+				if agg.EndPeriod().After(t) {
+					continue
+				}
+				if idx < 0 || agg.EndPeriod().Before(a.Config.Aggregators[idx].EndPeriod()) {
+					idx = i
+				}
+			}
+			if idx < 0 {
+				return
+			}
+			agg := a.Config.Aggregators[idx]
+			clk.advance(agg.EndPeriod())
+			agg.Push(accumulators[idx])
+		}
+	}
+
+	for _, m := range metrics {
+		pushUntil(m.Time())
+		clk.advance(m.Time())
+
+		var dropOriginal bool
+		for _, agg := range a.Config.Aggregators {
+			if ok := agg.Add(m); ok {
+				dropOriginal = true
+			}
+		}
+		if !dropOriginal {
+			passed = append(passed, m)
+		} else {
+			m.Drop()
+		}
+	}
+
+	// Flush the last window of every aggregator
+	for i, agg := range a.Config.Aggregators {
+		clk.advance(agg.EndPeriod())
+		agg.Push(accumulators[i])
+	}
+	close(dst)
+
+	return passed, <-done
+}
+
+// replayAccumulator uses the simulated clock for metrics created without an
+// explicit timestamp, e.g. by aggregators on push.
+type replayAccumulator struct {
+	telegraf.Accumulator
+	clk clock.Clock
+}
+
+func (a *replayAccumulator) AddFields(measurement string, fields map[string]interface{}, tags map[string]string, t ...time.Time) {
+	a.Accumulator.AddFields(measurement, fields, tags, a.timestamp(t))
+}
+
+func (a *replayAccumulator) AddGauge(measurement string, fields map[string]interface{}, tags map[string]string, t ...time.Time) {
+	a.Accumulator.AddGauge(measurement, fields, tags, a.timestamp(t))
+}
+
+func (a *replayAccumulator) AddCounter(measurement string, fields map[string]interface{}, tags map[string]string, t ...time.Time) {
+	a.Accumulator.AddCounter(measurement, fields, tags, a.timestamp(t))
+}
+
+func (a *replayAccumulator) AddSummary(measurement string, fields map[string]interface{}, tags map[string]string, t ...time.Time) {
+	a.Accumulator.AddSummary(measurement, fields, tags, a.timestamp(t))
+}
+
+func (a *replayAccumulator) AddHistogram(measurement string, fields map[string]interface{}, tags map[string]string, t ...time.Time) {
+	a.Accumulator.AddHistogram(measurement, fields, tags, a.timestamp(t))
+}
+
+func (a *replayAccumulator) timestamp(t []time.Time) time.Time {
+	if len(t) > 0 {
+		return t[0]
+	}
+	return a.clk.Now()
+}
+
+// replayClock is a simulated clock following the timestamps of the replayed
+// metrics. Only the current time is used during replay, so advancing the clock
+// skips the timer handling of the mock clock which would add a delay for each
+// metric.
+type replayClock struct {
+	*clock.Mock
+	now atomic.Int64
+}
+
+func newReplayClock() *replayClock {
+	return &replayClock{Mock: clock.NewMock()}
+}
+
+// advance moves the clock forward to the given time. The clock never goes
+// backward.
+func (c *replayClock) advance(t time.Time) {
+	ts := t.UnixNano()
+	for {
+		current := c.now.Load()
+		if ts <= current || c.now.CompareAndSwap(current, ts) {
+			return
+		}
+	}
+}
+
+func (c *replayClock) Now() time.Time {
+	return time.Unix(0, c.now.Load())
+}
+
+func (c *replayClock) Since(t time.Time) time.Duration {
+	return c.Now().Sub(t)
+}
+
+func (c *replayClock) Until(t time.Time) time.Duration {
+	return t.Sub(c.Now())
+}
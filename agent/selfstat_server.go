@@ -0,0 +1,66 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/influxdata/telegraf/selfstat"
+)
+
+// selfstatServer serves the internal statistics in OpenMetrics format. The
+// server runs independently of the metric pipeline, so the statistics stay
+// available even if outputs are stuck.
+type selfstatServer struct {
+	server *http.Server
+	addr   net.Addr
+	done   chan struct{}
+}
+
+func startSelfstatServer(address string) (*selfstatServer, error) {
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return nil, fmt.Errorf("listening for internal statistics failed: %w", err)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", selfstat.OpenMetricsContentType)
+		if err := selfstat.WriteOpenMetrics(w); err != nil {
+			log.Printf("E! [agent] Serving internal statistics failed: %v", err)
+		}
+	})
+
+	s := &selfstatServer{
+		server: &http.Server{
+			Handler:           mux,
+			ReadHeaderTimeout: 10 * time.Second,
+		},
+		addr: listener.Addr(),
+		done: make(chan struct{}),
+	}
+
+	go func() {
+		defer close(s.done)
+		if err := s.server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Printf("E! [agent] Serving internal statistics failed: %v", err)
+		}
+	}()
+	log.Printf("I! [agent] Serving internal statistics on http://%s/metrics", s.addr)
+
+	return s, nil
+}
+
+func (s *selfstatServer) stop() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := s.server.Shutdown(ctx); err != nil {
+		log.Printf("E! [agent] Stopping internal statistics server failed: %v", err)
+	}
+	<-s.done
+}
@@ -1,5 +1,7 @@
 package telegraf
 
+import "time"
+
 // Aggregator is an interface for implementing an Aggregator plugin.
 // the RunningAggregator wraps this interface and guarantees that
 // Add, Push, and Reset can not be called concurrently, so locking is not
@@ -16,3 +18,17 @@ type Aggregator interface {
 	// Reset resets the aggregators caches and aggregates.
 	Reset()
 }
+
+// WindowedAggregator is an Aggregator depending on the aggregation period
+// and window, e.g. to aggregate over multiples of the period.
+type WindowedAggregator interface {
+	Aggregator
+
+	// SetPeriod sets the aggregation period. The function is called before
+	// Init.
+	SetPeriod(period time.Duration)
+
+	// SetWindowEnd sets the end of the aggregation window pushed next. The
+	// function is called before each Push.
+	SetWindowEnd(end time.Time)
+}
@@ -17,6 +17,7 @@ import (
 	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/logger"
 	"github.com/influxdata/telegraf/migrations"
+	"github.com/influxdata/telegraf/plugins/parsers/csv"
 )
 
 func getConfigCommands(configHandlingFlags []cli.Flag, outputBuffer io.Writer) []*cli.Command {
@@ -136,6 +137,95 @@ InfluxDB v2 output plugin use
 						return nil
 					},
 				},
+				{
+					Name:      "csv-schema",
+					Usage:     "infer the schema of a CSV file for pinning it in the csv parser configuration",
+					ArgsUsage: "<file>",
+					Description: `
+The 'csv-schema' command samples the rows of the given CSV file in the same way
+as the csv parser with 'csv_infer_schema' enabled and prints the inferred
+column names, types, tags and timestamp together with the options used for
+reading the file as parser options. Those options can be used to replace the
+inference by a fixed schema.
+
+To infer the schema of the semicolon separated file 'data.csv' use
+
+> telegraf config csv-schema --delimiter ";" data.csv
+`,
+					Flags: []cli.Flag{
+						&cli.IntFlag{
+							Name:  "header-row-count",
+							Usage: "number of header rows",
+							Value: 1,
+						},
+						&cli.IntFlag{
+							Name:  "skip-rows",
+							Usage: "number of rows to skip before the header",
+						},
+						&cli.IntFlag{
+							Name:  "skip-columns",
+							Usage: "number of columns to skip",
+						},
+						&cli.StringFlag{
+							Name:  "delimiter",
+							Usage: "separator between the values",
+						},
+						&cli.StringFlag{
+							Name:  "comment",
+							Usage: "character marking comment rows",
+						},
+						&cli.BoolFlag{
+							Name:  "trim-space",
+							Usage: "remove leading whitespace from the values",
+						},
+						&cli.StringSliceFlag{
+							Name:  "skip-values",
+							Usage: "values to treat as missing, e.g. placeholders like \"N/A\"",
+						},
+						&cli.IntFlag{
+							Name:  "infer-rows",
+							Usage: "number of rows sampled to infer the column types",
+							Value: 100,
+						},
+						&cli.BoolFlag{
+							Name:  "infer-tags",
+							Usage: "use string columns with repeating values as tags",
+						},
+					},
+					Action: func(cCtx *cli.Context) error {
+						if cCtx.NArg() != 1 {
+							return errors.New("exactly one CSV file must be specified")
+						}
+						data, err := os.ReadFile(cCtx.Args().First())
+						if err != nil {
+							return fmt.Errorf("reading CSV file failed: %w", err)
+						}
+
+						parser := &csv.Parser{
+							HeaderRowCount: cCtx.Int("header-row-count"),
+							SkipRows:       cCtx.Int("skip-rows"),
+							SkipColumns:    cCtx.Int("skip-columns"),
+							Delimiter:      cCtx.String("delimiter"),
+							Comment:        cCtx.String("comment"),
+							TrimSpace:      cCtx.Bool("trim-space"),
+							SkipValues:     cCtx.StringSlice("skip-values"),
+							InferSchema:    true,
+							InferRows:      cCtx.Int("infer-rows"),
+							InferTags:      cCtx.Bool("infer-tags"),
+							SkipErrors:     true,
+							Log:            logger.New("parsers", "csv", ""),
+						}
+						if err := parser.Init(); err != nil {
+							return err
+						}
+						if _, err := parser.Parse(data); err != nil {
+							return fmt.Errorf("parsing CSV file failed: %w", err)
+						}
+
+						_, err = outputBuffer.Write([]byte(parser.Schema()))
+						return err
+					},
+				},
 				{
 					Name:  "migrate",
 					Usage: "migrate deprecated plugins and options of the configuration(s)",
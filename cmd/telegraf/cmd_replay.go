@@ -0,0 +1,102 @@
+// Command handling for the "replay" command
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/urfave/cli/v2"
+)
+
+func getReplayCommands(m App, outputBuffer io.Writer) []*cli.Command {
+	return []*cli.Command{
+		{
+			Name:  "replay",
+			Usage: "run recorded metrics through the configured processors and aggregators",
+			Description: `
+The 'replay' command feeds metrics from a recording through the processors
+and aggregators of the given configuration and prints the resulting metrics
+in InfluxDB line protocol. Inputs and outputs of the configuration are not
+used.
+
+Instead of the wall-clock, a simulated clock following the metric
+timestamps is used, so aggregation windows only depend on the recorded
+data. This allows to test processor and aggregator settings offline and
+reproducibly.
+
+Recordings can either be files in InfluxDB line protocol or the disk-buffer
+of an output, i.e. the directory of a single output in the configured
+'buffer_directory'. By default, the format is determined by the source
+being a file or directory.
+
+To replay a line protocol file and write the result to a file run
+
+> telegraf --config telegraf.conf replay --output result.influx metrics.influx
+`,
+			ArgsUsage: "<recording>",
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:  "format",
+					Usage: "format of the recording, either 'influx' or 'wal' (default: auto-detect)",
+				},
+				&cli.StringFlag{
+					Name:  "output",
+					Usage: "file to write the resulting metrics to instead of stdout",
+				},
+			},
+			Action: func(cCtx *cli.Context) error {
+				if cCtx.NArg() != 1 {
+					return errors.New("exactly one recording must be specified")
+				}
+				source := cCtx.Args().First()
+
+				format := cCtx.String("format")
+				switch format {
+				case "":
+					info, err := os.Stat(source)
+					if err != nil {
+						return fmt.Errorf("accessing recording failed: %w", err)
+					}
+					format = "influx"
+					if info.IsDir() {
+						format = "wal"
+					}
+				case "influx", "wal":
+				default:
+					return fmt.Errorf("invalid recording format %q", format)
+				}
+
+				// Do not load any inputs or outputs
+				filters := processFilterFlags(cCtx)
+				filters.input = []string{"-"}
+				filters.output = []string{"-"}
+				g := GlobalFlags{
+					config:           cCtx.StringSlice("config"),
+					configDir:        cCtx.StringSlice("config-directory"),
+					plugindDir:       cCtx.String("plugin-directory"),
+					password:         cCtx.String("password"),
+					oldEnvBehavior:   cCtx.Bool("old-env-behavior"),
+					nonStrictEnvVars: cCtx.Bool("non-strict-env-handling"),
+					debug:            cCtx.Bool("debug"),
+					quiet:            cCtx.Bool("quiet"),
+					unprotected:      cCtx.Bool("unprotected"),
+				}
+				m.Init(nil, filters, g, WindowFlags{})
+
+				w := outputBuffer
+				if fn := cCtx.String("output"); fn != "" {
+					f, err := os.Create(fn)
+					if err != nil {
+						return fmt.Errorf("creating output file failed: %w", err)
+					}
+					defer f.Close()
+					w = f
+				}
+
+				return m.Replay(source, format, w)
+			},
+		},
+	}
+}
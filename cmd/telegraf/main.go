@@ -289,6 +289,7 @@ func runApp(args []string, outputBuffer io.Writer, pprof Server, c TelegrafConfi
 	)
 	commands = append(commands, getPluginCommands(outputBuffer)...)
 	commands = append(commands, getServiceCommands(outputBuffer)...)
+	commands = append(commands, getReplayCommands(m, outputBuffer)...)
 
 	app := &cli.App{
 		Name:   "Telegraf",
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"testing"
@@ -42,6 +43,9 @@ var secrets = map[string]map[string][]byte{
 type MockTelegraf struct {
 	GlobalFlags
 	WindowFlags
+
+	replaySource string
+	replayFormat string
 }
 
 func NewMockTelegraf() *MockTelegraf {
@@ -74,6 +78,13 @@ func (*MockTelegraf) GetSecretStore(id string) (telegraf.SecretStore, error) {
 	return s, nil
 }
 
+func (m *MockTelegraf) Replay(source, format string, w io.Writer) error {
+	m.replaySource = source
+	m.replayFormat = format
+	_, err := w.Write([]byte("replayed\n"))
+	return err
+}
+
 type MockSecretStore struct {
 	Secrets map[string][]byte
 }
@@ -575,3 +586,59 @@ func TestFlagsAreSet(t *testing.T) {
 	require.Equal(t, expectedString, m.watchConfig)
 	require.Equal(t, expectedString, m.pidFile)
 }
+
+func TestReplayCommand(t *testing.T) {
+	dir := t.TempDir()
+	recording := filepath.Join(dir, "metrics.influx")
+	require.NoError(t, os.WriteFile(recording, []byte("metric value=42\n"), 0600))
+
+	buf := new(bytes.Buffer)
+	args := append(os.Args[0:1], "--config", "telegraf.conf", "replay", recording)
+	m := NewMockTelegraf()
+	require.NoError(t, runApp(args, buf, NewMockServer(), NewMockConfig(buf), m))
+	require.Equal(t, "replayed\n", buf.String())
+	require.Equal(t, recording, m.replaySource)
+	require.Equal(t, "influx", m.replayFormat)
+	require.Equal(t, []string{"telegraf.conf"}, m.config)
+
+	// Directories are replayed as disk-buffer
+	buf.Reset()
+	output := filepath.Join(dir, "result.influx")
+	args = append(os.Args[0:1], "replay", "--output", output, dir)
+	m = NewMockTelegraf()
+	require.NoError(t, runApp(args, buf, NewMockServer(), NewMockConfig(buf), m))
+	require.Empty(t, buf.String())
+	require.Equal(t, "wal", m.replayFormat)
+	actual, err := os.ReadFile(output)
+	require.NoError(t, err)
+	require.Equal(t, "replayed\n", string(actual))
+
+	// Invalid formats are rejected
+	args = append(os.Args[0:1], "replay", "--format", "json", recording)
+	require.ErrorContains(t, runApp(args, buf, NewMockServer(), NewMockConfig(buf), NewMockTelegraf()), "invalid recording format")
+}
+
+func TestCommandConfigCSVSchema(t *testing.T) {
+	fn := filepath.Join(t.TempDir(), "data.csv")
+	data := "time;host;value\n2024-03-01T10:00:00Z;server01;1.5\n2024-03-01T10:01:00Z;server01;2\n"
+	require.NoError(t, os.WriteFile(fn, []byte(data), 0600))
+
+	buf := new(bytes.Buffer)
+	args := append(os.Args[0:1], "config", "csv-schema", "--delimiter", ";", "--infer-tags", fn)
+	require.NoError(t, runApp(args, buf, NewMockServer(), NewMockConfig(buf), NewMockTelegraf()))
+
+	expected := `## Schema inferred from 2 sampled rows
+csv_header_row_count = 1
+csv_delimiter = ";"
+csv_column_names = ["time", "host", "value"]
+csv_column_types = ["string", "string", "float"]
+csv_tag_columns = ["host"]
+csv_timestamp_column = "time"
+csv_timestamp_format = "2006-01-02T15:04:05Z07:00"
+`
+	require.Equal(t, expected, buf.String())
+
+	// A file is required
+	args = append(os.Args[0:1], "config", "csv-schema")
+	require.ErrorContains(t, runApp(args, buf, NewMockServer(), NewMockConfig(buf), NewMockTelegraf()), "exactly one CSV file")
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"log"
 	"net/http"
 	"net/url"
@@ -23,10 +24,12 @@ import (
 	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/logger"
+	"github.com/influxdata/telegraf/models"
 	"github.com/influxdata/telegraf/plugins/aggregators"
 	"github.com/influxdata/telegraf/plugins/inputs"
 	"github.com/influxdata/telegraf/plugins/outputs"
 	"github.com/influxdata/telegraf/plugins/parsers"
+	"github.com/influxdata/telegraf/plugins/parsers/influx"
 	"github.com/influxdata/telegraf/plugins/processors"
 	"github.com/influxdata/telegraf/plugins/secretstores"
 )
@@ -71,6 +74,9 @@ type App interface {
 	// Secret store commands
 	ListSecretStores() ([]string, error)
 	GetSecretStore(string) (telegraf.SecretStore, error)
+
+	// Replay command
+	Replay(source, format string, w io.Writer) error
 }
 
 type Telegraf struct {
@@ -140,6 +146,54 @@ func (t *Telegraf) GetSecretStore(id string) (telegraf.SecretStore, error) {
 	return store, nil
 }
 
+func (t *Telegraf) Replay(source, format string, w io.Writer) error {
+	c, err := t.loadConfiguration()
+	if err != nil {
+		return err
+	}
+
+	logConfig := &logger.Config{
+		Debug:                   c.Agent.Debug || t.debug,
+		Quiet:                   c.Agent.Quiet || t.quiet,
+		LogTarget:               c.Agent.LogTarget,
+		LogFormat:               c.Agent.LogFormat,
+		Logfile:                 c.Agent.Logfile,
+		StructuredLogMessageKey: c.Agent.StructuredLogMessageKey,
+		LogWithTimezone:         c.Agent.LogWithTimezone,
+	}
+	if err := logger.SetupLogging(logConfig); err != nil {
+		return fmt.Errorf("setting up logging failed: %w", err)
+	}
+
+	var metrics []telegraf.Metric
+	switch format {
+	case "influx":
+		buf, err := os.ReadFile(source)
+		if err != nil {
+			return fmt.Errorf("reading recording failed: %w", err)
+		}
+		parser := &influx.Parser{}
+		if err := parser.Init(); err != nil {
+			return err
+		}
+		if metrics, err = parser.Parse(buf); err != nil {
+			return fmt.Errorf("parsing recording failed: %w", err)
+		}
+	case "wal":
+		if metrics, err = models.ReadDiskBuffer(source); err != nil {
+			return fmt.Errorf("reading recording failed: %w", err)
+		}
+	default:
+		return fmt.Errorf("invalid recording format %q", format)
+	}
+	log.Printf("I! Replaying %d metrics from %q", len(metrics), source)
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	return agent.NewAgent(c).Replay(ctx, metrics, w)
+}
+
 func (t *Telegraf) reloadLoop() error {
 	reloadConfig := false
 	reload := make(chan bool, 1)
@@ -474,6 +528,8 @@ func (t *Telegraf) runAgent(ctx context.Context, reloadConfig bool) error {
 		RotationMaxSize:         int64(c.Agent.LogfileRotationMaxSize),
 		RotationMaxArchives:     c.Agent.LogfileRotationMaxArchives,
 		LogWithTimezone:         c.Agent.LogWithTimezone,
+		DedupInterval:           time.Duration(c.Agent.LogDedupInterval),
+		ErrorTarget:             c.Agent.LogErrorTarget,
 	}
 
 	if err := logger.SetupLogging(logConfig); err != nil {
@@ -530,6 +586,7 @@ func (t *Telegraf) runAgent(ctx context.Context, reloadConfig bool) error {
 		}
 	}
 	ag := agent.NewAgent(c)
+	defer c.StopSecretStores()
 
 	// Notify systemd that telegraf is ready
 	// SdNotify() only tries to notify if the NOTIFY_SOCKET environment is set, so it's safe to call when systemd isn't present.
@@ -114,6 +114,9 @@ type Config struct {
 
 	NumberSecrets uint64
 
+	// secretUsers keeps track of plugins to notify about secret updates
+	secretUsers []*secretUser
+
 	seenAgentTable     bool
 	seenAgentTableOnce sync.Once
 }
@@ -270,6 +273,14 @@ type AgentConfig struct {
 	// Pick a timezone to use when logging or type 'local' for local time.
 	LogWithTimezone string `toml:"log_with_timezone"`
 
+	// Interval for suppressing repeated identical log messages. The number
+	// of repetitions is logged once the interval elapsed. Disabled if zero.
+	LogDedupInterval Duration `toml:"log_dedup_interval"`
+
+	// Additional target for logging warnings and errors, can be "stderr" or
+	// a log-format such as "syslog" or, on Windows, "eventlog".
+	LogErrorTarget string `toml:"log_error_target"`
+
 	Hostname     string
 	OmitHostname bool
 
@@ -313,6 +324,10 @@ type AgentConfig struct {
 	// metrics buffered in the last `flush_interval` in the event of a power
 	// cut.
 	BufferDiskSync *bool `toml:"buffer_disk_sync"`
+
+	// Address to serve the internal statistics of the agent in OpenMetrics
+	// format on the "/metrics" path. Disabled if empty.
+	SelfstatListen string `toml:"selfstat_listen"`
 }
 
 // InputNames returns a list of strings of the configured inputs.
@@ -1005,6 +1020,7 @@ func (c *Config) addAggregator(name, source string, table *ast.Table) error {
 		return fmt.Errorf("undefined but requested aggregator: %s", name)
 	}
 	aggregator := creator()
+	secretsMark := len(unlinkedSecrets)
 
 	conf, err := c.buildAggregator(name, source, table)
 	if err != nil {
@@ -1019,7 +1035,9 @@ func (c *Config) addAggregator(name, source string, table *ast.Table) error {
 		return err
 	}
 
-	c.Aggregators = append(c.Aggregators, models.NewRunningAggregator(aggregator, conf))
+	ra := models.NewRunningAggregator(aggregator, conf)
+	c.addSecretUser(aggregator, ra.Log(), secretsMark)
+	c.Aggregators = append(c.Aggregators, ra)
 	return nil
 }
 
@@ -1108,9 +1126,67 @@ func (c *Config) LinkSecrets() error {
 			return fmt.Errorf("retrieving resolver failed: %w", err)
 		}
 	}
+
+	// Register for updates of secret stores able to detect changes
+	for storeID, store := range c.SecretStores {
+		if notifier, ok := store.(telegraf.SecretStoreNotifier); ok {
+			notifier.SetNotifier(func(key string) {
+				c.notifySecretUsers(storeID, key)
+			})
+		}
+	}
 	return nil
 }
 
+// StopSecretStores terminates background routines of the secret stores, e.g.
+// used for detecting changed secrets
+func (c *Config) StopSecretStores() {
+	for _, store := range c.SecretStores {
+		if s, ok := store.(interface{ Stop() }); ok {
+			s.Stop()
+		}
+	}
+}
+
+// secretUser is a plugin to notify if one of the given secrets changed
+type secretUser struct {
+	handler telegraf.SecretUpdateHandler
+	log     telegraf.Logger
+	secrets []*Secret
+}
+
+// addSecretUser registers the plugin for secret updates if it implements the
+// SecretUpdateHandler interface. The secrets of the plugin are all secrets
+// added to the unlinked secrets since the given mark.
+func (c *Config) addSecretUser(plugin any, log telegraf.Logger, mark int) {
+	if p, ok := plugin.(processors.HasUnwrap); ok {
+		plugin = p.Unwrap()
+	}
+	handler, ok := plugin.(telegraf.SecretUpdateHandler)
+	if !ok || mark >= len(unlinkedSecrets) {
+		return
+	}
+
+	c.secretUsers = append(c.secretUsers, &secretUser{
+		handler: handler,
+		log:     log,
+		secrets: slices.Clone(unlinkedSecrets[mark:]),
+	})
+}
+
+// notifySecretUsers informs all plugins referencing the given key of the
+// secret store with the given ID about the update of the secret.
+func (c *Config) notifySecretUsers(storeID, key string) {
+	for _, user := range c.secretUsers {
+		if !slices.ContainsFunc(user.secrets, func(s *Secret) bool { return s.references(storeID, key) }) {
+			continue
+		}
+		if err := user.handler.SecretsUpdated(); err != nil {
+			user.log.Errorf("Handling update of secrets from store %q failed: %v", storeID, err)
+		}
+	}
+}
+
 func (c *Config) probeParser(parentCategory, parentName string, table *ast.Table) bool {
 	dataFormat := c.getFieldString(table, "data_format")
 	if dataFormat == "" {
@@ -1247,11 +1323,13 @@ func (c *Config) addProcessor(name, source string, table *ast.Table) error {
 	if err != nil {
 		return err
 	}
+	secretsMark := len(unlinkedSecrets)
 	processorBefore, count, err := c.setupProcessor(processorBeforeConfig.Name, creator, table)
 	if err != nil {
 		return err
 	}
 	rf := models.NewRunningProcessor(processorBefore, processorBeforeConfig)
+	c.addSecretUser(processorBefore, rf.Log(), secretsMark)
 	c.fileProcessors = append(c.fileProcessors, &OrderedPlugin{table.Line, rf})
 
 	// Setup another (new) processor instance running after the aggregator
@@ -1259,11 +1337,13 @@ func (c *Config) addProcessor(name, source string, table *ast.Table) error {
 	if err != nil {
 		return err
 	}
+	secretsMark = len(unlinkedSecrets)
 	processorAfter, _, err := c.setupProcessor(processorAfterConfig.Name, creator, table)
 	if err != nil {
 		return err
 	}
 	rf = models.NewRunningProcessor(processorAfter, processorAfterConfig)
+	c.addSecretUser(processorAfter, rf.Log(), secretsMark)
 	c.fileAggProcessors = append(c.fileAggProcessors, &OrderedPlugin{table.Line, rf})
 
 	// Check the number of misses against the threshold. We need to double
@@ -1377,6 +1457,7 @@ func (c *Config) addOutput(name, source string, table *ast.Table) error {
 		return fmt.Errorf("undefined but requested output: %s", name)
 	}
 	output := creator()
+	secretsMark := len(unlinkedSecrets)
 
 	// If the output has a SetSerializer function, then this means it can write
 	// arbitrary types of output, so build the serializer and set it.
@@ -1432,6 +1513,7 @@ func (c *Config) addOutput(name, source string, table *ast.Table) error {
 	if err != nil {
 		return err
 	}
+	c.addSecretUser(output, ro.Log(), secretsMark)
 	c.Outputs = append(c.Outputs, ro)
 
 	return nil
@@ -1472,6 +1554,7 @@ func (c *Config) addInput(name, source string, table *ast.Table) error {
 		return fmt.Errorf("undefined but requested input: %s", name)
 	}
 	input := creator()
+	secretsMark := len(unlinkedSecrets)
 
 	// If the input has a SetParser or SetParserFunc function, it can accept
 	// arbitrary data-formats, so build the requested parser and set it.
@@ -1525,6 +1608,7 @@ func (c *Config) addInput(name, source string, table *ast.Table) error {
 
 	rp := models.NewRunningInput(input, pluginConfig)
 	rp.SetDefaultTags(c.Tags)
+	c.addSecretUser(input, rp.Log(), secretsMark)
 	c.Inputs = append(c.Inputs, rp)
 
 	return nil
@@ -1709,6 +1793,7 @@ func (c *Config) buildInput(name, source string, tbl *ast.Table) (*models.InputC
 	if cp.CollectionOffset < 0 {
 		return nil, fmt.Errorf("negative collection_offset %q is not allowed", cp.CollectionOffset)
 	}
+	cp.Schedule = c.getFieldString(tbl, "schedule")
 	cp.StartupErrorBehavior = c.getFieldString(tbl, "startup_error_behavior")
 	cp.TimeSource = c.getFieldString(tbl, "time_source")
 
@@ -1816,6 +1901,7 @@ func (c *Config) missingTomlField(_ reflect.Type, key string) error {
 		"name_override", "name_prefix", "name_suffix", "namedrop", "namedrop_separator", "namepass", "namepass_separator",
 		"order",
 		"pass", "period", "precision",
+		"schedule",
 		"tagdrop", "tagexclude", "taginclude", "tagpass", "tags", "startup_error_behavior", "labels":
 
 	// secret store options to ignore
@@ -313,6 +313,18 @@ func resolve(secret []byte, resolvers map[string]telegraf.ResolveFunc) ([]byte,
 	return newsecret, remaining, replaceErrs
 }
 
+// references checks if the secret dynamically resolves the given key of the
+// secret store with the given ID. An empty key matches all keys of the store.
+func (s *Secret) references(storeID, key string) bool {
+	for ref := range s.resolvers {
+		id, k := splitLink(ref)
+		if id == storeID && (key == "" || k == key) {
+			return true
+		}
+	}
+	return false
+}
+
 func splitLink(s string) (storeID, key string) {
 	// There should _ALWAYS_ be two parts due to the regular expression match
 	parts := strings.SplitN(s[2:len(s)-1], ":", 2)
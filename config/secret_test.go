@@ -509,6 +509,88 @@ func TestSecretStoreDeclarationValidID(t *testing.T) {
 	}
 }
 
+func TestSecretStoreNotifyUpdate(t *testing.T) {
+	defer func() { unlinkedSecrets = make([]*Secret, 0) }()
+
+	cfg := []byte(`
+[[inputs.mockup_update]]
+	secret = "@{mock:secret1}"
+[[inputs.mockup_update]]
+	secret = "user=@{mock:secret1} pass=@{mock:secret2}"
+[[inputs.mockup_update]]
+	secret = "@{mock:secret3}"
+[[inputs.mockup]]
+	secret = "@{mock:secret1}"
+`)
+	c := NewConfig()
+	require.NoError(t, c.LoadConfigData(cfg, EmptySourcePath))
+	require.Len(t, c.Inputs, 4)
+
+	// Create a mockup secretstore
+	store := &MockupSecretStore{
+		Secrets: map[string][]byte{
+			"secret1": []byte("Ood Bnar"),
+			"secret2": []byte("Thon"),
+			"secret3": []byte("Arca Jeth"),
+		},
+		Dynamic: true,
+	}
+	require.NoError(t, store.Init())
+	c.SecretStores["mock"] = store
+	require.NoError(t, c.LinkSecrets())
+	require.NotNil(t, store.notify)
+
+	// Update a secret used by the first two plugins
+	require.NoError(t, store.Set("secret1", "Obi-Wan Kenobi"))
+	store.notify("secret1")
+	require.Equal(t, 1, c.Inputs[0].Input.(*MockupSecretUpdatePlugin).updates)
+	require.Equal(t, 1, c.Inputs[1].Input.(*MockupSecretUpdatePlugin).updates)
+	require.Zero(t, c.Inputs[2].Input.(*MockupSecretUpdatePlugin).updates)
+
+	// Check that the plugin gets the new secret value
+	secret, err := c.Inputs[0].Input.(*MockupSecretUpdatePlugin).Secret.Get()
+	require.NoError(t, err)
+	require.Equal(t, "Obi-Wan Kenobi", secret.TemporaryString())
+	secret.Destroy()
+
+	// Update of all secrets in the store
+	store.notify("")
+	require.Equal(t, 2, c.Inputs[0].Input.(*MockupSecretUpdatePlugin).updates)
+	require.Equal(t, 2, c.Inputs[1].Input.(*MockupSecretUpdatePlugin).updates)
+	require.Equal(t, 1, c.Inputs[2].Input.(*MockupSecretUpdatePlugin).updates)
+
+	// Update of an unused secret
+	store.notify("unused")
+	require.Equal(t, 2, c.Inputs[0].Input.(*MockupSecretUpdatePlugin).updates)
+	require.Equal(t, 2, c.Inputs[1].Input.(*MockupSecretUpdatePlugin).updates)
+	require.Equal(t, 1, c.Inputs[2].Input.(*MockupSecretUpdatePlugin).updates)
+}
+
+func TestSecretStoreNotifyStatic(t *testing.T) {
+	defer func() { unlinkedSecrets = make([]*Secret, 0) }()
+
+	cfg := []byte(`
+[[inputs.mockup_update]]
+	secret = "@{mock:secret}"
+`)
+	c := NewConfig()
+	require.NoError(t, c.LoadConfigData(cfg, EmptySourcePath))
+	require.Len(t, c.Inputs, 1)
+
+	// Create a mockup secretstore with static secrets
+	store := &MockupSecretStore{
+		Secrets: map[string][]byte{"secret": []byte("Ood Bnar")},
+	}
+	require.NoError(t, store.Init())
+	c.SecretStores["mock"] = store
+	require.NoError(t, c.LinkSecrets())
+	require.NotNil(t, store.notify)
+
+	// Static secrets are resolved on linking so updates must not be reported
+	store.notify("secret")
+	require.Zero(t, c.Inputs[0].Input.(*MockupSecretUpdatePlugin).updates)
+}
+
 type SecretImplTestSuite struct {
 	suite.Suite
 	protected bool
@@ -809,9 +891,26 @@ type MockupSecretPlugin struct {
 func (*MockupSecretPlugin) SampleConfig() string                { return "Mockup test secret plugin" }
 func (*MockupSecretPlugin) Gather(_ telegraf.Accumulator) error { return nil }
 
+// Mockup (input) plugin for testing secret updates
+type MockupSecretUpdatePlugin struct {
+	Secret Secret `toml:"secret"`
+
+	updates int
+}
+
+func (*MockupSecretUpdatePlugin) SampleConfig() string                { return "Mockup test secret plugin" }
+func (*MockupSecretUpdatePlugin) Gather(_ telegraf.Accumulator) error { return nil }
+
+func (m *MockupSecretUpdatePlugin) SecretsUpdated() error {
+	m.updates++
+	return nil
+}
+
 type MockupSecretStore struct {
 	Secrets map[string][]byte
 	Dynamic bool
+
+	notify telegraf.SecretNotifyFunc
 }
 
 func (*MockupSecretStore) Init() error {
@@ -841,6 +940,10 @@ func (s *MockupSecretStore) List() ([]string, error) {
 	}
 	return keys, nil
 }
+func (s *MockupSecretStore) SetNotifier(notify telegraf.SecretNotifyFunc) {
+	s.notify = notify
+}
+
 func (s *MockupSecretStore) GetResolver(key string) (telegraf.ResolveFunc, error) {
 	return func() ([]byte, bool, error) {
 		v, err := s.Get(key)
@@ -852,6 +955,7 @@ func (s *MockupSecretStore) GetResolver(key string) (telegraf.ResolveFunc, error
 func init() {
 	// Register the mockup input plugin for the required names
 	inputs.Add("mockup", func() telegraf.Input { return &MockupSecretPlugin{} })
+	inputs.Add("mockup_update", func() telegraf.Input { return &MockupSecretUpdatePlugin{} })
 	secretstores.Add("mockup", func(string) telegraf.SecretStore {
 		return &MockupSecretStore{}
 	})
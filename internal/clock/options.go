@@ -4,6 +4,7 @@ import (
 	"time"
 
 	"github.com/benbjohnson/clock"
+	"github.com/robfig/cron/v3"
 )
 
 type config struct {
@@ -11,6 +12,9 @@ type config struct {
 	start time.Time
 	align bool
 
+	schedule cron.Schedule
+	last     time.Time
+
 	notifier chan bool
 }
 
@@ -34,3 +38,20 @@ func WithStartupNotification(notifier chan bool) Option {
 		c.notifier = notifier
 	}
 }
+
+// WithSchedule triggers the ticker at the times of the given schedule instead
+// of using a fixed interval. Alignment and offset are ignored in this case.
+func WithSchedule(schedule cron.Schedule) Option {
+	return func(c *config) {
+		c.schedule = schedule
+	}
+}
+
+// WithLastTick triggers the ticker immediately if the schedule contains a
+// tick between the given time and now, e.g. to catch up on a tick missed
+// during downtime. Only applies in combination with WithSchedule.
+func WithLastTick(last time.Time) Option {
+	return func(c *config) {
+		c.last = last
+	}
+}
@@ -0,0 +1,34 @@
+package clock
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+var scheduleParser = cron.NewParser(
+	cron.SecondOptional | cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor,
+)
+
+// ParseSchedule parses the given cron expression. Next to the standard five
+// fields, an optional leading seconds field, descriptors like "@daily" and a
+// "CRON_TZ=<zone>" prefix are supported. Times are interpreted in the local
+// timezone unless a zone is given.
+func ParseSchedule(spec string) (cron.Schedule, error) {
+	schedule, err := scheduleParser.Parse(spec)
+	if err != nil {
+		return nil, fmt.Errorf("parsing schedule %q failed: %w", spec, err)
+	}
+	if schedule.Next(time.Now()).IsZero() {
+		return nil, fmt.Errorf("schedule %q never triggers", spec)
+	}
+	return schedule, nil
+}
+
+// ScheduleInterval returns the time between the next two ticks of the given
+// schedule after t as an estimate of the schedule's period.
+func ScheduleInterval(schedule cron.Schedule, t time.Time) time.Duration {
+	next := schedule.Next(t)
+	return schedule.Next(next).Sub(next)
+}
@@ -34,17 +34,25 @@ func NewTicker(interval, jitter, offset time.Duration, opt ...Option) *Ticker {
 
 	schedule := cfg.clk.Now()
 
-	// Align the scheduled trigger time to interval borders
-	if cfg.align {
-		// Add minimum interval size to avoid scheduling exceptionally short
-		// intervals. This avoids an issue that can occur where the previous
-		// interval ends slightly early due to very minor clock changes.
-		schedule = internal.AlignTime(cfg.start.Add(interval/100), interval)
-	}
+	if cfg.schedule != nil {
+		// Use the next time of the schedule unless we missed a tick since
+		// the last one, in this case tick immediately.
+		if cfg.last.IsZero() || cfg.schedule.Next(cfg.last).After(schedule) {
+			schedule = cfg.schedule.Next(schedule)
+		}
+	} else {
+		// Align the scheduled trigger time to interval borders
+		if cfg.align {
+			// Add minimum interval size to avoid scheduling exceptionally short
+			// intervals. This avoids an issue that can occur where the previous
+			// interval ends slightly early due to very minor clock changes.
+			schedule = internal.AlignTime(cfg.start.Add(interval/100), interval)
+		}
 
-	// Compute the scheduled first tick by adding the offset. By doing so, we
-	// do not need to take the offset into account later.
-	schedule = schedule.Add(offset)
+		// Compute the scheduled first tick by adding the offset. By doing so, we
+		// do not need to take the offset into account later.
+		schedule = schedule.Add(offset)
+	}
 
 	// Initialize the ticker instance and start it
 	t := &Ticker{
@@ -73,6 +81,14 @@ func (t *Ticker) Stop() {
 	t.wg.Wait()
 }
 
+// next returns the scheduled time of the tick following the current one
+func (t *Ticker) next() time.Time {
+	if t.cfg.schedule != nil {
+		return t.cfg.schedule.Next(t.schedule)
+	}
+	return t.schedule.Add(t.interval)
+}
+
 func (t *Ticker) run(ctx context.Context) {
 	// Start with the first scheduled tick
 	timer := t.clk.Timer(t.clk.Until(t.schedule) + internal.RandomDuration(t.jitter))
@@ -89,7 +105,7 @@ func (t *Ticker) run(ctx context.Context) {
 			// randomizing the timing with the given jitter (if any). Note, we
 			// need to remember the next scheduling without adding the ticker
 			// to avoid drifting of the ticks by jitter/2 on average!
-			t.schedule = t.schedule.Add(t.interval)
+			t.schedule = t.next()
 			timer.Reset(t.clk.Until(t.schedule) + internal.RandomDuration(t.jitter))
 
 			// Fire our event in a non-blocking fashion to avoid blocking the
@@ -0,0 +1,97 @@
+package clock
+
+import (
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScheduledTicker(t *testing.T) {
+	schedule, err := ParseSchedule("CRON_TZ=UTC 5 * * * *")
+	require.NoError(t, err)
+
+	clk := clock.NewMock()
+	end := clk.Now().Add(3 * time.Hour)
+
+	ticker := NewTicker(time.Second, 0, 0, WithClock(clk), WithSchedule(schedule))
+	defer ticker.Stop()
+
+	expected := []time.Time{
+		time.Unix(5*60, 0).UTC(),
+		time.Unix(3600+5*60, 0).UTC(),
+		time.Unix(7200+5*60, 0).UTC(),
+	}
+
+	actual := make([]time.Time, 0)
+	for clk.Now().Before(end) {
+		select {
+		case ts := <-ticker.C:
+			actual = append(actual, ts.UTC())
+		default:
+			clk.Add(time.Minute)
+		}
+	}
+
+	require.Equal(t, expected, actual)
+}
+
+func TestScheduledTickerCatchUp(t *testing.T) {
+	schedule, err := ParseSchedule("CRON_TZ=UTC 0 2 * * *")
+	require.NoError(t, err)
+
+	clk := clock.NewMock()
+	clk.Set(time.Date(2024, 3, 2, 12, 0, 0, 0, time.UTC))
+
+	// The tick of today was missed so we need to tick immediately and
+	// continue with the schedule afterwards
+	last := time.Date(2024, 3, 1, 2, 0, 0, 0, time.UTC)
+	ticker := NewTicker(time.Second, 0, 0, WithClock(clk), WithSchedule(schedule), WithLastTick(last))
+	defer ticker.Stop()
+
+	clk.Add(time.Second)
+	require.Equal(t, time.Date(2024, 3, 2, 12, 0, 0, 0, time.UTC), (<-ticker.C).UTC())
+
+	clk.Set(time.Date(2024, 3, 3, 2, 0, 0, 0, time.UTC))
+	require.Equal(t, time.Date(2024, 3, 3, 2, 0, 0, 0, time.UTC), (<-ticker.C).UTC())
+}
+
+func TestScheduledTickerNoCatchUp(t *testing.T) {
+	schedule, err := ParseSchedule("CRON_TZ=UTC 0 2 * * *")
+	require.NoError(t, err)
+
+	clk := clock.NewMock()
+	clk.Set(time.Date(2024, 3, 2, 12, 0, 0, 0, time.UTC))
+
+	// The tick of today already happened so wait for the next one
+	last := time.Date(2024, 3, 2, 2, 0, 0, 0, time.UTC)
+	ticker := NewTicker(time.Second, 0, 0, WithClock(clk), WithSchedule(schedule), WithLastTick(last))
+	defer ticker.Stop()
+
+	clk.Add(time.Hour)
+	select {
+	case ts := <-ticker.C:
+		require.Failf(t, "unexpected tick", "tick at %v", ts)
+	default:
+	}
+
+	clk.Set(time.Date(2024, 3, 3, 2, 0, 0, 0, time.UTC))
+	require.Equal(t, time.Date(2024, 3, 3, 2, 0, 0, 0, time.UTC), (<-ticker.C).UTC())
+}
+
+func TestParseSchedule(t *testing.T) {
+	_, err := ParseSchedule("@daily")
+	require.NoError(t, err)
+	_, err = ParseSchedule("30 5 * * * *")
+	require.NoError(t, err)
+
+	_, err = ParseSchedule("61 * * * *")
+	require.ErrorContains(t, err, "parsing schedule")
+	_, err = ParseSchedule("0 0 30 2 *")
+	require.ErrorContains(t, err, "never triggers")
+
+	schedule, err := ParseSchedule("CRON_TZ=UTC 5 * * * *")
+	require.NoError(t, err)
+	require.Equal(t, time.Hour, ScheduleInterval(schedule, time.Unix(0, 0)))
+}
@@ -0,0 +1,104 @@
+package logger
+
+import (
+	"fmt"
+	"maps"
+	"sync"
+	"time"
+
+	"github.com/influxdata/telegraf"
+)
+
+// repetition keeps track of a log message repeated within the deduplication
+// interval
+type repetition struct {
+	level      telegraf.LogLevel
+	prefix     string
+	attributes map[string]interface{}
+	message    string
+	start      time.Time
+	count      int
+}
+
+// summary returns the log entry reporting the number of suppressed messages
+func (r *repetition) summary(ts time.Time) *entry {
+	attr := maps.Clone(r.attributes)
+	if attr == nil {
+		attr = make(map[string]interface{}, 1)
+	}
+	attr["repeated"] = r.count
+
+	return &entry{
+		timestamp:  ts,
+		level:      r.level,
+		prefix:     r.prefix,
+		attributes: attr,
+		args: []interface{}{
+			fmt.Sprintf("Message %q repeated %d times in last %s", r.message, r.count, ts.Sub(r.start).Round(time.Second)),
+		},
+	}
+}
+
+// deduplicator suppresses identical log messages repeated within the interval
+// and reports the number of suppressed messages once the interval elapsed.
+type deduplicator struct {
+	interval time.Duration
+	seen     map[string]*repetition
+	sync.Mutex
+}
+
+func newDeduplicator(interval time.Duration) *deduplicator {
+	return &deduplicator{
+		interval: interval,
+		seen:     make(map[string]*repetition),
+	}
+}
+
+// check returns if the given message should be logged and an optional summary
+// entry to log before if a previous interval for this message elapsed.
+func (d *deduplicator) check(level telegraf.LogLevel, ts time.Time, prefix string, attr map[string]interface{}, msg string) (bool, *entry) {
+	key := level.Indicator() + prefix + msg
+
+	d.Lock()
+	defer d.Unlock()
+
+	r, found := d.seen[key]
+	if found && ts.Sub(r.start) < d.interval {
+		r.count++
+		return false, nil
+	}
+
+	// Start a new interval and log the message
+	var summary *entry
+	if found && r.count > 0 {
+		summary = r.summary(ts)
+	}
+	d.seen[key] = &repetition{
+		level:      level,
+		prefix:     prefix,
+		attributes: attr,
+		message:    msg,
+		start:      ts,
+	}
+	return true, summary
+}
+
+// expire removes all messages with their interval elapsed at the given time
+// and returns the summary entries for messages repeated within the interval.
+// If force is set, all messages are removed regardless of their interval.
+func (d *deduplicator) expire(ts time.Time, force bool) []*entry {
+	d.Lock()
+	defer d.Unlock()
+
+	var summaries []*entry
+	for key, r := range d.seen {
+		if !force && ts.Sub(r.start) < d.interval {
+			continue
+		}
+		if r.count > 0 {
+			summaries = append(summaries, r.summary(ts))
+		}
+		delete(d.seen, key)
+	}
+	return summaries
+}
@@ -0,0 +1,152 @@
+package logger
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+)
+
+func TestDedupRepeatedMessages(t *testing.T) {
+	d := newDeduplicator(5 * time.Minute)
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	attr := map[string]interface{}{"category": "inputs", "plugin": "test"}
+
+	// The first message must be logged
+	ok, summary := d.check(telegraf.Error, start, "[inputs.test] ", attr, "failed")
+	require.True(t, ok)
+	require.Nil(t, summary)
+
+	// Repetitions within the interval are suppressed
+	for i := 1; i <= 120; i++ {
+		ok, summary := d.check(telegraf.Error, start.Add(time.Duration(i)*time.Second), "[inputs.test] ", attr, "failed")
+		require.False(t, ok)
+		require.Nil(t, summary)
+	}
+
+	// Messages differing in level, source or content are not suppressed
+	ok, _ = d.check(telegraf.Warn, start.Add(time.Minute), "[inputs.test] ", attr, "failed")
+	require.True(t, ok)
+	ok, _ = d.check(telegraf.Error, start.Add(time.Minute), "[inputs.other] ", attr, "failed")
+	require.True(t, ok)
+	ok, _ = d.check(telegraf.Error, start.Add(time.Minute), "[inputs.test] ", attr, "other failure")
+	require.True(t, ok)
+
+	// After the interval elapsed the message is logged again with a summary
+	ok, summary = d.check(telegraf.Error, start.Add(5*time.Minute), "[inputs.test] ", attr, "failed")
+	require.True(t, ok)
+	require.NotNil(t, summary)
+	require.Equal(t, telegraf.Error, summary.level)
+	require.Equal(t, "[inputs.test] ", summary.prefix)
+	require.Equal(t, []interface{}{`Message "failed" repeated 120 times in last 5m0s`}, summary.args)
+	require.Equal(t, map[string]interface{}{"category": "inputs", "plugin": "test", "repeated": 120}, summary.attributes)
+
+	// The original attributes must not be modified
+	require.Equal(t, map[string]interface{}{"category": "inputs", "plugin": "test"}, attr)
+}
+
+func TestDedupExpire(t *testing.T) {
+	d := newDeduplicator(time.Minute)
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	ok, _ := d.check(telegraf.Error, start, "", nil, "repeated")
+	require.True(t, ok)
+	ok, _ = d.check(telegraf.Error, start.Add(time.Second), "", nil, "repeated")
+	require.False(t, ok)
+	ok, _ = d.check(telegraf.Error, start.Add(30*time.Second), "", nil, "once")
+	require.True(t, ok)
+
+	// Nothing elapsed yet
+	require.Empty(t, d.expire(start.Add(30*time.Second), false))
+
+	// The first message's interval elapsed
+	summaries := d.expire(start.Add(time.Minute), false)
+	require.Len(t, summaries, 1)
+	require.Equal(t, []interface{}{`Message "repeated" repeated 1 times in last 1m0s`}, summaries[0].args)
+
+	// Forcing the expiry removes messages without repetitions silently
+	require.Empty(t, d.expire(start.Add(time.Minute), true))
+	require.Empty(t, d.seen)
+
+	// The message should be logged again after expiry
+	ok, summary := d.check(telegraf.Error, start.Add(61*time.Second), "", nil, "repeated")
+	require.True(t, ok)
+	require.Nil(t, summary)
+}
+
+func TestDedupHandler(t *testing.T) {
+	sink := &captureSink{}
+	h := defaultHandler()
+	h.switchSink(sink, telegraf.Info, time.UTC, true)
+	h.setDedup(time.Hour)
+
+	start := time.Now()
+	for i := range 10 {
+		h.print(telegraf.Error, start.Add(time.Duration(i)*time.Second), "[inputs.test] ", nil, "failed")
+	}
+	require.Equal(t, []string{"E! [inputs.test] failed"}, sink.messages())
+
+	// Remaining repetitions must be reported on close
+	require.NoError(t, h.close())
+	require.Len(t, sink.messages(), 2)
+	require.Contains(t, sink.messages()[1], `E! [inputs.test] Message "failed" repeated 9 times in last`)
+}
+
+func TestErrorSink(t *testing.T) {
+	sink := &captureSink{}
+	errsink := &captureSink{}
+	h := defaultHandler()
+	h.switchSink(sink, telegraf.Debug, time.UTC, true)
+	h.setErrorSink(errsink)
+
+	h.print(telegraf.Error, time.Now(), "", nil, "error")
+	h.print(telegraf.Warn, time.Now(), "", nil, "warning")
+	h.print(telegraf.Info, time.Now(), "", nil, "info")
+	h.print(telegraf.Debug, time.Now(), "", nil, "debug")
+
+	require.Equal(t, []string{"E! error", "W! warning", "I! info", "D! debug"}, sink.messages())
+	require.Equal(t, []string{"E! error", "W! warning"}, errsink.messages())
+}
+
+func TestErrorTargetDuplicate(t *testing.T) {
+	instance = defaultHandler()
+	cfg := &Config{
+		LogFormat:   "text",
+		ErrorTarget: "stderr",
+	}
+	require.ErrorContains(t, SetupLogging(cfg), "duplicates the main log target")
+}
+
+func TestErrorTargetUnknown(t *testing.T) {
+	for _, target := range []string{"foo", "text", "structured"} {
+		t.Run(target, func(t *testing.T) {
+			instance = defaultHandler()
+			cfg := &Config{
+				LogFormat:   "text",
+				ErrorTarget: target,
+			}
+			require.ErrorContains(t, SetupLogging(cfg), "unsupported error log target: "+target)
+		})
+	}
+}
+
+type captureSink struct {
+	lines []string
+	sync.Mutex
+}
+
+func (s *captureSink) Print(level telegraf.LogLevel, _ time.Time, prefix string, _ map[string]interface{}, args ...interface{}) {
+	s.Lock()
+	defer s.Unlock()
+	s.lines = append(s.lines, level.Indicator()+" "+prefix+fmt.Sprint(args...))
+}
+
+func (s *captureSink) messages() []string {
+	s.Lock()
+	defer s.Unlock()
+	return append([]string(nil), s.lines...)
+}
@@ -26,9 +26,15 @@ type handler struct {
 	timezone *time.Location
 
 	impl      sink
+	errorsink sink
 	earlysink *log.Logger
 	earlylogs *list.List
 	sync.Mutex
+
+	// Deduplication of repeated messages
+	dedup     *deduplicator
+	dedupDone chan struct{}
+	dedupWg   sync.WaitGroup
 }
 
 func defaultHandler() *handler {
@@ -71,6 +77,77 @@ func (h *handler) switchSink(impl sink, level telegraf.LogLevel, tz *time.Locati
 	h.Unlock()
 }
 
+// setErrorSink sets the additional sink receiving warnings and errors only
+func (h *handler) setErrorSink(impl sink) {
+	h.errorsink = impl
+}
+
+// setDedup enables suppressing repeated messages within the given interval
+// or disables deduplication for a zero interval.
+func (h *handler) setDedup(interval time.Duration) {
+	h.stopDedup()
+	if interval <= 0 {
+		return
+	}
+
+	h.dedup = newDeduplicator(interval)
+	h.dedupDone = make(chan struct{})
+	h.dedupWg.Add(1)
+	go func(d *deduplicator, done chan struct{}) {
+		defer h.dedupWg.Done()
+
+		// Check for elapsed intervals at least every second to report
+		// repetitions timely even if the message stopped occurring.
+		ticker := time.NewTicker(min(interval, time.Second))
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case ts := <-ticker.C:
+				for _, e := range d.expire(ts.In(h.timezone), false) {
+					h.write(e.level, e.timestamp, e.prefix, e.attributes, e.args...)
+				}
+			}
+		}
+	}(h.dedup, h.dedupDone)
+}
+
+func (h *handler) stopDedup() {
+	if h.dedup == nil {
+		return
+	}
+	close(h.dedupDone)
+	h.dedupWg.Wait()
+
+	// Report the remaining repetitions before stopping
+	for _, e := range h.dedup.expire(time.Now().In(h.timezone), true) {
+		h.write(e.level, e.timestamp, e.prefix, e.attributes, e.args...)
+	}
+	h.dedup = nil
+}
+
+// print outputs the message to the sinks unless it is suppressed as repetition
+func (h *handler) print(level telegraf.LogLevel, ts time.Time, prefix string, attr map[string]interface{}, args ...interface{}) {
+	if h.dedup != nil {
+		ok, summary := h.dedup.check(level, ts, prefix, attr, fmt.Sprint(args...))
+		if summary != nil {
+			h.write(summary.level, summary.timestamp, summary.prefix, summary.attributes, summary.args...)
+		}
+		if !ok {
+			return
+		}
+	}
+	h.write(level, ts, prefix, attr, args...)
+}
+
+func (h *handler) write(level telegraf.LogLevel, ts time.Time, prefix string, attr map[string]interface{}, args ...interface{}) {
+	h.impl.Print(level, ts, prefix, attr, args...)
+	if h.errorsink != nil && telegraf.Warn.Includes(level) {
+		h.errorsink.Print(level, ts, prefix, attr, args...)
+	}
+}
+
 func (h *handler) add(level telegraf.LogLevel, ts time.Time, prefix string, attr map[string]interface{}, args ...interface{}) *entry {
 	e := &entry{
 		timestamp:  ts,
@@ -91,6 +168,7 @@ func (h *handler) close() error {
 	if h.impl == nil {
 		return nil
 	}
+	h.stopDedup()
 
 	h.Lock()
 	current := h.earlylogs.Front()
@@ -100,6 +178,13 @@ func (h *handler) close() error {
 	}
 	h.Unlock()
 
+	if l, ok := h.errorsink.(io.Closer); ok {
+		if err := l.Close(); err != nil {
+			return err
+		}
+	}
+	h.errorsink = nil
+
 	if l, ok := h.impl.(io.Closer); ok {
 		return l.Close()
 	}
@@ -91,11 +91,18 @@ func (l *logger) Level() telegraf.LogLevel {
 	return instance.level
 }
 
+// SetID adds the ID of the plugin instance to the logging attributes
+func (l *logger) SetID(id string) {
+	if id != "" {
+		l.attributes["id"] = id
+	}
+}
+
 // AddAttribute allows to add a key-value attribute to the logging output
 func (l *logger) AddAttribute(key string, value interface{}) {
 	// Do not allow to overwrite general keys
 	switch key {
-	case "category", "plugin", "alias":
+	case "category", "plugin", "alias", "id":
 	default:
 		l.attributes[key] = value
 	}
@@ -168,7 +175,7 @@ func (l *logger) Print(level telegraf.LogLevel, ts time.Time, args ...interface{
 		return
 	}
 	if instance.impl != nil {
-		instance.impl.Print(level, ts.In(instance.timezone), l.prefix, l.attributes, args...)
+		instance.print(level, ts.In(instance.timezone), l.prefix, l.attributes, args...)
 	} else {
 		msg := append([]interface{}{ts.In(instance.timezone).Format(time.RFC3339), " ", level.Indicator(), " ", l.prefix}, args...)
 		instance.earlysink.Print(msg...)
@@ -219,6 +226,10 @@ type Config struct {
 	InstanceName string
 	// Structured logging message key
 	StructuredLogMessageKey string
+	// interval for suppressing repeated identical messages, disabled if zero
+	DedupInterval time.Duration
+	// additional log target for warnings and errors, e.g. "stderr" or "syslog"
+	ErrorTarget string
 
 	// internal  log-level
 	logLevel telegraf.LogLevel
@@ -294,6 +305,42 @@ func SetupLogging(cfg *Config) error {
 		return err
 	}
 
+	// Create the additional sink for warnings and errors if any
+	var errorsink sink
+	switch cfg.ErrorTarget {
+	case "":
+	case "stderr":
+		if cfg.Logfile == "" && cfg.LogFormat != "eventlog" {
+			return errors.New("error log target \"stderr\" duplicates the main log target")
+		}
+
+		// Keep the structured format if configured but always write to stderr
+		format := cfg.LogFormat
+		if format != "structured" {
+			format = "text"
+		}
+		errCfg := *cfg
+		errCfg.Logfile = ""
+		if errorsink, err = registry[format](&errCfg); err != nil {
+			return fmt.Errorf("creating error log target failed: %w", err)
+		}
+	case "syslog", "eventlog":
+		// Only the system loggers are valid targets as the creators of the
+		// other formats would write to the main log file
+		errCreator, found := registry[cfg.ErrorTarget]
+		if !found {
+			return fmt.Errorf("unsupported error log target: %s", cfg.ErrorTarget)
+		}
+		if cfg.ErrorTarget == cfg.LogFormat {
+			return fmt.Errorf("error log target %q duplicates the main log target", cfg.ErrorTarget)
+		}
+		if errorsink, err = errCreator(cfg); err != nil {
+			return fmt.Errorf("creating error log target failed: %w", err)
+		}
+	default:
+		return fmt.Errorf("unsupported error log target: %s", cfg.ErrorTarget)
+	}
+
 	// Close the previous logger if possible
 	if err := CloseLogging(); err != nil {
 		return err
@@ -302,6 +349,8 @@ func SetupLogging(cfg *Config) error {
 	// Update the logging instance
 	skipEarlyLogs := cfg.LogFormat == "text" && cfg.Logfile == ""
 	instance.switchSink(l, cfg.logLevel, tz, skipEarlyLogs)
+	instance.setErrorSink(errorsink)
+	instance.setDedup(cfg.DedupInterval)
 
 	return nil
 }
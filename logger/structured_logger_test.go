@@ -230,6 +230,45 @@ func TestStructuredDerivedLoggerWithAttributes(t *testing.T) {
 	require.Equal(t, expected, actual)
 }
 
+func TestStructuredDerivedLoggerWithID(t *testing.T) {
+	instance = defaultHandler()
+	filename := filepath.Join(t.TempDir(), "test.log")
+
+	cfg := &Config{
+		Logfile:             filename,
+		LogFormat:           "structured",
+		RotationMaxArchives: -1,
+	}
+	require.NoError(t, SetupLogging(cfg))
+	defer func() { require.NoError(t, CloseLogging()) }()
+
+	l := New("testing", "test", "myalias")
+	l.SetID("abcdef")
+	l.AddAttribute("id", "foo") // Should be ignored
+
+	l.Info("TEST")
+
+	buf, err := os.ReadFile(filename)
+	require.NoError(t, err)
+
+	expected := map[string]interface{}{
+		"level":    "INFO",
+		"msg":      "TEST",
+		"category": "testing",
+		"plugin":   "test",
+		"alias":    "myalias",
+		"id":       "abcdef",
+	}
+
+	var actual map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf, &actual))
+
+	require.Contains(t, actual, "time")
+	require.NotEmpty(t, actual["time"])
+	delete(actual, "time")
+	require.Equal(t, expected, actual)
+}
+
 func TestStructuredWriteToTruncatedFile(t *testing.T) {
 	filename := filepath.Join(t.TempDir(), "test.log")
 
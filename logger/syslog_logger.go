@@ -0,0 +1,66 @@
+//go:build !windows
+
+package logger
+
+import (
+	"fmt"
+	"log"
+	"log/syslog"
+	"os"
+	"time"
+
+	"github.com/influxdata/telegraf"
+)
+
+type syslogLogger struct {
+	writer *syslog.Writer
+	errlog *log.Logger
+}
+
+func (l *syslogLogger) Close() error {
+	if l.writer == nil {
+		return nil
+	}
+	if err := l.writer.Close(); err != nil {
+		return err
+	}
+	l.writer = nil
+	return nil
+}
+
+func (l *syslogLogger) Print(level telegraf.LogLevel, _ time.Time, prefix string, _ map[string]interface{}, args ...interface{}) {
+	msg := prefix + fmt.Sprint(args...)
+
+	var err error
+	switch level {
+	case telegraf.Error:
+		err = l.writer.Err(msg)
+	case telegraf.Warn:
+		err = l.writer.Warning(msg)
+	case telegraf.Info:
+		err = l.writer.Info(msg)
+	default:
+		err = l.writer.Debug(msg)
+	}
+	if err != nil {
+		l.errlog.Printf("E! Writing log message failed: %v", err)
+	}
+}
+
+func createSyslogLogger(cfg *Config) (sink, error) {
+	writer, err := syslog.New(syslog.LOG_DAEMON|syslog.LOG_INFO, cfg.InstanceName)
+	if err != nil {
+		return nil, fmt.Errorf("connecting to syslog failed: %w", err)
+	}
+
+	l := &syslogLogger{
+		writer: writer,
+		errlog: log.New(os.Stderr, "", 0),
+	}
+
+	return l, nil
+}
+
+func init() {
+	add("syslog", createSyslogLogger)
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"os"
 	"path/filepath"
 	"slices"
 	"sort"
@@ -272,3 +273,45 @@ func (b *DiskBuffer) resetBatch() {
 	b.batchFirst = 0
 	b.batchSize = 0
 }
+
+// ReadDiskBuffer returns all metrics currently stored in the disk-buffer WAL
+// at the given path, i.e. the buffer directory of a single output. The WAL is
+// not modified. Tracking information is not persisted so metrics are returned
+// as plain metrics.
+func ReadDiskBuffer(path string) ([]telegraf.Metric, error) {
+	if _, err := os.Stat(path); err != nil {
+		return nil, fmt.Errorf("accessing wal failed: %w", err)
+	}
+
+	walFile, err := wal.Open(path, &wal.Options{AllowEmpty: true, NoSync: true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open wal file: %w", err)
+	}
+	defer walFile.Close()
+
+	first, err := walFile.FirstIndex()
+	if err != nil {
+		return nil, fmt.Errorf("reading first index failed: %w", err)
+	}
+	last, err := walFile.LastIndex()
+	if err != nil {
+		return nil, fmt.Errorf("reading last index failed: %w", err)
+	}
+	if first == 0 {
+		return nil, nil
+	}
+
+	metrics := make([]telegraf.Metric, 0, last-first+1)
+	for idx := first; idx <= last; idx++ {
+		data, err := walFile.Read(idx)
+		if err != nil {
+			return nil, fmt.Errorf("reading entry %d failed: %w", idx, err)
+		}
+		m, err := metric.FromBytes(data)
+		if err != nil && !errors.Is(err, metric.ErrSkipTracking) {
+			return nil, fmt.Errorf("decoding entry %d failed: %w", idx, err)
+		}
+		metrics = append(metrics, m)
+	}
+	return metrics, nil
+}
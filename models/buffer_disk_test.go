@@ -359,3 +359,31 @@ func TestDiskBufferTrackingOnOutputOutage(t *testing.T) {
 	defer mu.Unlock()
 	require.ElementsMatch(t, created, delivered, "tracking information mismatch")
 }
+
+func TestReadDiskBuffer(t *testing.T) {
+	path := t.TempDir()
+
+	// Create a disk buffer and consume some of the metrics
+	buf, err := NewBuffer("test", "id123", "", 0, "disk_write_through", path, true)
+	require.NoError(t, err)
+	expected := make([]telegraf.Metric, 0, 5)
+	for i := range 5 {
+		m := metric.New("test", map[string]string{}, map[string]interface{}{"value": i}, time.Unix(int64(i), 0))
+		buf.Add(m)
+		expected = append(expected, m)
+	}
+	tx := buf.BeginTransaction(2)
+	tx.AcceptAll()
+	buf.EndTransaction(tx)
+	require.NoError(t, buf.Close())
+
+	// Only the remaining metrics must be read
+	actual, err := ReadDiskBuffer(filepath.Join(path, "id123"))
+	require.NoError(t, err)
+	testutil.RequireMetricsEqual(t, expected[2:], actual)
+
+	// Non-existing buffers must not be created
+	_, err = ReadDiskBuffer(filepath.Join(path, "unknown"))
+	require.ErrorContains(t, err, "accessing wal failed")
+	require.NoDirExists(t, filepath.Join(path, "unknown"))
+}
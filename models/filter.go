@@ -245,7 +245,19 @@ func (f *Filter) compileMetricFilter() error {
 		return nil
 	}
 
-	// Declare the computation environment for the filter including custom functions
+	prog, err := CompileMetricExpression(expression)
+	if err != nil {
+		return err
+	}
+	f.metricFilter = prog
+	return nil
+}
+
+// CompileMetricExpression compiles the given boolean CEL expression over the
+// metric variables 'name', 'tags', 'fields' and 'time'. The environment is
+// shared by all CEL based metric conditions to behave consistently.
+func CompileMetricExpression(expression string) (cel.Program, error) {
+	// Declare the computation environment including custom functions
 	env, err := cel.NewEnv(
 		cel.VariableDecls(
 			decls.NewVariable("name", types.StringType),
@@ -263,25 +275,24 @@ func (f *Filter) compileMetricFilter() error {
 		ext.Strings(),
 	)
 	if err != nil {
-		return fmt.Errorf("creating environment failed: %w", err)
+		return nil, fmt.Errorf("creating environment failed: %w", err)
 	}
 
 	// Compile the program
 	ast, issues := env.Compile(expression)
 	if issues.Err() != nil {
-		return issues.Err()
+		return nil, issues.Err()
 	}
 	// Check if we got a boolean expression needed for filtering
 	if ast.OutputType() != cel.BoolType {
-		return errors.New("expression needs to return a boolean")
+		return nil, errors.New("expression needs to return a boolean")
 	}
 
 	// Get the final program
 	options := cel.EvalOptions(
 		cel.OptOptimize,
 	)
-	f.metricFilter, err = env.Program(ast, options)
-	return err
+	return env.Program(ast, options)
 }
 
 func ShouldPassFilters(include, exclude filter.Filter, key string) bool {
@@ -4,6 +4,8 @@ import (
 	"sync"
 	"time"
 
+	"github.com/benbjohnson/clock"
+
 	"github.com/influxdata/telegraf"
 	logging "github.com/influxdata/telegraf/logger"
 	"github.com/influxdata/telegraf/metric"
@@ -16,6 +18,7 @@ type RunningAggregator struct {
 	Config      *AggregatorConfig
 	periodStart time.Time
 	periodEnd   time.Time
+	clk         clock.Clock
 	log         telegraf.Logger
 
 	MetricsPushed   selfstat.Stat
@@ -35,6 +38,7 @@ func NewRunningAggregator(aggregator telegraf.Aggregator, config *AggregatorConf
 
 	aggErrorsRegister := selfstat.Register("aggregate", "errors", tags)
 	logger := logging.New("aggregators", config.Name, config.Alias)
+	logger.SetID(config.ID)
 	logger.RegisterErrorCallback(func() {
 		aggErrorsRegister.Incr(1)
 	})
@@ -67,6 +71,7 @@ func NewRunningAggregator(aggregator telegraf.Aggregator, config *AggregatorConf
 			"push_time_ns",
 			tags,
 		),
+		clk: clock.New(),
 		log: logger,
 	}
 }
@@ -95,6 +100,9 @@ func (r *RunningAggregator) LogName() string {
 }
 
 func (r *RunningAggregator) Init() error {
+	if p, ok := r.Aggregator.(telegraf.WindowedAggregator); ok {
+		p.SetPeriod(r.Config.Period)
+	}
 	if p, ok := r.Aggregator.(telegraf.Initializer); ok {
 		err := p.Init()
 		if err != nil {
@@ -119,6 +127,14 @@ func (r *RunningAggregator) EndPeriod() time.Time {
 	return r.periodEnd
 }
 
+// SetClock replaces the clock used to determine the current aggregation
+// window, e.g. to use a simulated clock when replaying recorded data.
+func (r *RunningAggregator) SetClock(clk clock.Clock) {
+	r.Lock()
+	defer r.Unlock()
+	r.clk = clk
+}
+
 func (r *RunningAggregator) UpdateWindow(start, until time.Time) {
 	r.periodStart = start
 	r.periodEnd = until
@@ -179,6 +195,10 @@ func (r *RunningAggregator) Push(acc telegraf.Accumulator) {
 	r.Lock()
 	defer r.Unlock()
 
+	if p, ok := r.Aggregator.(telegraf.WindowedAggregator); ok {
+		p.SetWindowEnd(r.periodEnd)
+	}
+
 	since := r.periodEnd
 	until := r.periodEnd.Add(r.Config.Period)
 
@@ -186,7 +206,7 @@ func (r *RunningAggregator) Push(acc telegraf.Accumulator) {
 	// not be the case if the machine's clock was adjusted or the machine
 	// hibernated as in those cases the clock might be advanced before or
 	// after the initial aggregation window.
-	nowWall := time.Now().Truncate(-1)
+	nowWall := r.clk.Now().Truncate(-1)
 	if nowWall.Before(since.Truncate(-1)) || nowWall.After(until.Truncate(-1)) {
 		since = nowWall.Truncate(r.Config.Period)
 		until = since.Add(r.Config.Period)
@@ -3,10 +3,14 @@ package models
 import (
 	"errors"
 	"fmt"
+	"sync"
 	"time"
 
+	"github.com/robfig/cron/v3"
+
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/internal/clock"
 	logging "github.com/influxdata/telegraf/logger"
 	"github.com/influxdata/telegraf/selfstat"
 )
@@ -30,11 +34,15 @@ type RunningInput struct {
 	gatherStart time.Time
 	gatherEnd   time.Time
 
+	schedule      cron.Schedule
+	scheduleState *ScheduleState
+
 	MetricsGathered selfstat.Stat
 	GatherTime      selfstat.Stat
 	GatherTimeouts  selfstat.Stat
 	GatherErrors    selfstat.Stat
 	StartupErrors   selfstat.Stat
+	Healthy         selfstat.Stat
 }
 
 func NewRunningInput(input telegraf.Input, config *InputConfig) *RunningInput {
@@ -48,6 +56,7 @@ func NewRunningInput(input telegraf.Input, config *InputConfig) *RunningInput {
 
 	errorLogRegister := selfstat.Register("gather", "errors", tags)
 	logger := logging.New("inputs", config.Name, config.Alias)
+	logger.SetID(config.ID)
 	logger.RegisterErrorCallback(func() {
 		errorLogRegister.Incr(1)
 	})
@@ -57,6 +66,10 @@ func NewRunningInput(input telegraf.Input, config *InputConfig) *RunningInput {
 	SetLoggerOnPlugin(input, logger)
 	SetStatisticsOnPlugin(input, logger, tags)
 
+	// Consider the plugin healthy until the first error occurs
+	healthy := selfstat.Register("health", "healthy", tags)
+	healthy.Set(1)
+
 	return &RunningInput{
 		Input:  input,
 		Config: config,
@@ -85,7 +98,8 @@ func NewRunningInput(input telegraf.Input, config *InputConfig) *RunningInput {
 			"startup_errors",
 			tags,
 		),
-		log: logger,
+		Healthy: healthy,
+		log:     logger,
 	}
 }
 
@@ -100,6 +114,7 @@ type InputConfig struct {
 	CollectionJitterSet  bool
 	CollectionOffset     time.Duration
 	Precision            time.Duration
+	Schedule             string
 	TimeSource           string
 	StartupErrorBehavior string
 	LogLevel             string
@@ -136,6 +151,15 @@ func (r *RunningInput) Init() error {
 		return fmt.Errorf("invalid 'time_source' setting %q", r.Config.TimeSource)
 	}
 
+	if r.Config.Schedule != "" {
+		schedule, err := clock.ParseSchedule(r.Config.Schedule)
+		if err != nil {
+			return fmt.Errorf("invalid 'schedule' setting: %w", err)
+		}
+		r.schedule = schedule
+		r.scheduleState = &ScheduleState{}
+	}
+
 	if p, ok := r.Input.(telegraf.Initializer); ok {
 		return p.Init()
 	}
@@ -156,6 +180,7 @@ func (r *RunningInput) Start(acc telegraf.Accumulator) error {
 		return nil
 	}
 	r.StartupErrors.Incr(1)
+	r.Healthy.Set(0)
 
 	// Check if the plugin reports a retry-able error, otherwise we exit.
 	var serr *internal.StartupError
@@ -257,6 +282,7 @@ func (r *RunningInput) Gather(acc telegraf.Accumulator) error {
 			var serr *internal.StartupError
 			if !errors.As(err, &serr) || !serr.Retry || !serr.Partial {
 				r.StartupErrors.Incr(1)
+				r.Healthy.Set(0)
 				return internal.ErrNotConnected
 			}
 			r.log.Debugf("Partially connected after %d attempts", r.retries)
@@ -275,8 +301,10 @@ func (r *RunningInput) Gather(acc telegraf.Accumulator) error {
 	if err != nil {
 		r.GatherErrors.Incr(1)
 		GlobalGatherErrors.Incr(1)
+		r.Healthy.Set(0)
 		return err
 	}
+	r.Healthy.Set(1)
 	return nil
 }
 
@@ -291,4 +319,51 @@ func (r *RunningInput) Log() telegraf.Logger {
 func (r *RunningInput) IncrGatherTimeouts() {
 	GlobalGatherTimeouts.Incr(1)
 	r.GatherTimeouts.Incr(1)
+	r.Healthy.Set(0)
+}
+
+// Schedule returns the parsed cron schedule of the input or nil if the input
+// is gathered in intervals. Only valid after calling Init().
+func (r *RunningInput) Schedule() cron.Schedule {
+	return r.schedule
+}
+
+// ScheduleState returns the state tracking the scheduled gathers of the input
+// or nil if the input is gathered in intervals. Only valid after calling
+// Init().
+func (r *RunningInput) ScheduleState() *ScheduleState {
+	return r.scheduleState
+}
+
+// ScheduleState keeps the time of the last scheduled gather of an input. The
+// state can be registered with the persister to catch up on gathers missed
+// while Telegraf was not running.
+type ScheduleState struct {
+	sync.Mutex
+	last time.Time
+}
+
+func (s *ScheduleState) Last() time.Time {
+	s.Lock()
+	defer s.Unlock()
+	return s.last
+}
+
+func (s *ScheduleState) Update(t time.Time) {
+	s.Lock()
+	defer s.Unlock()
+	s.last = t
+}
+
+func (s *ScheduleState) GetState() interface{} {
+	return s.Last()
+}
+
+func (s *ScheduleState) SetState(state interface{}) error {
+	last, ok := state.(time.Time)
+	if !ok {
+		return fmt.Errorf("invalid schedule state type %T", state)
+	}
+	s.Update(last)
+	return nil
 }
@@ -2,6 +2,7 @@ package models
 
 import (
 	"errors"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -10,6 +11,7 @@ import (
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/persister"
 	"github.com/influxdata/telegraf/selfstat"
 	"github.com/influxdata/telegraf/testutil"
 )
@@ -630,3 +632,46 @@ func (m *mockInput) Probe() error {
 func (m *mockInput) Gather(telegraf.Accumulator) error {
 	return m.gatherReturn
 }
+
+func TestRunningInputSchedule(t *testing.T) {
+	ri := NewRunningInput(&mockInput{}, &InputConfig{
+		Name:     "TestRunningInput",
+		Schedule: "CRON_TZ=UTC 0 2 * * *",
+	})
+	require.NoError(t, ri.Init())
+	require.NotNil(t, ri.Schedule())
+	require.NotNil(t, ri.ScheduleState())
+
+	start := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	require.Equal(t, time.Date(2024, 3, 2, 2, 0, 0, 0, time.UTC), ri.Schedule().Next(start))
+
+	// The state must survive a round-trip through the persister
+	last := time.Date(2024, 3, 1, 2, 0, 0, 0, time.UTC)
+	ri.ScheduleState().Update(last)
+	fn := filepath.Join(t.TempDir(), "states.json")
+	p := &persister.Persister{Filename: fn}
+	require.NoError(t, p.Init())
+	require.NoError(t, p.Register("schedule", ri.ScheduleState()))
+	require.NoError(t, p.Store())
+
+	restored := &ScheduleState{}
+	p = &persister.Persister{Filename: fn}
+	require.NoError(t, p.Init())
+	require.NoError(t, p.Register("schedule", restored))
+	require.NoError(t, p.Load())
+	require.True(t, last.Equal(restored.Last()))
+}
+
+func TestRunningInputScheduleInvalid(t *testing.T) {
+	ri := NewRunningInput(&mockInput{}, &InputConfig{
+		Name:     "TestRunningInput",
+		Schedule: "every day",
+	})
+	require.ErrorContains(t, ri.Init(), "invalid 'schedule' setting")
+
+	// Inputs without schedule are gathered in intervals
+	ri = NewRunningInput(&mockInput{}, &InputConfig{Name: "TestRunningInput"})
+	require.NoError(t, ri.Init())
+	require.Nil(t, ri.Schedule())
+	require.Nil(t, ri.ScheduleState())
+}
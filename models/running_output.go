@@ -64,6 +64,7 @@ type RunningOutput struct {
 	WriteTime       selfstat.Stat
 	WriteErrors     selfstat.Stat
 	StartupErrors   selfstat.Stat
+	Healthy         selfstat.Stat
 
 	BatchReady chan time.Time
 
@@ -87,6 +88,7 @@ func NewRunningOutput(output telegraf.Output, config *OutputConfig, batchSize, b
 
 	errorLogRegister := selfstat.Register("write", "errors", tags)
 	logger := logging.New("outputs", config.Name, config.Alias)
+	logger.SetID(config.ID)
 	logger.RegisterErrorCallback(func() {
 		errorLogRegister.Incr(1)
 	})
@@ -96,6 +98,10 @@ func NewRunningOutput(output telegraf.Output, config *OutputConfig, batchSize, b
 	SetLoggerOnPlugin(output, logger)
 	SetStatisticsOnPlugin(output, logger, tags)
 
+	// Consider the plugin healthy until the first error occurs
+	healthy := selfstat.Register("health", "healthy", tags)
+	healthy.Set(1)
+
 	if config.MetricBufferLimit > 0 {
 		bufferLimit = config.MetricBufferLimit
 	}
@@ -141,7 +147,8 @@ func NewRunningOutput(output telegraf.Output, config *OutputConfig, batchSize, b
 			"startup_errors",
 			tags,
 		),
-		log: logger,
+		Healthy: healthy,
+		log:     logger,
 	}
 
 	return ro, nil
@@ -187,6 +194,7 @@ func (r *RunningOutput) Connect() error {
 		return nil
 	}
 	r.StartupErrors.Incr(1)
+	r.Healthy.Set(0)
 
 	// Check if the plugin reports a retry-able error, otherwise we exit.
 	var serr *internal.StartupError
@@ -307,6 +315,7 @@ func (r *RunningOutput) Write() error {
 			var serr *internal.StartupError
 			if !errors.As(err, &serr) || !serr.Retry || !serr.Partial {
 				r.StartupErrors.Incr(1)
+				r.Healthy.Set(0)
 				return internal.ErrNotConnected
 			}
 			r.log.Debugf("Partially connected after %d attempts", r.retries)
@@ -352,6 +361,7 @@ func (r *RunningOutput) WriteBatch() error {
 		r.retries++
 		if err := r.Output.Connect(); err != nil {
 			r.StartupErrors.Incr(1)
+			r.Healthy.Set(0)
 			return internal.ErrNotConnected
 		}
 		r.started = true
@@ -381,8 +391,10 @@ func (r *RunningOutput) doTransaction() error {
 	if err != nil {
 		r.WriteErrors.Incr(1)
 		GlobalWriteErrors.Incr(1)
+		r.Healthy.Set(0)
 		return err
 	}
+	r.Healthy.Set(1)
 
 	return nil
 }
@@ -43,6 +43,7 @@ func NewRunningProcessor(processor telegraf.StreamingProcessor, config *Processo
 
 	processErrorsRegister := selfstat.Register("process", "errors", tags)
 	logger := logging.New("processors", config.Name, config.Alias)
+	logger.SetID(config.ID)
 	logger.RegisterErrorCallback(func() {
 		processErrorsRegister.Incr(1)
 	})
@@ -52,6 +53,12 @@ func NewRunningProcessor(processor telegraf.StreamingProcessor, config *Processo
 	SetLoggerOnPlugin(processor, logger)
 	SetStatisticsOnPlugin(processor, logger, tags)
 
+	// Non-streaming processors are wrapped, so set the statistics collector
+	// on the actual plugin
+	if p, ok := processor.(interface{ Unwrap() telegraf.Processor }); ok {
+		SetStatisticsOnPlugin(p.Unwrap(), logger, tags)
+	}
+
 	return &RunningProcessor{
 		Processor: processor,
 		Config:    config,
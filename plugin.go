@@ -62,3 +62,16 @@ type StatefulPlugin interface {
 type ProbePlugin interface {
 	Probe() error
 }
+
+// SecretUpdateHandler is an optional interface for plugins that use secrets
+// only once, e.g. when connecting, and need to pick up changed secret values
+// e.g. by reconnecting. Only secrets referencing a secret store implementing
+// the SecretStoreNotifier interface and resolving dynamically will trigger
+// an update.
+type SecretUpdateHandler interface {
+	// SecretsUpdated is called whenever a secret referenced in the plugin's
+	// configuration changed in its secret store. The function is called
+	// concurrently to the other plugin functions and must not block, so
+	// plugins should defer reconnecting e.g. to the next Gather or Write.
+	SecretsUpdated() error
+}
@@ -0,0 +1,5 @@
+//go:build !custom || aggregators || aggregators.downsample
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/aggregators/downsample" // register plugin
@@ -0,0 +1,5 @@
+//go:build !custom || aggregators || aggregators.native_histogram
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/aggregators/native_histogram" // register plugin
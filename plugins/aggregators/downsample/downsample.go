@@ -0,0 +1,290 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package downsample
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/filter"
+	"github.com/influxdata/telegraf/internal/choice"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/plugins/aggregators"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+var functions = []string{"avg", "max", "min", "sum", "count", "first", "last"}
+
+type Downsample struct {
+	Resolutions []*resolution   `toml:"resolution"`
+	Log         telegraf.Logger `toml:"-"`
+
+	period    time.Duration
+	windowEnd time.Time
+}
+
+type resolution struct {
+	Interval   config.Duration   `toml:"interval"`
+	NameSuffix string            `toml:"name_suffix"`
+	Tags       map[string]string `toml:"tags"`
+	Function   string            `toml:"function"`
+	Fields     map[string]string `toml:"fields"`
+
+	overrides []override
+	windows   map[time.Time]map[uint64]*aggregate
+}
+
+type override struct {
+	filter   filter.Filter
+	function string
+}
+
+type aggregate struct {
+	name   string
+	tags   map[string]string
+	fields map[string]*consolidation
+}
+
+type consolidation struct {
+	function  string
+	value     interface{}
+	timestamp time.Time
+	sum       float64
+	count     int64
+}
+
+func (*Downsample) SampleConfig() string {
+	return sampleConfig
+}
+
+func (d *Downsample) SetPeriod(period time.Duration) {
+	d.period = period
+}
+
+func (d *Downsample) SetWindowEnd(end time.Time) {
+	d.windowEnd = end
+}
+
+func (d *Downsample) Init() error {
+	if len(d.Resolutions) == 0 {
+		return errors.New("no resolution configured")
+	}
+
+	for i, r := range d.Resolutions {
+		interval := time.Duration(r.Interval)
+		if interval <= 0 {
+			return fmt.Errorf("invalid interval for resolution %d", i+1)
+		}
+		if d.period > 0 && interval%d.period != 0 {
+			return fmt.Errorf("interval %s of resolution %d is not a multiple of the period %s", interval, i+1, d.period)
+		}
+
+		if r.Function == "" {
+			r.Function = "avg"
+		}
+		if !choice.Contains(r.Function, functions) {
+			return fmt.Errorf("invalid function %q for resolution %d", r.Function, i+1)
+		}
+
+		// Sort the field patterns by specificity to let the most specific
+		// matching pattern win, using the name for a deterministic order
+		patterns := make([]string, 0, len(r.Fields))
+		for pattern := range r.Fields {
+			patterns = append(patterns, pattern)
+		}
+		sort.Slice(patterns, func(i, j int) bool {
+			li, lj := literalLength(patterns[i]), literalLength(patterns[j])
+			if li != lj {
+				return li > lj
+			}
+			return patterns[i] < patterns[j]
+		})
+		r.overrides = make([]override, 0, len(patterns))
+		for _, pattern := range patterns {
+			function := r.Fields[pattern]
+			if !choice.Contains(function, functions) {
+				return fmt.Errorf("invalid function %q for field %q of resolution %d", function, pattern, i+1)
+			}
+			f, err := filter.Compile([]string{pattern})
+			if err != nil {
+				return fmt.Errorf("creating filter for field %q of resolution %d failed: %w", pattern, i+1, err)
+			}
+			r.overrides = append(r.overrides, override{filter: f, function: function})
+		}
+
+		r.windows = make(map[time.Time]map[uint64]*aggregate)
+	}
+
+	return nil
+}
+
+func (d *Downsample) Add(in telegraf.Metric) {
+	id := in.HashID()
+	for _, r := range d.Resolutions {
+		start := in.Time().Truncate(time.Duration(r.Interval))
+		window, found := r.windows[start]
+		if !found {
+			window = make(map[uint64]*aggregate)
+			r.windows[start] = window
+		}
+
+		a, found := window[id]
+		if !found {
+			a = &aggregate{
+				name:   in.Name(),
+				tags:   in.Tags(),
+				fields: make(map[string]*consolidation),
+			}
+			window[id] = a
+		}
+
+		for _, field := range in.FieldList() {
+			c, found := a.fields[field.Key]
+			if !found {
+				c = &consolidation{function: r.function(field.Key)}
+				a.fields[field.Key] = c
+			}
+			c.add(field.Value, in.Time())
+		}
+	}
+}
+
+// Push outputs all windows ending within the current aggregation window.
+// Windows of longer resolutions are kept until they are complete.
+func (d *Downsample) Push(acc telegraf.Accumulator) {
+	// Always use nanosecond precision to keep the window start timestamps
+	acc.SetPrecision(time.Nanosecond)
+
+	for _, r := range d.Resolutions {
+		interval := time.Duration(r.Interval)
+
+		starts := make([]time.Time, 0, len(r.windows))
+		for start := range r.windows {
+			if d.windowEnd.IsZero() || !start.Add(interval).After(d.windowEnd) {
+				starts = append(starts, start)
+			}
+		}
+		slices.SortFunc(starts, func(a, b time.Time) int { return a.Compare(b) })
+
+		for _, start := range starts {
+			for _, a := range r.windows[start] {
+				fields := make(map[string]interface{}, len(a.fields))
+				for key, c := range a.fields {
+					if v := c.result(); v != nil {
+						fields[key] = v
+					}
+				}
+				if len(fields) == 0 {
+					continue
+				}
+
+				m := metric.New(a.name+r.NameSuffix, a.tags, fields, start)
+				for k, v := range r.Tags {
+					m.AddTag(k, v)
+				}
+				acc.AddMetric(m)
+			}
+			delete(r.windows, start)
+		}
+	}
+}
+
+// Reset keeps the pending windows as those might span multiple periods
+func (*Downsample) Reset() {}
+
+// literalLength returns the number of non-wildcard characters of the pattern
+func literalLength(pattern string) int {
+	return len(pattern) - strings.Count(pattern, "*") - strings.Count(pattern, "?")
+}
+
+// function returns the consolidation function for the given field
+func (r *resolution) function(field string) string {
+	for _, o := range r.overrides {
+		if o.filter.Match(field) {
+			return o.function
+		}
+	}
+	return r.Function
+}
+
+func (c *consolidation) add(value interface{}, ts time.Time) {
+	switch c.function {
+	case "first":
+		if c.value == nil || ts.Before(c.timestamp) {
+			c.value, c.timestamp = value, ts
+		}
+		return
+	case "last":
+		if c.value == nil || !ts.Before(c.timestamp) {
+			c.value, c.timestamp = value, ts
+		}
+		return
+	case "count":
+		c.count++
+		return
+	}
+
+	var v float64
+	switch x := value.(type) {
+	case float64:
+		v = x
+	case int64:
+		v = float64(x)
+	case uint64:
+		v = float64(x)
+	case bool:
+		if x {
+			v = 1
+		}
+	default:
+		return
+	}
+
+	c.count++
+	c.sum += v
+	switch c.function {
+	case "max":
+		if c.count == 1 || v > c.value.(float64) {
+			c.value = v
+		}
+	case "min":
+		if c.count == 1 || v < c.value.(float64) {
+			c.value = v
+		}
+	}
+}
+
+func (c *consolidation) result() interface{} {
+	switch c.function {
+	case "first", "last", "max", "min":
+		return c.value
+	case "count":
+		return c.count
+	}
+
+	// Fields without any numerical value cannot be consolidated
+	if c.count == 0 {
+		return nil
+	}
+	switch c.function {
+	case "avg":
+		return c.sum / float64(c.count)
+	case "sum":
+		return c.sum
+	}
+	return nil
+}
+
+func init() {
+	aggregators.Add("downsample", func() telegraf.Aggregator {
+		return &Downsample{}
+	})
+}
@@ -0,0 +1,251 @@
+package downsample
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/models"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInitInvalid(t *testing.T) {
+	tests := []struct {
+		name        string
+		resolutions []*resolution
+		expected    string
+	}{
+		{
+			name:     "no resolution",
+			expected: "no resolution configured",
+		},
+		{
+			name:        "no interval",
+			resolutions: []*resolution{{}},
+			expected:    "invalid interval for resolution 1",
+		},
+		{
+			name:        "not a multiple of period",
+			resolutions: []*resolution{{Interval: config.Duration(90 * time.Second)}},
+			expected:    "interval 1m30s of resolution 1 is not a multiple of the period 1m0s",
+		},
+		{
+			name:        "invalid function",
+			resolutions: []*resolution{{Interval: config.Duration(time.Minute), Function: "median"}},
+			expected:    `invalid function "median" for resolution 1`,
+		},
+		{
+			name: "invalid field function",
+			resolutions: []*resolution{
+				{Interval: config.Duration(time.Minute), Fields: map[string]string{"a": "p99"}},
+			},
+			expected: `invalid function "p99" for field "a" of resolution 1`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := &Downsample{Resolutions: tt.resolutions, Log: testutil.Logger{}}
+			plugin.SetPeriod(time.Minute)
+			require.ErrorContains(t, plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestFunctions(t *testing.T) {
+	start := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	plugin := &Downsample{
+		Resolutions: []*resolution{
+			{
+				Interval: config.Duration(time.Minute),
+				Function: "avg",
+				Fields: map[string]string{
+					"max":   "max",
+					"min":   "min",
+					"sum":   "sum",
+					"count": "count",
+					"f*":    "first",
+					"l*":    "last",
+				},
+			},
+		},
+		Log: testutil.Logger{},
+	}
+	plugin.SetPeriod(time.Minute)
+	require.NoError(t, plugin.Init())
+
+	for i, v := range []int64{3, 1, 2} {
+		plugin.Add(metric.New(
+			"test",
+			map[string]string{"host": "a"},
+			map[string]interface{}{
+				"avg":   v,
+				"max":   v,
+				"min":   v,
+				"sum":   v,
+				"count": v,
+				"first": v,
+				"last":  v,
+				"lstr":  "value" + string(rune('0'+i)),
+				"str":   "ignored",
+			},
+			start.Add(time.Duration(i)*10*time.Second),
+		))
+	}
+
+	var acc testutil.Accumulator
+	plugin.SetWindowEnd(start.Add(time.Minute))
+	plugin.Push(&acc)
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"test",
+			map[string]string{"host": "a"},
+			map[string]interface{}{
+				"avg":   2.0,
+				"max":   3.0,
+				"min":   1.0,
+				"sum":   6.0,
+				"count": int64(3),
+				"first": int64(3),
+				"last":  int64(2),
+				"lstr":  "value2",
+			},
+			start,
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics())
+}
+
+func TestFieldOverrideSpecificity(t *testing.T) {
+	start := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	plugin := &Downsample{
+		Resolutions: []*resolution{
+			{
+				Interval: config.Duration(time.Minute),
+				Function: "avg",
+				Fields: map[string]string{
+					"*":         "sum",
+					"cpu_*":     "max",
+					"cpu_?dle":  "min",
+					"cpu_total": "last",
+				},
+			},
+		},
+		Log: testutil.Logger{},
+	}
+	plugin.SetPeriod(time.Minute)
+	require.NoError(t, plugin.Init())
+
+	for i, v := range []int64{3, 1, 2} {
+		plugin.Add(metric.New(
+			"test",
+			map[string]string{},
+			map[string]interface{}{
+				"mem_used":  v,
+				"cpu_user":  v,
+				"cpu_idle":  v,
+				"cpu_total": v,
+			},
+			start.Add(time.Duration(i)*10*time.Second),
+		))
+	}
+
+	var acc testutil.Accumulator
+	plugin.SetWindowEnd(start.Add(time.Minute))
+	plugin.Push(&acc)
+
+	// The catch-all pattern must not hide the more specific patterns
+	expected := []telegraf.Metric{
+		metric.New(
+			"test",
+			map[string]string{},
+			map[string]interface{}{
+				"mem_used":  6.0,
+				"cpu_user":  3.0,
+				"cpu_idle":  1.0,
+				"cpu_total": int64(2),
+			},
+			start,
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics())
+}
+
+func TestMultipleResolutions(t *testing.T) {
+	start := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	plugin := &Downsample{
+		Resolutions: []*resolution{
+			{
+				Interval: config.Duration(time.Minute),
+				Tags:     map[string]string{"resolution": "1m"},
+			},
+			{
+				Interval:   config.Duration(5 * time.Minute),
+				NameSuffix: "_5m",
+				Function:   "max",
+			},
+		},
+		Log: testutil.Logger{},
+	}
+	plugin.SetPeriod(time.Minute)
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	for i := range 10 {
+		ts := start.Add(time.Duration(i) * time.Minute)
+		plugin.Add(metric.New("test", nil, map[string]interface{}{"value": float64(i)}, ts))
+		plugin.Add(metric.New("test", nil, map[string]interface{}{"value": float64(i + 1)}, ts.Add(30*time.Second)))
+
+		plugin.SetWindowEnd(ts.Add(time.Minute))
+		plugin.Push(&acc)
+		plugin.Reset()
+	}
+
+	var expected []telegraf.Metric
+	for i := range 10 {
+		ts := start.Add(time.Duration(i) * time.Minute)
+		expected = append(expected, metric.New(
+			"test",
+			map[string]string{"resolution": "1m"},
+			map[string]interface{}{"value": float64(i) + 0.5},
+			ts,
+		))
+		if i%5 == 4 {
+			expected = append(expected, metric.New(
+				"test_5m",
+				map[string]string{},
+				map[string]interface{}{"value": float64(i + 1)},
+				ts.Add(-4*time.Minute),
+			))
+		}
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics())
+}
+
+func TestRunningAggregator(t *testing.T) {
+	plugin := &Downsample{
+		Resolutions: []*resolution{{Interval: config.Duration(2 * time.Minute)}},
+		Log:         testutil.Logger{},
+	}
+	ra := models.NewRunningAggregator(plugin, &models.AggregatorConfig{Name: "downsample", Period: time.Minute})
+	require.NoError(t, ra.Init())
+	require.Equal(t, time.Minute, plugin.period)
+
+	start := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	ra.UpdateWindow(start, start.Add(time.Minute))
+	var acc testutil.Accumulator
+	ra.Push(&acc)
+	require.Equal(t, start.Add(time.Minute), plugin.windowEnd)
+
+	// An invalid period must be rejected
+	plugin = &Downsample{
+		Resolutions: []*resolution{{Interval: config.Duration(2 * time.Minute)}},
+		Log:         testutil.Logger{},
+	}
+	ra = models.NewRunningAggregator(plugin, &models.AggregatorConfig{Name: "downsample", Period: 45 * time.Second})
+	require.ErrorContains(t, ra.Init(), "not a multiple of the period")
+}
@@ -0,0 +1,165 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package join
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/plugins/aggregators"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type Join struct {
+	Left        string          `toml:"left"`
+	Right       string          `toml:"right"`
+	On          []string        `toml:"on"`
+	RightOn     []string        `toml:"right_on"`
+	Mode        string          `toml:"mode"`
+	Tolerance   config.Duration `toml:"tolerance"`
+	Measurement string          `toml:"measurement"`
+	LeftPrefix  string          `toml:"left_prefix"`
+	RightPrefix string          `toml:"right_prefix"`
+
+	left  []telegraf.Metric
+	right map[string][]telegraf.Metric
+}
+
+func (*Join) SampleConfig() string {
+	return sampleConfig
+}
+
+func (j *Join) Init() error {
+	if j.Left == "" || j.Right == "" {
+		return errors.New("both 'left' and 'right' measurements must be set")
+	}
+	if len(j.On) == 0 {
+		return errors.New("no tags to join on")
+	}
+	if len(j.RightOn) == 0 {
+		j.RightOn = j.On
+	}
+	if len(j.RightOn) != len(j.On) {
+		return errors.New("number of 'on' and 'right_on' tags differ")
+	}
+
+	switch j.Mode {
+	case "":
+		j.Mode = "inner"
+	case "inner", "left":
+	default:
+		return fmt.Errorf("invalid mode %q", j.Mode)
+	}
+
+	if j.Tolerance < 0 {
+		return errors.New("tolerance must not be negative")
+	}
+
+	if j.Measurement == "" {
+		j.Measurement = j.Left
+	}
+
+	j.Reset()
+
+	return nil
+}
+
+func (j *Join) Add(m telegraf.Metric) {
+	// Self-joins use the metric on both sides
+	name := m.Name()
+	if name == j.Left {
+		j.left = append(j.left, m)
+	}
+	if name == j.Right {
+		if key, ok := joinKey(m, j.RightOn); ok {
+			j.right[key] = append(j.right[key], m)
+		}
+	}
+}
+
+func (j *Join) Push(acc telegraf.Accumulator) {
+	// Always use nanosecond precision to avoid rounding metrics that were
+	// produced at a precision higher than the agent default.
+	acc.SetPrecision(time.Nanosecond)
+
+	for _, l := range j.left {
+		var match telegraf.Metric
+		if key, ok := joinKey(l, j.On); ok {
+			match = j.closest(l.Time(), j.right[key])
+		}
+		if match == nil && j.Mode == "inner" {
+			continue
+		}
+		acc.AddMetric(j.combine(l, match))
+	}
+}
+
+func (j *Join) Reset() {
+	j.left = nil
+	j.right = make(map[string][]telegraf.Metric)
+}
+
+// closest returns the candidate closest to the given time within the
+// tolerance or nil if there is no such candidate
+func (j *Join) closest(t time.Time, candidates []telegraf.Metric) telegraf.Metric {
+	var match telegraf.Metric
+	best := time.Duration(j.Tolerance)
+	for _, c := range candidates {
+		diff := c.Time().Sub(t)
+		if diff < 0 {
+			diff = -diff
+		}
+		if diff <= best {
+			match, best = c, diff
+		}
+	}
+	return match
+}
+
+// combine creates the joined metric using the tags and timestamp of the left
+// metric. The right metric may be nil for left joins.
+func (j *Join) combine(l, r telegraf.Metric) telegraf.Metric {
+	m := metric.New(j.Measurement, nil, nil, l.Time(), l.Type())
+	if r != nil {
+		for _, tag := range r.TagList() {
+			m.AddTag(tag.Key, tag.Value)
+		}
+		for _, field := range r.FieldList() {
+			m.AddField(j.RightPrefix+field.Key, field.Value)
+		}
+	}
+	for _, tag := range l.TagList() {
+		m.AddTag(tag.Key, tag.Value)
+	}
+	for _, field := range l.FieldList() {
+		m.AddField(j.LeftPrefix+field.Key, field.Value)
+	}
+	return m
+}
+
+// joinKey returns the values of the given tags as key or false if one of the
+// tags is missing
+func joinKey(m telegraf.Metric, tags []string) (string, bool) {
+	values := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		v, found := m.GetTag(tag)
+		if !found {
+			return "", false
+		}
+		values = append(values, v)
+	}
+	return strings.Join(values, "\x00"), true
+}
+
+func init() {
+	aggregators.Add("join", func() telegraf.Aggregator {
+		return &Join{}
+	})
+}
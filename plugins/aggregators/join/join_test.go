@@ -0,0 +1,177 @@
+package join
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInitInvalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *Join
+		expected string
+	}{
+		{
+			name:     "missing right",
+			plugin:   &Join{Left: "disk", On: []string{"device"}},
+			expected: "both 'left' and 'right' measurements must be set",
+		},
+		{
+			name:     "missing keys",
+			plugin:   &Join{Left: "disk", Right: "diskio"},
+			expected: "no tags to join on",
+		},
+		{
+			name:     "key mismatch",
+			plugin:   &Join{Left: "disk", Right: "diskio", On: []string{"device"}, RightOn: []string{"a", "b"}},
+			expected: "number of 'on' and 'right_on' tags differ",
+		},
+		{
+			name:     "invalid mode",
+			plugin:   &Join{Left: "disk", Right: "diskio", On: []string{"device"}, Mode: "outer"},
+			expected: `invalid mode "outer"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.ErrorContains(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestJoin(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	input := []telegraf.Metric{
+		metric.New("disk", map[string]string{"device": "sda1", "path": "/"}, map[string]interface{}{"used": 42}, now),
+		metric.New("disk", map[string]string{"device": "sdb1", "path": "/data"}, map[string]interface{}{"used": 23}, now),
+		metric.New("disk", map[string]string{"path": "/tmp"}, map[string]interface{}{"used": 1}, now),
+		metric.New("diskio", map[string]string{"device": "sda1", "name": "sda"}, map[string]interface{}{"reads": 100}, now.Add(time.Second)),
+		metric.New("diskio", map[string]string{"device": "sda1", "name": "sda"}, map[string]interface{}{"reads": 110}, now.Add(-3*time.Second)),
+		metric.New("diskio", map[string]string{"device": "sdc1", "name": "sdc"}, map[string]interface{}{"reads": 5}, now),
+		metric.New("cpu", map[string]string{"device": "sda1"}, map[string]interface{}{"usage": 5}, now),
+	}
+
+	tests := []struct {
+		name     string
+		plugin   *Join
+		expected []telegraf.Metric
+	}{
+		{
+			name: "inner join",
+			plugin: &Join{
+				Left:      "disk",
+				Right:     "diskio",
+				On:        []string{"device"},
+				Tolerance: config.Duration(5 * time.Second),
+			},
+			expected: []telegraf.Metric{
+				metric.New(
+					"disk",
+					map[string]string{"device": "sda1", "path": "/", "name": "sda"},
+					map[string]interface{}{"used": 42, "reads": 100},
+					now,
+				),
+			},
+		},
+		{
+			name: "left join",
+			plugin: &Join{
+				Left:        "disk",
+				Right:       "diskio",
+				On:          []string{"device"},
+				Mode:        "left",
+				Tolerance:   config.Duration(5 * time.Second),
+				Measurement: "storage",
+				RightPrefix: "io_",
+			},
+			expected: []telegraf.Metric{
+				metric.New(
+					"storage",
+					map[string]string{"device": "sda1", "path": "/", "name": "sda"},
+					map[string]interface{}{"used": 42, "io_reads": 100},
+					now,
+				),
+				metric.New("storage", map[string]string{"device": "sdb1", "path": "/data"}, map[string]interface{}{"used": 23}, now),
+				metric.New("storage", map[string]string{"path": "/tmp"}, map[string]interface{}{"used": 1}, now),
+			},
+		},
+		{
+			name: "outside tolerance",
+			plugin: &Join{
+				Left:  "disk",
+				Right: "diskio",
+				On:    []string{"device"},
+			},
+		},
+		{
+			name: "no matching tag values",
+			plugin: &Join{
+				Left:    "cpu",
+				Right:   "diskio",
+				On:      []string{"device"},
+				RightOn: []string{"name"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.NoError(t, tt.plugin.Init())
+			for _, m := range input {
+				tt.plugin.Add(m)
+			}
+
+			var acc testutil.Accumulator
+			tt.plugin.Push(&acc)
+			testutil.RequireMetricsEqual(t, tt.expected, acc.GetTelegrafMetrics(), testutil.SortMetrics())
+		})
+	}
+}
+
+func TestJoinDifferentTagNames(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	plugin := &Join{
+		Left:        "procstat",
+		Right:       "systemd_units",
+		On:          []string{"systemd_unit"},
+		RightOn:     []string{"name"},
+		RightPrefix: "unit_",
+	}
+	require.NoError(t, plugin.Init())
+
+	plugin.Add(metric.New("procstat", map[string]string{"systemd_unit": "sshd.service"}, map[string]interface{}{"cpu_usage": 1.5}, now))
+	plugin.Add(metric.New("systemd_units", map[string]string{"name": "sshd.service"}, map[string]interface{}{"active_code": 0}, now))
+
+	var acc testutil.Accumulator
+	plugin.Push(&acc)
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"procstat",
+			map[string]string{"systemd_unit": "sshd.service", "name": "sshd.service"},
+			map[string]interface{}{"cpu_usage": 1.5, "unit_active_code": 0},
+			now,
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics())
+}
+
+func TestReset(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	plugin := &Join{Left: "a", Right: "b", On: []string{"id"}}
+	require.NoError(t, plugin.Init())
+
+	plugin.Add(metric.New("b", map[string]string{"id": "1"}, map[string]interface{}{"y": 2}, now))
+	plugin.Reset()
+	plugin.Add(metric.New("a", map[string]string{"id": "1"}, map[string]interface{}{"x": 1}, now))
+
+	var acc testutil.Accumulator
+	plugin.Push(&acc)
+	require.Empty(t, acc.GetTelegrafMetrics())
+}
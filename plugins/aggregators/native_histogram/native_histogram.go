@@ -0,0 +1,266 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package native_histogram
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+
+	"github.com/prometheus/prometheus/model/histogram"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/filter"
+	"github.com/influxdata/telegraf/plugins/aggregators"
+	"github.com/influxdata/telegraf/plugins/common/nativehistogram"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+// Limits of the schema for native histograms defined by Prometheus
+const (
+	minSchema = -4
+	maxSchema = 8
+)
+
+type NativeHistogram struct {
+	Fields        []string        `toml:"fields"`
+	Schema        int32           `toml:"schema"`
+	MaxBuckets    int             `toml:"max_buckets"`
+	ZeroThreshold float64         `toml:"zero_threshold"`
+	Cumulative    bool            `toml:"cumulative"`
+	Log           telegraf.Logger `toml:"-"`
+
+	accept filter.Filter
+	bounds map[int32][]float64
+	cache  map[uint64]*aggregate
+}
+
+type aggregate struct {
+	name   string
+	tags   map[string]string
+	fields map[string]*sketch
+}
+
+// sketch is a sparse exponential histogram
+type sketch struct {
+	schema    int32
+	count     float64
+	sum       float64
+	zeroCount float64
+	positive  map[int32]float64
+	negative  map[int32]float64
+}
+
+func (*NativeHistogram) SampleConfig() string {
+	return sampleConfig
+}
+
+func (n *NativeHistogram) Init() error {
+	if n.Schema < minSchema || n.Schema > maxSchema {
+		return fmt.Errorf("schema %d out of range [%d, %d]", n.Schema, minSchema, maxSchema)
+	}
+	if n.MaxBuckets < 1 {
+		return errors.New("max_buckets must be positive")
+	}
+	if n.ZeroThreshold < 0 {
+		return errors.New("zero_threshold must not be negative")
+	}
+
+	if len(n.Fields) == 0 {
+		n.Fields = []string{"*"}
+	}
+	f, err := filter.Compile(n.Fields)
+	if err != nil {
+		return fmt.Errorf("failed to create new field filter: %w", err)
+	}
+	n.accept = f
+
+	// Precompute the bucket boundaries within a power of two for all
+	// positive schemas, i.e. the fractions 2^(j/2^schema - 1) in [0.5, 1)
+	n.bounds = make(map[int32][]float64, maxSchema)
+	for schema := int32(1); schema <= maxSchema; schema++ {
+		size := 1 << schema
+		bounds := make([]float64, 0, size)
+		for j := range size {
+			bounds = append(bounds, math.Ldexp(math.Exp2(float64(j)/float64(size)), -1))
+		}
+		n.bounds[schema] = bounds
+	}
+
+	n.cache = make(map[uint64]*aggregate)
+
+	return nil
+}
+
+func (n *NativeHistogram) Add(in telegraf.Metric) {
+	id := in.HashID()
+	a, found := n.cache[id]
+	if !found {
+		a = &aggregate{
+			name:   in.Name(),
+			tags:   in.Tags(),
+			fields: make(map[string]*sketch),
+		}
+		n.cache[id] = a
+	}
+
+	for _, field := range in.FieldList() {
+		if !n.accept.Match(field.Key) {
+			continue
+		}
+
+		var value float64
+		switch v := field.Value.(type) {
+		case float64:
+			value = v
+		case int64:
+			value = float64(v)
+		case uint64:
+			value = float64(v)
+		default:
+			continue
+		}
+		if math.IsNaN(value) || math.IsInf(value, 0) {
+			continue
+		}
+
+		s, found := a.fields[field.Key]
+		if !found {
+			s = &sketch{
+				schema:   n.Schema,
+				positive: make(map[int32]float64),
+				negative: make(map[int32]float64),
+			}
+			a.fields[field.Key] = s
+		}
+		n.observe(s, value)
+	}
+}
+
+func (n *NativeHistogram) Push(acc telegraf.Accumulator) {
+	hint := histogram.GaugeType
+	if n.Cumulative {
+		hint = histogram.UnknownCounterReset
+	}
+
+	for _, a := range n.cache {
+		for key, s := range a.fields {
+			h := &histogram.FloatHistogram{
+				CounterResetHint: hint,
+				Schema:           s.schema,
+				ZeroThreshold:    n.ZeroThreshold,
+				ZeroCount:        s.zeroCount,
+				Count:            s.count,
+				Sum:              s.sum,
+			}
+			h.PositiveSpans, h.PositiveBuckets = buckets(s.positive)
+			h.NegativeSpans, h.NegativeBuckets = buckets(s.negative)
+
+			acc.AddHistogram(a.name+"_"+key, nativehistogram.Fields(h), a.tags)
+		}
+	}
+}
+
+func (n *NativeHistogram) Reset() {
+	// Keep the histograms over all periods for cumulative output
+	if n.Cumulative {
+		return
+	}
+	n.cache = make(map[uint64]*aggregate)
+}
+
+// observe adds the value to the sketch and reduces the resolution of the
+// sketch if the number of buckets exceeds the limit
+func (n *NativeHistogram) observe(s *sketch, value float64) {
+	s.count++
+	s.sum += value
+
+	switch {
+	case math.Abs(value) <= n.ZeroThreshold:
+		s.zeroCount++
+		return
+	case value > 0:
+		s.positive[n.index(value, s.schema)]++
+	default:
+		s.negative[n.index(-value, s.schema)]++
+	}
+
+	for len(s.positive)+len(s.negative) > n.MaxBuckets && s.schema > minSchema {
+		s.schema--
+		s.positive = reduce(s.positive)
+		s.negative = reduce(s.negative)
+	}
+}
+
+// index returns the index of the bucket containing the given positive value.
+// Bucket i covers the range (base^(i-1), base^i] with base = 2^(2^-schema).
+func (n *NativeHistogram) index(value float64, schema int32) int32 {
+	frac, exp := math.Frexp(value)
+	if schema > 0 {
+		bounds := n.bounds[schema]
+		return int32((exp-1)*len(bounds) + sort.SearchFloat64s(bounds, frac))
+	}
+
+	idx := exp
+	if frac == 0.5 {
+		idx--
+	}
+	offset := (1 << -schema) - 1
+	return int32((idx + offset) >> -schema)
+}
+
+// reduce merges pairs of buckets to halve the resolution, i.e. to decrease
+// the schema by one
+func reduce(in map[int32]float64) map[int32]float64 {
+	out := make(map[int32]float64, len(in)/2+1)
+	for idx, count := range in {
+		out[(idx+1)>>1] += count
+	}
+	return out
+}
+
+// buckets converts the sparse buckets to spans and absolute bucket counts
+func buckets(in map[int32]float64) ([]histogram.Span, []float64) {
+	if len(in) == 0 {
+		return nil, nil
+	}
+
+	indices := make([]int32, 0, len(in))
+	for idx := range in {
+		indices = append(indices, idx)
+	}
+	slices.Sort(indices)
+
+	// Each span starts with the distance to the end of the previous span,
+	// the first span starts at the absolute index.
+	spans := make([]histogram.Span, 0, 1)
+	counts := make([]float64, 0, len(indices))
+	next := indices[0]
+	for i, idx := range indices {
+		if i == 0 || idx != next {
+			offset := idx - next
+			if i == 0 {
+				offset = idx
+			}
+			spans = append(spans, histogram.Span{Offset: offset})
+		}
+		spans[len(spans)-1].Length++
+		counts = append(counts, in[idx])
+		next = idx + 1
+	}
+	return spans, counts
+}
+
+func init() {
+	aggregators.Add("native_histogram", func() telegraf.Aggregator {
+		return &NativeHistogram{
+			Schema:        3,
+			MaxBuckets:    160,
+			ZeroThreshold: math.Ldexp(1, -128),
+		}
+	})
+}
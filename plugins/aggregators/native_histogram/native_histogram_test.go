@@ -0,0 +1,218 @@
+package native_histogram
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/prometheus/prometheus/model/histogram"
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/plugins/aggregators"
+	"github.com/influxdata/telegraf/plugins/common/nativehistogram"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func newNativeHistogram() *NativeHistogram {
+	return aggregators.Aggregators["native_histogram"]().(*NativeHistogram)
+}
+
+func TestInitInvalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *NativeHistogram
+		expected string
+	}{
+		{
+			name:     "schema too small",
+			plugin:   &NativeHistogram{Schema: -5, MaxBuckets: 10},
+			expected: "schema -5 out of range",
+		},
+		{
+			name:     "schema too large",
+			plugin:   &NativeHistogram{Schema: 9, MaxBuckets: 10},
+			expected: "schema 9 out of range",
+		},
+		{
+			name:     "no buckets",
+			plugin:   &NativeHistogram{Schema: 3},
+			expected: "max_buckets must be positive",
+		},
+		{
+			name:     "negative zero threshold",
+			plugin:   &NativeHistogram{Schema: 3, MaxBuckets: 10, ZeroThreshold: -1},
+			expected: "zero_threshold must not be negative",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.ErrorContains(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestBucketIndex(t *testing.T) {
+	plugin := newNativeHistogram()
+	require.NoError(t, plugin.Init())
+
+	// Bucket i covers the range (2^((i-1)/2^schema), 2^(i/2^schema)]
+	tests := []struct {
+		value    float64
+		schema   int32
+		expected int32
+	}{
+		{value: 1, schema: 3, expected: 0},
+		{value: 2, schema: 3, expected: 8},
+		{value: 1.5, schema: 3, expected: 5},
+		{value: 0.25, schema: 3, expected: -16},
+		{value: 1, schema: 0, expected: 0},
+		{value: 3, schema: 0, expected: 2},
+		{value: 4, schema: 0, expected: 2},
+		{value: 3, schema: -1, expected: 1},
+		{value: 5, schema: -1, expected: 2},
+		{value: 0.3, schema: -2, expected: 0},
+	}
+	for _, tt := range tests {
+		idx := plugin.index(tt.value, tt.schema)
+		require.Equalf(t, tt.expected, idx, "value %v with schema %d", tt.value, tt.schema)
+
+		// Cross-check with the bucket boundaries
+		width := math.Exp2(-float64(tt.schema))
+		require.Greater(t, tt.value, math.Exp2(float64(idx-1)*width))
+		require.LessOrEqual(t, tt.value, math.Exp2(float64(idx)*width))
+	}
+}
+
+func TestAggregate(t *testing.T) {
+	plugin := newNativeHistogram()
+	plugin.Schema = 0
+	require.NoError(t, plugin.Init())
+
+	now := time.Now()
+	for _, v := range []interface{}{int64(1), uint64(3), 4.0, 0.0, -2.0, 40.0, "skip"} {
+		plugin.Add(metric.New("test", map[string]string{"host": "a"}, map[string]interface{}{"value": v}, now))
+	}
+
+	var acc testutil.Accumulator
+	plugin.Push(&acc)
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"test_value",
+			map[string]string{"host": "a"},
+			map[string]interface{}{
+				"counter_reset_hint":     uint64(histogram.GaugeType),
+				"schema":                 int64(0),
+				"zero_threshold":         math.Ldexp(1, -128),
+				"zero_count":             1.0,
+				"count":                  6.0,
+				"sum":                    46.0,
+				"positive_span_0_offset": int64(0),
+				"positive_span_0_length": uint64(1),
+				"positive_span_1_offset": int64(1),
+				"positive_span_1_length": uint64(1),
+				"positive_span_2_offset": int64(3),
+				"positive_span_2_length": uint64(1),
+				"positive_bucket_0":      1.0,
+				"positive_bucket_1":      2.0,
+				"positive_bucket_2":      1.0,
+				"negative_span_0_offset": int64(1),
+				"negative_span_0_length": uint64(1),
+				"negative_bucket_0":      1.0,
+			},
+			now,
+			telegraf.Histogram,
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+
+	// The output must be a valid native histogram
+	h, ok := nativehistogram.FromFields(acc.GetTelegrafMetrics()[0].Fields())
+	require.True(t, ok)
+	require.NoError(t, h.Validate())
+}
+
+func TestFieldFilter(t *testing.T) {
+	plugin := newNativeHistogram()
+	plugin.Fields = []string{"a*"}
+	require.NoError(t, plugin.Init())
+
+	plugin.Add(metric.New("test", nil, map[string]interface{}{"a1": 1.0, "a2": 2.0, "b": 3.0}, time.Now()))
+
+	var acc testutil.Accumulator
+	plugin.Push(&acc)
+
+	names := make([]string, 0, len(acc.Metrics))
+	for _, m := range acc.GetTelegrafMetrics() {
+		names = append(names, m.Name())
+	}
+	require.ElementsMatch(t, []string{"test_a1", "test_a2"}, names)
+}
+
+func TestMaxBuckets(t *testing.T) {
+	plugin := newNativeHistogram()
+	plugin.MaxBuckets = 10
+	require.NoError(t, plugin.Init())
+
+	now := time.Now()
+	for i := range 1000 {
+		plugin.Add(metric.New("test", nil, map[string]interface{}{"value": float64(i) - 200}, now))
+	}
+
+	var acc testutil.Accumulator
+	plugin.Push(&acc)
+	require.Len(t, acc.Metrics, 1)
+
+	h, ok := nativehistogram.FromFields(acc.GetTelegrafMetrics()[0].Fields())
+	require.True(t, ok)
+	require.NoError(t, h.Validate())
+	require.Less(t, h.Schema, int32(3))
+	require.LessOrEqual(t, len(h.PositiveBuckets)+len(h.NegativeBuckets), 10)
+	require.InDelta(t, 1000.0, h.Count, 1e-9)
+	require.InDelta(t, 1.0, h.ZeroCount, 1e-9)
+}
+
+func TestReset(t *testing.T) {
+	tests := []struct {
+		name       string
+		cumulative bool
+		hint       histogram.CounterResetHint
+		count      float64
+	}{
+		{
+			name:  "gauge",
+			hint:  histogram.GaugeType,
+			count: 1,
+		},
+		{
+			name:       "cumulative",
+			cumulative: true,
+			hint:       histogram.UnknownCounterReset,
+			count:      2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := newNativeHistogram()
+			plugin.Cumulative = tt.cumulative
+			require.NoError(t, plugin.Init())
+
+			m := metric.New("test", nil, map[string]interface{}{"value": 42.0}, time.Now())
+			var acc testutil.Accumulator
+			plugin.Add(m)
+			plugin.Push(&acc)
+			plugin.Reset()
+			plugin.Add(m)
+			plugin.Push(&acc)
+			plugin.Reset()
+
+			require.Len(t, acc.Metrics, 2)
+			h, ok := nativehistogram.FromFields(acc.GetTelegrafMetrics()[1].Fields())
+			require.True(t, ok)
+			require.Equal(t, tt.hint, h.CounterResetHint)
+			require.InDelta(t, tt.count, h.Count, 1e-9)
+		})
+	}
+}
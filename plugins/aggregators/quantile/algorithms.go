@@ -1,7 +1,10 @@
 package quantile
 
 import (
+	"errors"
+	"fmt"
 	"math"
+	"slices"
 	"sort"
 
 	"github.com/caio/go-tdigest"
@@ -110,3 +113,150 @@ func (e *exactAlgorithmR8) Quantile(q float64) float64 {
 	// Linear interpolation
 	return e.xs[j] + gamma*(e.xs[j+1]-e.xs[j])
 }
+
+// hdrHistogram is a high-dynamic-range histogram using logarithmically sized
+// ranges for each power of two, each split into linear sub-buckets. The
+// number of sub-buckets is chosen to keep the relative error below the
+// requested number of significant decimal digits, independent of the value
+// range. Only populated buckets are stored, so memory is bounded by the
+// dynamic range of the data and not by the number of samples.
+type hdrHistogram struct {
+	slots    int64
+	positive map[int64]uint64
+	negative map[int64]uint64
+	zero     uint64
+	count    uint64
+	min      float64
+	max      float64
+
+	// Sorted bucket keys, invalidated when a new bucket is populated
+	positiveKeys []int64
+	negativeKeys []int64
+	sorted       bool
+}
+
+func newHDR(digits int) (algorithm, error) {
+	if digits < 1 || digits > 5 {
+		return nil, fmt.Errorf("significant digits %d out of range [1,5]", digits)
+	}
+
+	// Same as HdrHistogram, use the power of two covering twice the largest
+	// value with single unit resolution and use the upper half of the
+	// sub-buckets for each power of two.
+	magnitude := math.Ceil(math.Log2(2 * math.Pow10(digits)))
+	return &hdrHistogram{
+		slots:    int64(math.Exp2(magnitude - 1)),
+		positive: make(map[int64]uint64),
+		negative: make(map[int64]uint64),
+		min:      math.Inf(1),
+		max:      math.Inf(-1),
+	}, nil
+}
+
+// Add adds a value to the algorithm.
+func (h *hdrHistogram) Add(value float64) error {
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return errors.New("value must be finite")
+	}
+
+	h.count++
+	h.min = math.Min(h.min, value)
+	h.max = math.Max(h.max, value)
+
+	switch {
+	case value > 0:
+		key := h.key(value)
+		if _, found := h.positive[key]; !found {
+			h.sorted = false
+		}
+		h.positive[key]++
+	case value < 0:
+		key := h.key(-value)
+		if _, found := h.negative[key]; !found {
+			h.sorted = false
+		}
+		h.negative[key]++
+	default:
+		h.zero++
+	}
+
+	return nil
+}
+
+// Quantile returns the quantile value for the given q.
+func (h *hdrHistogram) Quantile(q float64) float64 {
+	// No information
+	if h.count == 0 {
+		return math.NaN()
+	}
+	if q <= 0 {
+		return h.min
+	}
+	if q >= 1 {
+		return h.max
+	}
+
+	if !h.sorted {
+		h.positiveKeys = sortedKeys(h.positive)
+		h.negativeKeys = sortedKeys(h.negative)
+		h.sorted = true
+	}
+
+	// Walk the buckets in order of their values until reaching the rank of
+	// the quantile and return the center of the bucket, limited to the
+	// observed range.
+	rank := max(uint64(math.Ceil(q*float64(h.count))), 1)
+	var seen uint64
+	for i := len(h.negativeKeys) - 1; i >= 0; i-- {
+		key := h.negativeKeys[i]
+		seen += h.negative[key]
+		if seen >= rank {
+			return h.clamp(-h.center(key))
+		}
+	}
+	seen += h.zero
+	if seen >= rank {
+		return 0
+	}
+	for _, key := range h.positiveKeys {
+		seen += h.positive[key]
+		if seen >= rank {
+			return h.clamp(h.center(key))
+		}
+	}
+	return h.max
+}
+
+// key returns the bucket index for the given positive value. The index is
+// monotonically increasing with the value.
+func (h *hdrHistogram) key(value float64) int64 {
+	frac, exp := math.Frexp(value)
+	slot := int64((2*frac - 1) * float64(h.slots))
+	return int64(exp)*h.slots + slot
+}
+
+// center returns the center value of the bucket with the given index.
+func (h *hdrHistogram) center(key int64) float64 {
+	exp := key / h.slots
+	slot := key % h.slots
+	if slot < 0 {
+		exp--
+		slot += h.slots
+	}
+	width := math.Ldexp(1/float64(h.slots), int(exp)-1)
+	lower := math.Ldexp(1, int(exp)-1) + float64(slot)*width
+	return lower + width/2
+}
+
+func (h *hdrHistogram) clamp(value float64) float64 {
+	return math.Max(h.min, math.Min(h.max, value))
+}
+
+func sortedKeys(buckets map[int64]uint64) []int64 {
+	keys := make([]int64, 0, len(buckets))
+	for k := range buckets {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
@@ -2,8 +2,11 @@
 package quantile
 
 import (
+	"container/list"
 	_ "embed"
 	"fmt"
+	"hash/fnv"
+	"slices"
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/plugins/aggregators"
@@ -13,24 +16,37 @@ import (
 var sampleConfig string
 
 type Quantile struct {
-	Quantiles     []float64       `toml:"quantiles"`
-	Compression   float64         `toml:"compression"`
-	AlgorithmType string          `toml:"algorithm"`
-	Log           telegraf.Logger `toml:"-"`
+	Quantiles         []float64       `toml:"quantiles"`
+	Compression       float64         `toml:"compression"`
+	SignificantDigits int             `toml:"significant_digits"`
+	AlgorithmType     string          `toml:"algorithm"`
+	GroupBy           []string        `toml:"group_by"`
+	MaxSeries         int             `toml:"max_series"`
+	AddCountAndSum    bool            `toml:"add_count_and_sum"`
+	Log               telegraf.Logger `toml:"-"`
 
 	newAlgorithm newAlgorithmFunc
-	cache        map[uint64]aggregate
+	cache        map[uint64]*list.Element
+	lru          *list.List
+	evicted      int
 
 	suffixes []string
 }
 
 type aggregate struct {
+	id     uint64
 	name   string
-	fields map[string]algorithm
+	fields map[string]*field
 	tags   map[string]string
 }
 
-type newAlgorithmFunc func(compression float64) (algorithm, error)
+type field struct {
+	algo  algorithm
+	count uint64
+	sum   float64
+}
+
+type newAlgorithmFunc func() (algorithm, error)
 
 func (*Quantile) SampleConfig() string {
 	return sampleConfig
@@ -39,18 +55,27 @@ func (*Quantile) SampleConfig() string {
 func (q *Quantile) Init() error {
 	switch q.AlgorithmType {
 	case "t-digest", "":
-		q.newAlgorithm = newTDigest
+		q.newAlgorithm = func() (algorithm, error) { return newTDigest(q.Compression) }
 	case "exact R7":
-		q.newAlgorithm = newExactR7
+		q.newAlgorithm = func() (algorithm, error) { return newExactR7(q.Compression) }
 	case "exact R8":
-		q.newAlgorithm = newExactR8
+		q.newAlgorithm = func() (algorithm, error) { return newExactR8(q.Compression) }
+	case "hdr":
+		if q.SignificantDigits == 0 {
+			q.SignificantDigits = 3
+		}
+		q.newAlgorithm = func() (algorithm, error) { return newHDR(q.SignificantDigits) }
 	default:
 		return fmt.Errorf("unknown algorithm type %q", q.AlgorithmType)
 	}
-	if _, err := q.newAlgorithm(q.Compression); err != nil {
+	if _, err := q.newAlgorithm(); err != nil {
 		return fmt.Errorf("cannot create %q algorithm: %w", q.AlgorithmType, err)
 	}
 
+	if q.MaxSeries < 0 {
+		return fmt.Errorf("invalid 'max_series' value %d", q.MaxSeries)
+	}
+
 	if len(q.Quantiles) == 0 {
 		q.Quantiles = []float64{0.25, 0.5, 0.75}
 	}
@@ -74,50 +99,68 @@ func (q *Quantile) Init() error {
 }
 
 func (q *Quantile) Add(in telegraf.Metric) {
-	id := in.HashID()
-	if cached, ok := q.cache[id]; ok {
-		fields := in.Fields()
-		for k, algo := range cached.fields {
-			if field, ok := fields[k]; ok {
-				if v, isconvertible := convert(field); isconvertible {
-					err := algo.Add(v)
-					if err != nil {
-						q.Log.Errorf("adding cached field %s: %v", k, err)
-					}
-				}
-			}
+	id := q.seriesID(in)
+	var a *aggregate
+	if elem, ok := q.cache[id]; ok {
+		q.lru.MoveToFront(elem)
+		a = elem.Value.(*aggregate)
+	} else {
+		// New series, evict the least recently updated one if necessary
+		if q.MaxSeries > 0 && len(q.cache) >= q.MaxSeries {
+			oldest := q.lru.Back()
+			delete(q.cache, oldest.Value.(*aggregate).id)
+			q.lru.Remove(oldest)
+			q.evicted++
 		}
-		return
-	}
 
-	// New metric, setup cache and init algorithm
-	a := aggregate{
-		name:   in.Name(),
-		tags:   in.Tags(),
-		fields: make(map[string]algorithm),
+		a = &aggregate{
+			id:     id,
+			name:   in.Name(),
+			tags:   q.seriesTags(in),
+			fields: make(map[string]*field),
+		}
+		q.cache[id] = q.lru.PushFront(a)
 	}
-	for k, field := range in.Fields() {
-		if v, isconvertible := convert(field); isconvertible {
-			algo, err := q.newAlgorithm(q.Compression)
-			if err != nil {
-				q.Log.Errorf("generating algorithm %s: %v", k, err)
-			}
-			err = algo.Add(v)
+
+	for _, f := range in.FieldList() {
+		v, isconvertible := convert(f.Value)
+		if !isconvertible {
+			continue
+		}
+		entry, found := a.fields[f.Key]
+		if !found {
+			algo, err := q.newAlgorithm()
 			if err != nil {
-				q.Log.Errorf("adding field %s: %v", k, err)
+				q.Log.Errorf("generating algorithm %s: %v", f.Key, err)
+				continue
 			}
-			a.fields[k] = algo
+			entry = &field{algo: algo}
+			a.fields[f.Key] = entry
+		}
+		if err := entry.algo.Add(v); err != nil {
+			q.Log.Errorf("adding field %s: %v", f.Key, err)
+			continue
 		}
+		entry.count++
+		entry.sum += v
 	}
-	q.cache[id] = a
 }
 
 func (q *Quantile) Push(acc telegraf.Accumulator) {
-	for _, aggregate := range q.cache {
-		fields := make(map[string]interface{}, len(aggregate.fields)*len(q.Quantiles))
-		for k, algo := range aggregate.fields {
+	if q.evicted > 0 {
+		q.Log.Warnf("Evicted %d series due to exceeding 'max_series'", q.evicted)
+	}
+
+	for elem := q.lru.Front(); elem != nil; elem = elem.Next() {
+		aggregate := elem.Value.(*aggregate)
+		fields := make(map[string]interface{}, len(aggregate.fields)*(len(q.Quantiles)+2))
+		for k, f := range aggregate.fields {
 			for i, qtl := range q.Quantiles {
-				fields[k+q.suffixes[i]] = algo.Quantile(qtl)
+				fields[k+q.suffixes[i]] = f.algo.Quantile(qtl)
+			}
+			if q.AddCountAndSum {
+				fields[k+"_count"] = f.count
+				fields[k+"_sum"] = f.sum
 			}
 		}
 		acc.AddFields(aggregate.name, fields, aggregate.tags)
@@ -125,7 +168,45 @@ func (q *Quantile) Push(acc telegraf.Accumulator) {
 }
 
 func (q *Quantile) Reset() {
-	q.cache = make(map[uint64]aggregate)
+	q.cache = make(map[uint64]*list.Element)
+	q.lru = list.New()
+	q.evicted = 0
+}
+
+// seriesID returns the identifier of the series the metric belongs to. If
+// grouping is enabled, only the name and the grouping tags are considered.
+func (q *Quantile) seriesID(in telegraf.Metric) uint64 {
+	if len(q.GroupBy) == 0 {
+		return in.HashID()
+	}
+
+	h := fnv.New64a()
+	h.Write([]byte(in.Name()))
+	h.Write([]byte("\n"))
+	for _, tag := range in.TagList() {
+		if !slices.Contains(q.GroupBy, tag.Key) {
+			continue
+		}
+		h.Write([]byte(tag.Key))
+		h.Write([]byte("\n"))
+		h.Write([]byte(tag.Value))
+		h.Write([]byte("\n"))
+	}
+	return h.Sum64()
+}
+
+func (q *Quantile) seriesTags(in telegraf.Metric) map[string]string {
+	if len(q.GroupBy) == 0 {
+		return in.Tags()
+	}
+
+	tags := make(map[string]string, len(q.GroupBy))
+	for _, key := range q.GroupBy {
+		if v, found := in.GetTag(key); found {
+			tags[key] = v
+		}
+	}
+	return tags
 }
 
 func convert(in interface{}) (float64, bool) {
@@ -1,7 +1,10 @@
 package quantile
 
 import (
+	"math"
 	"math/rand"
+	"sort"
+	"strconv"
 	"testing"
 	"time"
 
@@ -440,6 +443,141 @@ func TestMultipleMetricsExactR8(t *testing.T) {
 	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime(), epsilon, sort)
 }
 
+func TestConfigInvalidSignificantDigits(t *testing.T) {
+	q := Quantile{AlgorithmType: "hdr", SignificantDigits: 6}
+	err := q.Init()
+	require.ErrorContains(t, err, "significant digits 6 out of range")
+}
+
+func TestHDRAccuracy(t *testing.T) {
+	for _, digits := range []int{1, 2, 3, 4} {
+		t.Run(strconv.Itoa(digits), func(t *testing.T) {
+			hdr, err := newHDR(digits)
+			require.NoError(t, err)
+
+			// Use values spanning multiple orders of magnitude
+			values := make([]float64, 0, 10000)
+			for i := 1; i <= 10000; i++ {
+				v := math.Pow(10, float64(i%700)/100.0) * float64(i)
+				require.NoError(t, hdr.Add(v))
+				values = append(values, v)
+			}
+			sort.Float64s(values)
+
+			// Compare against the nearest-rank quantile of the samples
+			for _, qtl := range []float64{0.01, 0.1, 0.25, 0.5, 0.75, 0.9, 0.99, 0.999} {
+				expected := values[int(math.Ceil(qtl*float64(len(values))))-1]
+				actual := hdr.Quantile(qtl)
+				require.InEpsilonf(t, expected, actual, math.Pow10(-digits), "quantile %v", qtl)
+			}
+		})
+	}
+}
+
+func TestHDRNegativeAndZero(t *testing.T) {
+	hdr, err := newHDR(3)
+	require.NoError(t, err)
+
+	require.True(t, math.IsNaN(hdr.Quantile(0.5)))
+	for i := -50; i <= 50; i++ {
+		require.NoError(t, hdr.Add(float64(i)))
+	}
+	require.Error(t, hdr.Add(math.NaN()))
+
+	require.InDelta(t, -50.0, hdr.Quantile(0), 1e-9)
+	require.InDelta(t, -25.0, hdr.Quantile(0.25), 0.05)
+	require.InDelta(t, 0.0, hdr.Quantile(0.5), 1e-9)
+	require.InDelta(t, 25.0, hdr.Quantile(0.75), 0.05)
+	require.InDelta(t, 50.0, hdr.Quantile(1), 1e-9)
+}
+
+func TestGroupBy(t *testing.T) {
+	q := Quantile{
+		Quantiles:      []float64{0.5},
+		AlgorithmType:  "exact R7",
+		GroupBy:        []string{"service"},
+		AddCountAndSum: true,
+		Log:            testutil.Logger{},
+	}
+	require.NoError(t, q.Init())
+
+	for i := 0; i < 10; i++ {
+		for _, host := range []string{"a", "b", "c"} {
+			q.Add(metric.New(
+				"latency",
+				map[string]string{"service": "web", "host": host},
+				map[string]interface{}{"value": float64(i)},
+				time.Unix(0, 0),
+			))
+		}
+		q.Add(metric.New(
+			"latency",
+			map[string]string{"service": "db", "host": "a"},
+			map[string]interface{}{"value": float64(10 * i)},
+			time.Unix(0, 0),
+		))
+	}
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"latency",
+			map[string]string{"service": "web"},
+			map[string]interface{}{"value_050": 4.5, "value_count": uint64(30), "value_sum": 135.0},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"latency",
+			map[string]string{"service": "db"},
+			map[string]interface{}{"value_050": 45.0, "value_count": uint64(10), "value_sum": 450.0},
+			time.Unix(0, 0),
+		),
+	}
+
+	var acc testutil.Accumulator
+	q.Push(&acc)
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime(), testutil.SortMetrics())
+}
+
+func TestMaxSeries(t *testing.T) {
+	q := Quantile{
+		Quantiles:     []float64{0.5},
+		AlgorithmType: "hdr",
+		MaxSeries:     2,
+		Log:           testutil.Logger{},
+	}
+	require.NoError(t, q.Init())
+
+	add := func(series string, v float64) {
+		q.Add(metric.New(
+			"test",
+			map[string]string{"series": series},
+			map[string]interface{}{"value": v},
+			time.Unix(0, 0),
+		))
+	}
+	add("a", 1)
+	add("b", 2)
+	add("a", 1)
+	// Evicts "b" as it is the least recently updated series
+	add("c", 3)
+
+	expected := []telegraf.Metric{
+		metric.New("test", map[string]string{"series": "a"}, map[string]interface{}{"value_050": 1.0}, time.Unix(0, 0)),
+		metric.New("test", map[string]string{"series": "c"}, map[string]interface{}{"value_050": 3.0}, time.Unix(0, 0)),
+	}
+
+	var acc testutil.Accumulator
+	q.Push(&acc)
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime(), testutil.SortMetrics())
+
+	// The limit applies per period
+	q.Reset()
+	add("b", 2)
+	acc.ClearMetrics()
+	q.Push(&acc)
+	require.Len(t, acc.GetTelegrafMetrics(), 1)
+}
+
 func BenchmarkDefaultTDigest(b *testing.B) {
 	metrics := make([]telegraf.Metric, 0, 100)
 	for i := 0; i < 100; i++ {
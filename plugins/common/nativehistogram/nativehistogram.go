@@ -0,0 +1,155 @@
+// Package nativehistogram converts Prometheus native histograms from and to
+// the field layout used for histogram metrics in Telegraf.
+package nativehistogram
+
+import (
+	"fmt"
+
+	"github.com/prometheus/prometheus/model/histogram"
+)
+
+// Fields returns the fields representing the given histogram. Spans and
+// buckets are expanded into numbered fields, e.g. "positive_span_0_offset"
+// and "positive_bucket_0".
+func Fields(h *histogram.FloatHistogram) map[string]interface{} {
+	fields := map[string]interface{}{
+		"counter_reset_hint": uint64(h.CounterResetHint),
+		"schema":             int64(h.Schema),
+		"zero_threshold":     h.ZeroThreshold,
+		"zero_count":         h.ZeroCount,
+		"count":              h.Count,
+		"sum":                h.Sum,
+	}
+
+	// Expand positive and negative spans into fields
+	for i, span := range h.PositiveSpans {
+		fields[fmt.Sprintf("positive_span_%d_offset", i)] = int64(span.Offset)
+		fields[fmt.Sprintf("positive_span_%d_length", i)] = uint64(span.Length)
+	}
+	for i, span := range h.NegativeSpans {
+		fields[fmt.Sprintf("negative_span_%d_offset", i)] = int64(span.Offset)
+		fields[fmt.Sprintf("negative_span_%d_length", i)] = uint64(span.Length)
+	}
+
+	// Expand positive and negative buckets into fields
+	for i, bucket := range h.PositiveBuckets {
+		fields[fmt.Sprintf("positive_bucket_%d", i)] = bucket
+	}
+	for i, bucket := range h.NegativeBuckets {
+		fields[fmt.Sprintf("negative_bucket_%d", i)] = bucket
+	}
+
+	return fields
+}
+
+// FromFields reconstructs a native histogram from the given fields. The
+// function returns false if the fields do not represent a valid native
+// histogram.
+func FromFields(fields map[string]interface{}) (*histogram.FloatHistogram, bool) {
+	// Native histograms have count, sum, schema, counter_reset_hint, zero_threshold, zero_count
+	// If any of these are missing, we can't convert to a native histogram and short-circuit.
+	count, found := fields["count"]
+	if !found {
+		return nil, false
+	}
+	countFloat, ok := count.(float64)
+	if !ok {
+		return nil, false
+	}
+	sum, found := fields["sum"]
+	if !found {
+		return nil, false
+	}
+	sumFloat, ok := sum.(float64)
+	if !ok {
+		return nil, false
+	}
+	schema, found := fields["schema"]
+	if !found {
+		return nil, false
+	}
+	schemaInt, ok := schema.(int64)
+	if !ok {
+		return nil, false
+	}
+	counterResetHint, found := fields["counter_reset_hint"]
+	if !found {
+		return nil, false
+	}
+	counterResetHintInt, ok := counterResetHint.(uint64)
+	if !ok {
+		return nil, false
+	}
+	zeroThreshold, found := fields["zero_threshold"]
+	if !found {
+		return nil, false
+	}
+	zeroThresholdFloat, ok := zeroThreshold.(float64)
+	if !ok {
+		return nil, false
+	}
+	zeroCount, found := fields["zero_count"]
+	if !found {
+		return nil, false
+	}
+	zeroCountFloat, ok := zeroCount.(float64)
+	if !ok {
+		return nil, false
+	}
+
+	h := &histogram.FloatHistogram{
+		Count:            countFloat,
+		Sum:              sumFloat,
+		Schema:           int32(schemaInt),
+		CounterResetHint: histogram.CounterResetHint(counterResetHintInt),
+		ZeroThreshold:    zeroThresholdFloat,
+		ZeroCount:        zeroCountFloat,
+		PositiveSpans:    spansFromFields(fields, "positive"),
+		NegativeSpans:    spansFromFields(fields, "negative"),
+		PositiveBuckets:  bucketsFromFields(fields, "positive"),
+		NegativeBuckets:  bucketsFromFields(fields, "negative"),
+	}
+
+	if err := h.Validate(); err != nil {
+		return nil, false
+	}
+	return h, true
+}
+
+// spansFromFields collects the spans (offset, length pair) defining the
+// bucket boundaries. A native histogram can have zero or multiple spans, so
+// iterate until the first missing index.
+func spansFromFields(fields map[string]interface{}, prefix string) []histogram.Span {
+	spans := make([]histogram.Span, 0)
+	for i := 0; ; i++ {
+		offset, offsetFound := fields[fmt.Sprintf("%s_span_%d_offset", prefix, i)]
+		length, lengthFound := fields[fmt.Sprintf("%s_span_%d_length", prefix, i)]
+		if !offsetFound || !lengthFound {
+			break
+		}
+		offsetInt, offsetOk := offset.(int64)
+		lengthInt, lengthOk := length.(uint64)
+		if !offsetOk || !lengthOk {
+			break
+		}
+		spans = append(spans, histogram.Span{Offset: int32(offsetInt), Length: uint32(lengthInt)})
+	}
+	return spans
+}
+
+// bucketsFromFields collects the bucket counts until the first missing index
+func bucketsFromFields(fields map[string]interface{}, prefix string) []float64 {
+	buckets := make([]float64, 0)
+	for i := 0; ; i++ {
+		bucket, found := fields[fmt.Sprintf("%s_bucket_%d", prefix, i)]
+		if !found {
+			break
+		}
+		bucketFloat, ok := bucket.(float64)
+		if !ok {
+			break
+		}
+		buckets = append(buckets, bucketFloat)
+	}
+	return buckets
+}
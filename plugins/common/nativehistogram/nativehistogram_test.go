@@ -0,0 +1,75 @@
+package nativehistogram
+
+import (
+	"testing"
+
+	"github.com/prometheus/prometheus/model/histogram"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRoundtrip(t *testing.T) {
+	h := &histogram.FloatHistogram{
+		CounterResetHint: histogram.GaugeType,
+		Schema:           1,
+		ZeroThreshold:    0.001,
+		ZeroCount:        2,
+		Count:            9,
+		Sum:              3.5,
+		PositiveSpans:    []histogram.Span{{Offset: -1, Length: 2}, {Offset: 3, Length: 1}},
+		PositiveBuckets:  []float64{1, 2, 3},
+		NegativeSpans:    []histogram.Span{{Offset: 2, Length: 1}},
+		NegativeBuckets:  []float64{1},
+	}
+
+	fields := Fields(h)
+	require.Equal(t, int64(-1), fields["positive_span_0_offset"])
+	require.Equal(t, uint64(2), fields["positive_span_0_length"])
+	require.InDelta(t, 3.0, fields["positive_bucket_2"], 1e-9)
+
+	actual, ok := FromFields(fields)
+	require.True(t, ok)
+	require.Equal(t, h, actual)
+}
+
+func TestFromFieldsInvalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		fields map[string]interface{}
+	}{
+		{
+			name:   "classic histogram",
+			fields: map[string]interface{}{"count": 3.0, "sum": 1.0, "0.5": 1.0, "+Inf": 3.0},
+		},
+		{
+			name: "wrong type",
+			fields: map[string]interface{}{
+				"counter_reset_hint": uint64(0),
+				"schema":             3.0,
+				"zero_threshold":     0.0,
+				"zero_count":         0.0,
+				"count":              0.0,
+				"sum":                0.0,
+			},
+		},
+		{
+			name: "inconsistent buckets",
+			fields: map[string]interface{}{
+				"counter_reset_hint":     uint64(0),
+				"schema":                 int64(3),
+				"zero_threshold":         0.0,
+				"zero_count":             0.0,
+				"count":                  1.0,
+				"sum":                    1.0,
+				"positive_span_0_offset": int64(0),
+				"positive_span_0_length": uint64(2),
+				"positive_bucket_0":      1.0,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, ok := FromFields(tt.fields)
+			require.False(t, ok)
+		})
+	}
+}
@@ -0,0 +1,199 @@
+// Package schemaregistry implements a client for the Confluent schema
+// registry API shared by the Avro parser and serializer.
+package schemaregistry
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+const contentType = "application/vnd.schemaregistry.v1+json"
+
+// Error codes returned by the registry if the subject or version is unknown
+const (
+	ErrorSubjectNotFound = 40401
+	ErrorVersionNotFound = 40402
+)
+
+// Error is an error response of the registry
+type Error struct {
+	Code    int    `json:"error_code"`
+	Message string `json:"message"`
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("schema registry error %d: %s", e.Code, e.Message)
+}
+
+type Client struct {
+	url      string
+	username string
+	password string
+	client   *http.Client
+}
+
+// NewClient creates a client for the registry at the given address.
+// Credentials given as part of the address are used for basic
+// authentication.
+func NewClient(addr, caCertPath string) (*Client, error) {
+	var tlsCfg *tls.Config
+	if caCertPath != "" {
+		caCert, err := os.ReadFile(caCertPath)
+		if err != nil {
+			return nil, err
+		}
+		caCertPool := x509.NewCertPool()
+		caCertPool.AppendCertsFromPEM(caCert)
+		tlsCfg = &tls.Config{
+			RootCAs: caCertPool,
+		}
+	}
+	client := &http.Client{
+		Transport: &http.Transport{
+			TLSClientConfig: tlsCfg,
+			MaxIdleConns:    10,
+			IdleConnTimeout: 90 * time.Second,
+		},
+		Timeout: 30 * time.Second,
+	}
+
+	u, err := url.Parse(addr)
+	if err != nil {
+		return nil, fmt.Errorf("parsing registry URL failed: %w", err)
+	}
+
+	var username, password string
+	if u.User != nil {
+		username = u.User.Username()
+		password, _ = u.User.Password()
+		u.User = nil
+	}
+
+	return &Client{
+		url:      strings.TrimSuffix(u.String(), "/"),
+		username: username,
+		password: password,
+		client:   client,
+	}, nil
+}
+
+// SchemaByID returns the schema definition with the given ID
+func (c *Client) SchemaByID(id int) (string, error) {
+	var response struct {
+		Schema *string `json:"schema"`
+	}
+	endpoint := fmt.Sprintf("%s/schemas/ids/%d", c.url, id)
+	if err := c.do(http.MethodGet, endpoint, nil, &response); err != nil {
+		return "", err
+	}
+	if response.Schema == nil {
+		return "", errors.New("malformed response from schema registry: no 'schema' key")
+	}
+	return *response.Schema, nil
+}
+
+// Register registers the schema under the given subject and returns the
+// schema ID. Registering an already known schema returns the existing ID.
+func (c *Client) Register(subject, schema string) (int, error) {
+	var response struct {
+		ID int `json:"id"`
+	}
+	endpoint := fmt.Sprintf("%s/subjects/%s/versions", c.url, url.PathEscape(subject))
+	if err := c.do(http.MethodPost, endpoint, map[string]string{"schema": schema}, &response); err != nil {
+		return 0, err
+	}
+	return response.ID, nil
+}
+
+// CheckCompatibility checks the schema against the latest version of the
+// subject. A subject without any version is compatible to every schema.
+func (c *Client) CheckCompatibility(subject, schema string) error {
+	var response struct {
+		IsCompatible bool     `json:"is_compatible"`
+		Messages     []string `json:"messages"`
+	}
+	endpoint := fmt.Sprintf("%s/compatibility/subjects/%s/versions/latest?verbose=true", c.url, url.PathEscape(subject))
+	err := c.do(http.MethodPost, endpoint, map[string]string{"schema": schema}, &response)
+	if err != nil {
+		var rerr *Error
+		if errors.As(err, &rerr) && (rerr.Code == ErrorSubjectNotFound || rerr.Code == ErrorVersionNotFound) {
+			return nil
+		}
+		return err
+	}
+	if !response.IsCompatible {
+		if len(response.Messages) > 0 {
+			return fmt.Errorf("schema incompatible with latest version of subject %q: %s", subject, strings.Join(response.Messages, "; "))
+		}
+		return fmt.Errorf("schema incompatible with latest version of subject %q", subject)
+	}
+	return nil
+}
+
+// Latest returns the ID and definition of the latest schema of the subject.
+func (c *Client) Latest(subject string) (int, string, error) {
+	var response struct {
+		ID     int    `json:"id"`
+		Schema string `json:"schema"`
+	}
+	endpoint := fmt.Sprintf("%s/subjects/%s/versions/latest", c.url, url.PathEscape(subject))
+	if err := c.do(http.MethodGet, endpoint, nil, &response); err != nil {
+		return 0, "", err
+	}
+	if response.Schema == "" {
+		return 0, "", errors.New("malformed response from schema registry: no 'schema' key")
+	}
+	return response.ID, response.Schema, nil
+}
+
+func (c *Client) do(method, endpoint string, body, result interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		buf, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(buf)
+	}
+
+	req, err := http.NewRequest(method, endpoint, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", contentType)
+	if body != nil {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if c.username != "" {
+		req.SetBasicAuth(c.username, c.password)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		rerr := &Error{Code: resp.StatusCode}
+		if err := json.NewDecoder(resp.Body).Decode(rerr); err != nil || rerr.Message == "" {
+			rerr.Message = resp.Status
+		}
+		return rerr
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+		return fmt.Errorf("decoding schema registry response failed: %w", err)
+	}
+	return nil
+}
@@ -1,4 +1,4 @@
-package sql
+package sqldriver
 
 import (
 	// Blank imports to register the drivers
@@ -0,0 +1,55 @@
+// Package sqldriver registers the SQL drivers supported by Telegraf and maps
+// database types to the corresponding driver.
+package sqldriver
+
+import (
+	dbsql "database/sql"
+	"fmt"
+	"sort"
+
+	"github.com/influxdata/telegraf/internal/choice"
+)
+
+// Derive the sql-framework driver name from our config name. This abstracts
+// the actual driver from the database-type the user wants.
+var aliases = map[string]string{
+	"cockroach": "pgx",
+	"tidb":      "mysql",
+	"mssql":     "sqlserver",
+	"maria":     "mysql",
+	"postgres":  "pgx",
+	"oracle":    "oracle",
+}
+
+// Resolve returns the name of the registered driver for the given database
+// type or driver name
+func Resolve(name string) (string, error) {
+	driverName := name
+	if driver, ok := aliases[name]; ok {
+		driverName = driver
+	}
+
+	availDrivers := dbsql.Drivers()
+	if choice.Contains(driverName, availDrivers) {
+		return driverName, nil
+	}
+
+	for d, r := range aliases {
+		if choice.Contains(r, availDrivers) {
+			availDrivers = append(availDrivers, d)
+		}
+	}
+
+	// Sort the list of drivers and make them unique
+	sort.Strings(availDrivers)
+	last := 0
+	for _, d := range availDrivers {
+		if d != availDrivers[last] {
+			last++
+			availDrivers[last] = d
+		}
+	}
+	availDrivers = availDrivers[:last+1]
+
+	return "", fmt.Errorf("driver %q not supported use one of %v", name, availDrivers)
+}
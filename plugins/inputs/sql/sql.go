@@ -8,7 +8,6 @@ import (
 	"errors"
 	"fmt"
 	"os"
-	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -18,6 +17,7 @@ import (
 	"github.com/influxdata/telegraf/filter"
 	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/internal/choice"
+	"github.com/influxdata/telegraf/plugins/common/sqldriver"
 	"github.com/influxdata/telegraf/plugins/inputs"
 )
 
@@ -168,42 +168,11 @@ func (s *SQL) Init() error {
 		}
 	}
 
-	// Derive the sql-framework driver name from our config name. This abstracts the actual driver
-	// from the database-type the user wants.
-	aliases := map[string]string{
-		"cockroach": "pgx",
-		"tidb":      "mysql",
-		"mssql":     "sqlserver",
-		"maria":     "mysql",
-		"postgres":  "pgx",
-		"oracle":    "oracle",
-	}
-	s.driverName = s.Driver
-	if driver, ok := aliases[s.Driver]; ok {
-		s.driverName = driver
-	}
-
-	availDrivers := dbsql.Drivers()
-	if !choice.Contains(s.driverName, availDrivers) {
-		for d, r := range aliases {
-			if choice.Contains(r, availDrivers) {
-				availDrivers = append(availDrivers, d)
-			}
-		}
-
-		// Sort the list of drivers and make them unique
-		sort.Strings(availDrivers)
-		last := 0
-		for _, d := range availDrivers {
-			if d != availDrivers[last] {
-				last++
-				availDrivers[last] = d
-			}
-		}
-		availDrivers = availDrivers[:last+1]
-
-		return fmt.Errorf("driver %q not supported use one of %v", s.Driver, availDrivers)
+	driverName, err := sqldriver.Resolve(s.Driver)
+	if err != nil {
+		return err
 	}
+	s.driverName = driverName
 
 	if s.DisconnectedServersBehavior == "" {
 		s.DisconnectedServersBehavior = "error"
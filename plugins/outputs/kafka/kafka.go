@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"slices"
 	"strings"
+	"sync/atomic"
 	"text/template"
 	"time"
 
@@ -51,6 +52,9 @@ type Kafka struct {
 	producer     sarama.SyncProducer
 	headerTmpl   map[string]*template.Template
 
+	// Reconnect with the new SASL credentials on the next write
+	secretsUpdated atomic.Bool
+
 	serializer telegraf.Serializer
 }
 
@@ -148,7 +152,38 @@ func (k *Kafka) Close() error {
 	return k.producer.Close()
 }
 
+// SecretsUpdated is called when the SASL credentials changed in their
+// secret store, e.g. due to rotation
+func (k *Kafka) SecretsUpdated() error {
+	k.secretsUpdated.Store(true)
+	return nil
+}
+
+// reconnect replaces the producer by one using the current SASL credentials
+func (k *Kafka) reconnect() error {
+	if err := k.SetSASLConfig(k.saramaConfig); err != nil {
+		return fmt.Errorf("updating SASL config failed: %w", err)
+	}
+	producer, err := k.producerFunc(k.Brokers, k.saramaConfig)
+	if err != nil {
+		return fmt.Errorf("reconnecting with updated secrets failed: %w", err)
+	}
+	if err := k.producer.Close(); err != nil {
+		k.Log.Warnf("Closing previous producer failed: %v", err)
+	}
+	k.producer = producer
+	k.Log.Debug("Reconnected with updated secrets")
+	return nil
+}
+
 func (k *Kafka) Write(metrics []telegraf.Metric) error {
+	if k.secretsUpdated.Swap(false) {
+		if err := k.reconnect(); err != nil {
+			k.secretsUpdated.Store(true)
+			return err
+		}
+	}
+
 	msgs := make([]*sarama.ProducerMessage, 0, len(metrics))
 	for _, metric := range metrics {
 		metric, topic := k.getTopicName(metric)
@@ -10,6 +10,7 @@ import (
 	kafkacontainer "github.com/testcontainers/testcontainers-go/modules/kafka"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/metric"
 	"github.com/influxdata/telegraf/plugins/serializers/influx"
 	"github.com/influxdata/telegraf/testutil"
@@ -367,6 +368,58 @@ func TestHeaders(t *testing.T) {
 	}
 }
 
+func TestSecretRotation(t *testing.T) {
+	// Setup a dynamic secret as provided by a secret store
+	password := "Ood Bnar"
+	secret := config.NewSecret([]byte("@{mock:password}"))
+	require.NoError(t, secret.Link(map[string]telegraf.ResolveFunc{
+		"@{mock:password}": func() ([]byte, bool, error) {
+			return []byte(password), true, nil
+		},
+	}))
+
+	// Record the password of each created producer
+	var passwords []string
+	s := &influx.Serializer{}
+	require.NoError(t, s.Init())
+	plugin := &Kafka{
+		Brokers: []string{"127.0.0.1"},
+		Topic:   "telegraf",
+		Log:     testutil.Logger{},
+		producerFunc: func(addrs []string, cfg *sarama.Config) (sarama.SyncProducer, error) {
+			passwords = append(passwords, cfg.Net.SASL.Password)
+			return newMockProducer(addrs, cfg)
+		},
+	}
+	plugin.SASLUsername = config.NewSecret([]byte("telegraf"))
+	plugin.SASLPassword = secret
+	plugin.SetSerializer(s)
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Connect())
+	defer plugin.Close()
+
+	input := []telegraf.Metric{
+		metric.New("cpu", map[string]string{}, map[string]interface{}{"value": 42.0}, time.Unix(0, 0)),
+	}
+	require.NoError(t, plugin.Write(input))
+	require.Equal(t, []string{"Ood Bnar"}, passwords)
+
+	// Rotate the secret and notify the plugin
+	password = "Obi-Wan Kenobi"
+	require.NoError(t, plugin.SecretsUpdated())
+	require.NoError(t, plugin.Write(input))
+	require.Equal(t, []string{"Ood Bnar", "Obi-Wan Kenobi"}, passwords)
+
+	// The new producer must be used for writing
+	producer, ok := plugin.producer.(*mockProducer)
+	require.True(t, ok, "invalid producer type")
+	require.Len(t, producer.sent, 1)
+
+	// Subsequent writes must not reconnect
+	require.NoError(t, plugin.Write(input))
+	require.Len(t, passwords, 2)
+}
+
 type mockProducer struct {
 	sent []*sarama.ProducerMessage
 	sarama.SyncProducer
@@ -0,0 +1,59 @@
+package opentelemetry
+
+import (
+	"github.com/prometheus/prometheus/model/histogram"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+
+	"github.com/influxdata/telegraf"
+)
+
+// addExponentialHistogram adds the native histogram as exponential histogram
+// data point to the given metric slice. Prometheus bucket i covers the range
+// (base^(i-1), base^i] while OpenTelemetry bucket i covers (base^i, base^(i+1)]
+// so the bucket offsets differ by one.
+func addExponentialHistogram(ms pmetric.MetricSlice, m telegraf.Metric, h *histogram.FloatHistogram) {
+	om := ms.AppendEmpty()
+	om.SetName(m.Name())
+
+	eh := om.SetEmptyExponentialHistogram()
+	if h.CounterResetHint == histogram.GaugeType {
+		eh.SetAggregationTemporality(pmetric.AggregationTemporalityDelta)
+	} else {
+		eh.SetAggregationTemporality(pmetric.AggregationTemporalityCumulative)
+	}
+
+	dp := eh.DataPoints().AppendEmpty()
+	for _, tag := range m.TagList() {
+		dp.Attributes().PutStr(tag.Key, tag.Value)
+	}
+	dp.SetTimestamp(pcommon.NewTimestampFromTime(m.Time()))
+	dp.SetScale(h.Schema)
+	dp.SetCount(uint64(h.Count))
+	dp.SetSum(h.Sum)
+	dp.SetZeroCount(uint64(h.ZeroCount))
+	dp.SetZeroThreshold(h.ZeroThreshold)
+
+	fillExponentialBuckets(dp.Positive(), h.PositiveBucketIterator())
+	fillExponentialBuckets(dp.Negative(), h.NegativeBucketIterator())
+}
+
+// fillExponentialBuckets converts the sparse Prometheus buckets into the dense
+// OpenTelemetry representation filling gaps with empty buckets
+func fillExponentialBuckets(dst pmetric.ExponentialHistogramDataPointBuckets, it histogram.BucketIterator[float64]) {
+	var next int32
+	first := true
+	for it.Next() {
+		bucket := it.At()
+		if first {
+			dst.SetOffset(bucket.Index - 1)
+			next = bucket.Index
+			first = false
+		}
+		for ; next < bucket.Index; next++ {
+			dst.BucketCounts().Append(0)
+		}
+		dst.BucketCounts().Append(uint64(bucket.Count))
+		next++
+	}
+}
@@ -12,6 +12,7 @@ import (
 
 	"github.com/influxdata/influxdb-observability/common"
 	"github.com/influxdata/influxdb-observability/influx2otel"
+	"go.opentelemetry.io/collector/pdata/pmetric"
 	"go.opentelemetry.io/collector/pdata/pmetric/pmetricotlp"
 	_ "google.golang.org/grpc/encoding/gzip" // Blank import to allow gzip encoding
 	"google.golang.org/grpc/metadata"
@@ -19,6 +20,7 @@ import (
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/plugins/common/nativehistogram"
 	"github.com/influxdata/telegraf/plugins/common/proxy"
 	"github.com/influxdata/telegraf/plugins/common/tls"
 	"github.com/influxdata/telegraf/plugins/outputs"
@@ -172,7 +174,17 @@ func (o *OpenTelemetry) Write(metrics []telegraf.Metric) error {
 
 func (o *OpenTelemetry) sendBatch(metrics []telegraf.Metric) error {
 	batch := o.metricsConverter.NewBatch()
+	nativeHistograms := pmetric.NewMetricSlice()
 	for _, metric := range metrics {
+		// Native histograms cannot be represented by the converter so send
+		// them as exponential histograms
+		if metric.Type() == telegraf.Histogram {
+			if h, ok := nativehistogram.FromFields(metric.Fields()); ok {
+				addExponentialHistogram(nativeHistograms, metric, h)
+				continue
+			}
+		}
+
 		var vType common.InfluxMetricValueType
 		switch metric.Type() {
 		case telegraf.Gauge:
@@ -197,6 +209,10 @@ func (o *OpenTelemetry) sendBatch(metrics []telegraf.Metric) error {
 	}
 
 	md := pmetricotlp.NewExportRequestFromMetrics(batch.GetMetrics())
+	if nativeHistograms.Len() > 0 {
+		sm := md.Metrics().ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty()
+		nativeHistograms.MoveAndAppendTo(sm.Metrics())
+	}
 	if md.Metrics().ResourceMetrics().Len() == 0 {
 		return nil
 	}
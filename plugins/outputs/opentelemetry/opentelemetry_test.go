@@ -87,6 +87,81 @@ func TestOpenTelemetry(t *testing.T) {
 	require.JSONEq(t, string(expectJSON), string(gotJSON))
 }
 
+func TestOpenTelemetryNativeHistogram(t *testing.T) {
+	expect := pmetric.NewMetrics()
+	{
+		rm := expect.ResourceMetrics().AppendEmpty()
+		ilm := rm.ScopeMetrics().AppendEmpty()
+		m := ilm.Metrics().AppendEmpty()
+		m.SetName("request_duration")
+		eh := m.SetEmptyExponentialHistogram()
+		eh.SetAggregationTemporality(pmetric.AggregationTemporalityDelta)
+		dp := eh.DataPoints().AppendEmpty()
+		dp.Attributes().PutStr("foo", "bar")
+		dp.SetTimestamp(pcommon.Timestamp(1622848686000000000))
+		dp.SetScale(0)
+		dp.SetCount(5)
+		dp.SetSum(12.5)
+		dp.SetZeroCount(1)
+		dp.SetZeroThreshold(0.001)
+		dp.Positive().SetOffset(-1)
+		dp.Positive().BucketCounts().FromRaw([]uint64{1, 0, 2})
+		dp.Negative().SetOffset(1)
+		dp.Negative().BucketCounts().FromRaw([]uint64{1})
+	}
+	m := newMockOtelService(t)
+	t.Cleanup(m.Cleanup)
+
+	metricsConverter, err := influx2otel.NewLineProtocolToOtelMetrics(common.NoopLogger{})
+	require.NoError(t, err)
+	plugin := &OpenTelemetry{
+		ServiceAddress:   m.Address(),
+		Timeout:          config.Duration(time.Second),
+		Headers:          map[string]string{"test": "header1"},
+		metricsConverter: metricsConverter,
+		otlpMetricClient: &gRPCClient{
+			grpcClientConn:       m.GrpcClient(),
+			metricsServiceClient: pmetricotlp.NewGRPCClient(m.GrpcClient()),
+		},
+		Log: testutil.Logger{},
+	}
+
+	input := metric.New(
+		"request_duration",
+		map[string]string{"foo": "bar"},
+		map[string]interface{}{
+			"counter_reset_hint":     uint64(3),
+			"schema":                 int64(0),
+			"zero_threshold":         0.001,
+			"zero_count":             1.0,
+			"count":                  5.0,
+			"sum":                    12.5,
+			"positive_span_0_offset": int64(0),
+			"positive_span_0_length": uint64(1),
+			"positive_span_1_offset": int64(1),
+			"positive_span_1_length": uint64(1),
+			"positive_bucket_0":      1.0,
+			"positive_bucket_1":      2.0,
+			"negative_span_0_offset": int64(2),
+			"negative_span_0_length": uint64(1),
+			"negative_bucket_0":      1.0,
+		},
+		time.Unix(0, 1622848686000000000),
+		telegraf.Histogram,
+	)
+
+	require.NoError(t, plugin.Write([]telegraf.Metric{input}))
+
+	marshaller := pmetric.JSONMarshaler{}
+	expectJSON, err := marshaller.MarshalMetrics(expect)
+	require.NoError(t, err)
+
+	gotJSON, err := marshaller.MarshalMetrics(m.GotMetrics())
+	require.NoError(t, err)
+
+	require.JSONEq(t, string(expectJSON), string(gotJSON))
+}
+
 func TestOpenTelemetryHTTPProtobuf(t *testing.T) {
 	expect := pmetric.NewMetrics()
 	{
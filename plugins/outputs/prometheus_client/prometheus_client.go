@@ -5,6 +5,7 @@ import (
 	"context"
 	"crypto/tls"
 	_ "embed"
+	"errors"
 	"fmt"
 	"net"
 	"net/http"
@@ -18,6 +19,7 @@ import (
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/collectors"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
+	dto "github.com/prometheus/client_model/go"
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
@@ -62,6 +64,7 @@ type PrometheusClient struct {
 	ExportTimestamp    bool                               `toml:"export_timestamp"`
 	TypeMappings       serializers_prometheus.MetricTypes `toml:"metric_types"`
 	NameSanitization   string                             `toml:"name_sanitization"`
+	OpenMetrics        bool                               `toml:"openmetrics"`
 	HTTPHeaders        map[string]*config.Secret          `toml:"http_headers"`
 	Log                telegraf.Logger                    `toml:"-"`
 
@@ -117,6 +120,12 @@ func (p *PrometheusClient) Init() error {
 		return err
 	}
 
+	if p.OpenMetrics && p.MetricVersion != 2 {
+		return errors.New("openmetrics requires metric_version = 2")
+	}
+
+	var gatherer prometheus.Gatherer = registry
+
 	switch p.MetricVersion {
 	default:
 		fallthrough
@@ -134,13 +143,32 @@ func (p *PrometheusClient) Init() error {
 			return err
 		}
 	case 2:
-		p.collector = v2.NewCollector(
+		collector := v2.NewCollector(
 			time.Duration(p.ExpirationInterval),
 			p.StringAsLabel,
 			p.ExportTimestamp,
 			p.TypeMappings,
 			p.NameSanitization,
+			p.OpenMetrics,
 		)
+		p.collector = collector
+
+		// Serve the families directly for OpenMetrics as both the registry
+		// and merging gatherers drop metadata like units.
+		if p.OpenMetrics {
+			gatherer = prometheus.GathererFunc(func() ([]*dto.MetricFamily, error) {
+				mfs, err := registry.Gather()
+				if err != nil {
+					return nil, err
+				}
+				families, err := collector.Gather()
+				if err != nil {
+					return nil, err
+				}
+				return append(mfs, families...), nil
+			})
+			break
+		}
 		err := registry.Register(p.collector)
 		if err != nil {
 			return err
@@ -166,7 +194,11 @@ func (p *PrometheusClient) Init() error {
 
 	authHandler := internal.BasicAuthHandler(p.BasicUsername, password, "prometheus", onAuthError)
 	rangeHandler := internal.IPRangeHandler(ipRange, onError)
-	promHandler := promhttp.HandlerFor(registry, promhttp.HandlerOpts{ErrorHandling: promhttp.ContinueOnError})
+	promHandler := promhttp.HandlerFor(gatherer, promhttp.HandlerOpts{
+		ErrorHandling:                       promhttp.ContinueOnError,
+		EnableOpenMetrics:                   p.OpenMetrics,
+		EnableOpenMetricsTextCreatedSamples: p.OpenMetrics,
+	})
 	landingPageHandler := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
 		_, err := w.Write([]byte("Telegraf Output Plugin: Prometheus Client "))
 		if err != nil {
@@ -389,6 +389,42 @@ cpu_time_idle{host="example.org"} 42
 # HELP cpu_time_idle Telegraf collected metric
 # TYPE cpu_time_idle gauge
 cpu_time_idle{host="example.org"} 42
+`),
+		},
+		{
+			name: "openmetrics",
+			output: &PrometheusClient{
+				Listen:            ":0",
+				MetricVersion:     2,
+				CollectorsExclude: []string{"gocollector", "process"},
+				Path:              "/metrics",
+				OpenMetrics:       true,
+				Log:               logger,
+			},
+			metrics: []telegraf.Metric{
+				metric.New(
+					"prometheus",
+					map[string]string{
+						"unit":              "bytes",
+						"exemplar_trace_id": "abc",
+					},
+					map[string]interface{}{
+						"received_bytes":          42.0,
+						"received_bytes_created":  1700000000.0,
+						"received_bytes_exemplar": 3.0,
+					},
+					time.Unix(0, 0),
+					telegraf.Counter,
+				),
+			},
+			accept: "application/openmetrics-text;version=1.0.0",
+			expected: []byte(`
+# HELP received_bytes Telegraf collected metric
+# TYPE received_bytes counter
+# UNIT received_bytes bytes
+received_bytes_total 42.0 # {trace_id="abc"} 3.0
+received_bytes_created 1.7e+09
+# EOF
 `),
 		},
 	}
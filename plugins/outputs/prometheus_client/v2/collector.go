@@ -49,12 +49,14 @@ func NewCollector(
 	stringsAsLabel, exportTimestamp bool,
 	typeMapping serializers_prometheus.MetricTypes,
 	nameSanitization string,
+	openMetrics bool,
 ) *Collector {
 	cfg := serializers_prometheus.FormatConfig{
 		StringAsLabel:    stringsAsLabel,
 		ExportTimestamp:  exportTimestamp,
 		TypeMappings:     typeMapping,
 		NameSanitization: nameSanitization,
+		OpenMetrics:      openMetrics,
 	}
 
 	return &Collector{
@@ -86,6 +88,20 @@ func (c *Collector) Collect(ch chan<- prometheus.Metric) {
 	}
 }
 
+// Gather implements the prometheus.Gatherer interface. In contrast to
+// collecting the metrics via the registry, the families returned here keep
+// OpenMetrics metadata like the unit.
+func (c *Collector) Gather() ([]*dto.MetricFamily, error) {
+	c.Lock()
+	defer c.Unlock()
+
+	if c.expireDuration != 0 {
+		c.coll.Expire(time.Now(), c.expireDuration)
+	}
+
+	return c.coll.GetProto(), nil
+}
+
 func (c *Collector) Add(metrics []telegraf.Metric) error {
 	c.Lock()
 	defer c.Unlock()
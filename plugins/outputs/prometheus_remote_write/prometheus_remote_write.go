@@ -0,0 +1,291 @@
+//go:generate ../../../tools/config_includer/generator
+//go:generate ../../../tools/readme_config_includer/generator
+package prometheus_remote_write
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	_ "embed"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/internal"
+	common_http "github.com/influxdata/telegraf/plugins/common/http"
+	"github.com/influxdata/telegraf/plugins/outputs"
+	"github.com/influxdata/telegraf/plugins/serializers/prometheusremotewrite"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+const maxErrMsgLen = 1024
+
+type PrometheusRemoteWrite struct {
+	URL           string                    `toml:"url"`
+	Version       int                       `toml:"remote_write_version"`
+	Shards        int                       `toml:"shards"`
+	MaxRetryAfter config.Duration           `toml:"max_retry_after"`
+	StringAsLabel bool                      `toml:"string_as_label"`
+	UnitTag       string                    `toml:"unit_tag"`
+	Username      config.Secret             `toml:"username"`
+	Password      config.Secret             `toml:"password"`
+	Headers       map[string]*config.Secret `toml:"headers"`
+	Log           telegraf.Logger           `toml:"-"`
+	common_http.HTTPClientConfig
+
+	client      *http.Client
+	serializer  *prometheusremotewrite.Serializer
+	contentType string
+	version     string
+
+	retryTime time.Time
+	sync.Mutex
+}
+
+// shard contains the metrics of a subset of series along with their indices
+// in the written batch
+type shard struct {
+	metrics []telegraf.Metric
+	indices []int
+	err     error
+}
+
+// rejectError is returned for requests the receiver will never accept, so
+// the metrics must be dropped
+type rejectError struct {
+	err error
+}
+
+func (e *rejectError) Error() string {
+	return e.err.Error()
+}
+
+func (*PrometheusRemoteWrite) SampleConfig() string {
+	return sampleConfig
+}
+
+func (p *PrometheusRemoteWrite) Init() error {
+	if p.URL == "" {
+		return errors.New("missing 'url'")
+	}
+
+	switch p.Version {
+	case 0, 1:
+		p.Version = 1
+		p.contentType = "application/x-protobuf"
+		p.version = "0.1.0"
+	case 2:
+		p.contentType = "application/x-protobuf;proto=io.prometheus.write.v2.Request"
+		p.version = "2.0.0"
+	default:
+		return fmt.Errorf("invalid 'remote_write_version' %d", p.Version)
+	}
+
+	if p.Shards < 1 {
+		p.Shards = 1
+	}
+
+	p.serializer = &prometheusremotewrite.Serializer{
+		StringAsLabel: p.StringAsLabel,
+		Version:       p.Version,
+		UnitTag:       p.UnitTag,
+		Log:           p.Log,
+	}
+	return p.serializer.Init()
+}
+
+func (p *PrometheusRemoteWrite) Connect() error {
+	client, err := p.HTTPClientConfig.CreateClient(context.Background(), p.Log)
+	if err != nil {
+		return err
+	}
+	p.client = client
+
+	return nil
+}
+
+func (p *PrometheusRemoteWrite) Close() error {
+	if p.client != nil {
+		p.client.CloseIdleConnections()
+	}
+
+	return nil
+}
+
+func (p *PrometheusRemoteWrite) Write(metrics []telegraf.Metric) error {
+	p.Lock()
+	retryTime := p.retryTime
+	p.Unlock()
+	if time.Now().Before(retryTime) {
+		return fmt.Errorf("retry time requested by receiver has not elapsed, retrying at %v", retryTime)
+	}
+
+	// Distribute the metrics to the shards by their series. This keeps all
+	// samples of a series in the same shard and in the original order.
+	shards := make([]*shard, p.Shards)
+	for i := range shards {
+		shards[i] = &shard{}
+	}
+	for i, m := range metrics {
+		s := shards[m.HashID()%uint64(len(shards))]
+		s.metrics = append(s.metrics, m)
+		s.indices = append(s.indices, i)
+	}
+
+	var wg sync.WaitGroup
+	for _, s := range shards {
+		if len(s.metrics) == 0 {
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.err = p.send(s.metrics)
+		}()
+	}
+	wg.Wait()
+
+	// Accept the metrics of all successful shards and reject the ones the
+	// receiver will never accept. Metrics of all other shards are kept for
+	// retrying.
+	var writeErr internal.PartialWriteError
+	for _, s := range shards {
+		if s.err == nil {
+			writeErr.MetricsAccept = append(writeErr.MetricsAccept, s.indices...)
+			continue
+		}
+
+		writeErr.Err = s.err
+		var rerr *rejectError
+		if errors.As(s.err, &rerr) {
+			p.Log.Errorf("Dropping %d metrics: %v", len(s.indices), s.err)
+			writeErr.MetricsReject = append(writeErr.MetricsReject, s.indices...)
+			for range s.indices {
+				writeErr.MetricsRejectErrors = append(writeErr.MetricsRejectErrors, s.err)
+			}
+		}
+	}
+	if writeErr.Err != nil {
+		return &writeErr
+	}
+
+	return nil
+}
+
+func (p *PrometheusRemoteWrite) send(metrics []telegraf.Metric) error {
+	body, err := p.serializer.SerializeBatch(metrics)
+	if err != nil {
+		return &rejectError{fmt.Errorf("serializing metrics failed: %w", err)}
+	}
+
+	req, err := http.NewRequest(http.MethodPost, p.URL, bytes.NewBuffer(body))
+	if err != nil {
+		return err
+	}
+
+	if !p.Username.Empty() || !p.Password.Empty() {
+		username, err := p.Username.Get()
+		if err != nil {
+			return fmt.Errorf("getting username failed: %w", err)
+		}
+		password, err := p.Password.Get()
+		if err != nil {
+			username.Destroy()
+			return fmt.Errorf("getting password failed: %w", err)
+		}
+		req.SetBasicAuth(username.String(), password.String())
+		username.Destroy()
+		password.Destroy()
+	}
+
+	for k, v := range p.Headers {
+		secret, err := v.Get()
+		if err != nil {
+			return err
+		}
+		value := secret.String()
+		if strings.EqualFold(k, "host") {
+			req.Host = value
+		}
+		req.Header.Set(k, value)
+		secret.Destroy()
+	}
+
+	// Protocol headers must not be overridden by the user
+	req.Header.Set("User-Agent", internal.ProductToken())
+	req.Header.Set("Content-Type", p.contentType)
+	req.Header.Set("Content-Encoding", "snappy")
+	req.Header.Set("X-Prometheus-Remote-Write-Version", p.version)
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		_, err = io.Copy(io.Discard, resp.Body)
+		return err
+	}
+
+	var msg string
+	scanner := bufio.NewScanner(io.LimitReader(resp.Body, maxErrMsgLen))
+	if scanner.Scan() {
+		msg = scanner.Text()
+	}
+
+	// The receiver is overloaded or has a temporary problem, so retry
+	// honoring the time requested by the receiver
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500 {
+		if wait := p.retryAfter(resp.Header.Get("Retry-After")); wait > 0 {
+			p.Lock()
+			if t := time.Now().Add(wait); t.After(p.retryTime) {
+				p.retryTime = t
+			}
+			p.Unlock()
+			return fmt.Errorf("received status %q, retrying in %v: %s", resp.Status, wait, msg)
+		}
+		return fmt.Errorf("received status %q, retrying: %s", resp.Status, msg)
+	}
+
+	// The request is invalid and would never be accepted, e.g. due to
+	// out-of-order samples or invalid labels
+	return &rejectError{fmt.Errorf("received non-retryable status %q: %s", resp.Status, msg)}
+}
+
+// retryAfter returns the duration to wait given by the "Retry-After" header
+// either specified in seconds or as HTTP date
+func (p *PrometheusRemoteWrite) retryAfter(header string) time.Duration {
+	if header == "" {
+		return 0
+	}
+
+	var wait time.Duration
+	if seconds, err := strconv.ParseUint(header, 10, 64); err == nil {
+		wait = time.Duration(seconds) * time.Second
+	} else if t, err := http.ParseTime(header); err == nil {
+		wait = time.Until(t)
+	} else {
+		p.Log.Debugf("Ignoring invalid Retry-After header %q", header)
+		return 0
+	}
+
+	return max(0, min(wait, time.Duration(p.MaxRetryAfter)))
+}
+
+func init() {
+	outputs.Add("prometheus_remote_write", func() telegraf.Output {
+		return &PrometheusRemoteWrite{
+			MaxRetryAfter: config.Duration(5 * time.Minute),
+		}
+	})
+}
@@ -0,0 +1,303 @@
+package prometheus_remote_write
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang/snappy"
+	"github.com/prometheus/prometheus/prompb"
+	writev2 "github.com/prometheus/prometheus/prompb/io/prometheus/write/v2"
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInitFail(t *testing.T) {
+	plugin := &PrometheusRemoteWrite{}
+	require.ErrorContains(t, plugin.Init(), "missing 'url'")
+
+	plugin = &PrometheusRemoteWrite{URL: "http://localhost", Version: 3}
+	require.ErrorContains(t, plugin.Init(), "invalid 'remote_write_version' 3")
+}
+
+func TestWrite(t *testing.T) {
+	tests := []struct {
+		name        string
+		version     int
+		contentType string
+		rwVersion   string
+	}{
+		{
+			name:        "v1",
+			version:     1,
+			contentType: "application/x-protobuf",
+			rwVersion:   "0.1.0",
+		},
+		{
+			name:        "v2",
+			version:     2,
+			contentType: "application/x-protobuf;proto=io.prometheus.write.v2.Request",
+			rwVersion:   "2.0.0",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var series atomic.Int64
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Header.Get("Content-Type") != tt.contentType {
+					w.WriteHeader(http.StatusUnsupportedMediaType)
+					t.Errorf("unexpected content-type %q", r.Header.Get("Content-Type"))
+					return
+				}
+				if r.Header.Get("Content-Encoding") != "snappy" {
+					w.WriteHeader(http.StatusBadRequest)
+					t.Errorf("unexpected content-encoding %q", r.Header.Get("Content-Encoding"))
+					return
+				}
+				if r.Header.Get("X-Prometheus-Remote-Write-Version") != tt.rwVersion {
+					w.WriteHeader(http.StatusBadRequest)
+					t.Errorf("unexpected version %q", r.Header.Get("X-Prometheus-Remote-Write-Version"))
+					return
+				}
+				if r.Header.Get("X-Scope-OrgID") != "tenant" {
+					w.WriteHeader(http.StatusBadRequest)
+					t.Errorf("unexpected tenant %q", r.Header.Get("X-Scope-OrgID"))
+					return
+				}
+
+				n, err := decodeSeries(r, tt.version)
+				if err != nil {
+					w.WriteHeader(http.StatusBadRequest)
+					t.Error(err)
+					return
+				}
+				series.Add(int64(n))
+				w.WriteHeader(http.StatusNoContent)
+			}))
+			defer ts.Close()
+
+			header := config.NewSecret([]byte("tenant"))
+			plugin := &PrometheusRemoteWrite{
+				URL:     ts.URL,
+				Version: tt.version,
+				Shards:  4,
+				Headers: map[string]*config.Secret{"X-Scope-OrgID": &header},
+				Log:     testutil.Logger{},
+			}
+			require.NoError(t, plugin.Init())
+			require.NoError(t, plugin.Connect())
+			defer plugin.Close()
+
+			require.NoError(t, plugin.Write(createMetrics(20)))
+			require.Equal(t, int64(20), series.Load())
+		})
+	}
+}
+
+func TestSharding(t *testing.T) {
+	// Record the order of the samples per series seen by the receiver
+	var mu sync.Mutex
+	var requests int
+	seen := make(map[string][]int64)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		decoded, err := snappy.Decode(nil, body)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		var req prompb.WriteRequest
+		if err := req.Unmarshal(decoded); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		requests++
+		for _, s := range req.Timeseries {
+			var key string
+			for _, l := range s.Labels {
+				key += l.Name + "=" + l.Value + ","
+			}
+			for _, sample := range s.Samples {
+				seen[key] = append(seen[key], sample.Timestamp)
+			}
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer ts.Close()
+
+	plugin := &PrometheusRemoteWrite{
+		URL:    ts.URL,
+		Shards: 3,
+		Log:    testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Connect())
+	defer plugin.Close()
+
+	for i := range 5 {
+		metrics := make([]telegraf.Metric, 0, 30)
+		for j := range 30 {
+			metrics = append(metrics, metric.New(
+				"test",
+				map[string]string{"series": fmt.Sprintf("s%02d", j)},
+				map[string]interface{}{"value": float64(i)},
+				time.Unix(int64(i), 0),
+			))
+		}
+		require.NoError(t, plugin.Write(metrics))
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Equal(t, 15, requests)
+	require.Len(t, seen, 30)
+	for key, timestamps := range seen {
+		require.Equal(t, []int64{0, 1000, 2000, 3000, 4000}, timestamps, key)
+	}
+}
+
+func TestRetry(t *testing.T) {
+	var status atomic.Int64
+	status.Store(http.StatusTooManyRequests)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		code := int(status.Load())
+		if code == http.StatusTooManyRequests {
+			w.Header().Set("Retry-After", "1")
+		}
+		w.WriteHeader(code)
+	}))
+	defer ts.Close()
+
+	plugin := &PrometheusRemoteWrite{
+		URL:           ts.URL,
+		MaxRetryAfter: config.Duration(time.Minute),
+		Log:           testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Connect())
+	defer plugin.Close()
+
+	// Throttling keeps all metrics for retrying
+	metrics := createMetrics(5)
+	err := plugin.Write(metrics)
+	var werr *internal.PartialWriteError
+	require.ErrorAs(t, err, &werr)
+	require.Empty(t, werr.MetricsAccept)
+	require.Empty(t, werr.MetricsReject)
+
+	// Writes within the requested retry time are refused without sending
+	status.Store(http.StatusOK)
+	require.ErrorContains(t, plugin.Write(metrics), "retry time requested by receiver has not elapsed")
+
+	// After the retry time, the metrics are accepted
+	require.Eventually(t, func() bool {
+		return plugin.Write(metrics) == nil
+	}, 3*time.Second, 100*time.Millisecond)
+
+	// Server errors are retried without delay
+	status.Store(http.StatusServiceUnavailable)
+	err = plugin.Write(metrics)
+	require.ErrorAs(t, err, &werr)
+	require.Empty(t, werr.MetricsAccept)
+	require.Empty(t, werr.MetricsReject)
+	status.Store(http.StatusOK)
+	require.NoError(t, plugin.Write(metrics))
+}
+
+func TestReject(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		if _, err := w.Write([]byte("out of order sample")); err != nil {
+			t.Error(err)
+		}
+	}))
+	defer ts.Close()
+
+	plugin := &PrometheusRemoteWrite{
+		URL: ts.URL,
+		Log: testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Connect())
+	defer plugin.Close()
+
+	err := plugin.Write(createMetrics(5))
+	var werr *internal.PartialWriteError
+	require.ErrorAs(t, err, &werr)
+	require.ErrorContains(t, err, "out of order sample")
+	require.Empty(t, werr.MetricsAccept)
+	require.ElementsMatch(t, []int{0, 1, 2, 3, 4}, werr.MetricsReject)
+	require.Len(t, werr.MetricsRejectErrors, 5)
+}
+
+func TestRetryAfter(t *testing.T) {
+	plugin := &PrometheusRemoteWrite{
+		MaxRetryAfter: config.Duration(time.Minute),
+		Log:           testutil.Logger{},
+	}
+
+	require.Equal(t, time.Duration(0), plugin.retryAfter(""))
+	require.Equal(t, time.Duration(0), plugin.retryAfter("soon"))
+	require.Equal(t, 30*time.Second, plugin.retryAfter("30"))
+	require.Equal(t, time.Minute, plugin.retryAfter("3600"))
+
+	date := time.Now().Add(20 * time.Second).UTC().Format(http.TimeFormat)
+	require.InDelta(t, 20*time.Second, plugin.retryAfter(date), float64(2*time.Second))
+}
+
+func createMetrics(n int) []telegraf.Metric {
+	metrics := make([]telegraf.Metric, 0, n)
+	for i := range n {
+		metrics = append(metrics, metric.New(
+			"cpu",
+			map[string]string{"cpu": fmt.Sprintf("cpu%d", i)},
+			map[string]interface{}{"usage": float64(i)},
+			time.Unix(0, 0),
+			telegraf.Gauge,
+		))
+	}
+	return metrics
+}
+
+func decodeSeries(r *http.Request, version int) (int, error) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return 0, err
+	}
+	decoded, err := snappy.Decode(nil, body)
+	if err != nil {
+		return 0, err
+	}
+
+	if version == 2 {
+		var req writev2.Request
+		if err := req.Unmarshal(decoded); err != nil {
+			return 0, err
+		}
+		return len(req.Timeseries), nil
+	}
+
+	var req prompb.WriteRequest
+	if err := req.Unmarshal(decoded); err != nil {
+		return 0, err
+	}
+	return len(req.Timeseries), nil
+}
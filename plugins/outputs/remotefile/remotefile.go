@@ -49,6 +49,7 @@ type File struct {
 	serializers    map[string]telegraf.Serializer
 	modified       map[string]time.Time
 	encoder        internal.ContentEncoder
+	batch          time.Time
 }
 
 func (*File) SampleConfig() string {
@@ -89,7 +90,10 @@ func (f *File) Init() error {
 	fs.SetLogger(slog.NewLogger(f.Log).Handler())
 
 	// Setup custom template functions
-	funcs := template.FuncMap{"now": time.Now}
+	funcs := template.FuncMap{
+		"now":   time.Now,
+		"batch": func() time.Time { return f.batch },
+	}
 
 	// Setup filename templates
 	f.templates = make([]*template.Template, 0, len(f.Files))
@@ -184,6 +188,10 @@ func (f *File) Close() error {
 func (f *File) Write(metrics []telegraf.Metric) error {
 	var buf bytes.Buffer
 
+	// Use the same time for all metrics of the batch to allow unique
+	// filenames per write
+	f.batch = time.Now()
+
 	// Group the metrics per output file
 	groups := make(map[string][]telegraf.Metric)
 	for _, raw := range metrics {
@@ -244,7 +252,7 @@ func (f *File) Write(metrics []telegraf.Metric) error {
 	}
 
 	// Write the files
-	t := time.Now()
+	t := f.batch
 	for fn, serialized := range groupBuffer {
 		// Make sure the directory exists
 		dir := filepath.Dir(filepath.ToSlash(fn))
@@ -15,8 +15,10 @@ import (
 	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/metric"
+	parsers_parquet "github.com/influxdata/telegraf/plugins/parsers/parquet"
 	"github.com/influxdata/telegraf/plugins/serializers/csv"
 	"github.com/influxdata/telegraf/plugins/serializers/influx"
+	"github.com/influxdata/telegraf/plugins/serializers/parquet"
 	"github.com/influxdata/telegraf/testutil"
 )
 
@@ -381,6 +383,78 @@ func TestCSVSerialization(t *testing.T) {
 	require.Contains(t, plugin.serializers, "test-b.csv")
 }
 
+func TestParquetSerialization(t *testing.T) {
+	// Both batches map to the same hourly name without the batch function
+	batches := [][]telegraf.Metric{
+		{
+			metric.New("test", map[string]string{"source": "a"}, map[string]interface{}{"value": int64(42)}, time.Unix(1587686400, 0)),
+		},
+		{
+			metric.New("test", map[string]string{"source": "b"}, map[string]interface{}{"value": int64(23)}, time.Unix(1587686410, 0)),
+		},
+	}
+
+	tmpdir := t.TempDir()
+
+	// Setup the plugin including the serializer
+	plugin := &File{
+		Remote:            config.NewSecret([]byte("local:" + tmpdir)),
+		Files:             []string{`test-{{.Time.Format "2006-01-02T15"}}-{{batch.UnixNano}}.parquet`},
+		UseBatchFormat:    true,
+		WriteBackInterval: config.Duration(100 * time.Millisecond),
+		Log:               &testutil.Logger{},
+		CompressionLevel:  -1,
+	}
+
+	plugin.SetSerializerFunc(func() (telegraf.Serializer, error) {
+		serializer := &parquet.Serializer{}
+		err := serializer.Init()
+		return serializer, err
+	})
+
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Connect())
+	defer plugin.Close()
+
+	// Write the batches and close the plugin. This is required to actually
+	// flush the data to disk
+	for _, batch := range batches {
+		require.NoError(t, plugin.Write(batch))
+	}
+	plugin.Close()
+
+	// Each batch must result in a separate, readable file
+	entries, err := os.ReadDir(tmpdir)
+	require.NoError(t, err)
+	require.Len(t, entries, len(batches))
+
+	prefix := "test-" + time.Unix(1587686400, 0).Format("2006-01-02T15") + "-"
+	var actual []telegraf.Metric
+	for _, entry := range entries {
+		require.True(t, strings.HasPrefix(entry.Name(), prefix), entry.Name())
+
+		buf, err := os.ReadFile(filepath.Join(tmpdir, entry.Name()))
+		require.NoError(t, err)
+
+		parser := &parsers_parquet.Parser{
+			MeasurementColumn: "measurement",
+			TagColumns:        []string{"source"},
+			TimestampColumn:   "timestamp",
+			TimestampFormat:   "unix_ns",
+		}
+		require.NoError(t, parser.Init())
+		metrics, err := parser.Parse(buf)
+		require.NoError(t, err)
+		actual = append(actual, metrics...)
+	}
+
+	expected := make([]telegraf.Metric, 0, len(batches))
+	for _, batch := range batches {
+		expected = append(expected, batch...)
+	}
+	testutil.RequireMetricsEqual(t, expected, actual, testutil.SortMetrics())
+}
+
 func TestForgettingFiles(t *testing.T) {
 	input := []telegraf.Metric{
 		metric.New(
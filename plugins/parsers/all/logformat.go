@@ -0,0 +1,5 @@
+//go:build !custom || parsers || parsers.logformat
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/parsers/logformat" // register plugin
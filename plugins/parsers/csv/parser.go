@@ -47,6 +47,9 @@ type Parser struct {
 	MetadataSeparators []string        `toml:"csv_metadata_separators"`
 	MetadataTrimSet    string          `toml:"csv_metadata_trim_set"`
 	ResetMode          string          `toml:"csv_reset_mode"`
+	InferSchema        bool            `toml:"csv_infer_schema"`
+	InferRows          int             `toml:"csv_infer_rows"`
+	InferTags          bool            `toml:"csv_infer_tags"`
 	Log                telegraf.Logger `toml:"-"`
 
 	DefaultTags map[string]string
@@ -61,6 +64,10 @@ type Parser struct {
 	remainingSkipRows     int
 	remainingHeaderRows   int
 	remainingMetadataRows int
+	inferred              map[string]*inferredColumn
+	schema                schema
+	resolved              bool
+	headerChanges         int
 
 	sync.Mutex
 }
@@ -132,6 +139,11 @@ func (p *Parser) Reset() {
 	p.remainingSkipRows = p.SkipRows
 	p.remainingHeaderRows = p.HeaderRowCount
 	p.remainingMetadataRows = p.MetadataRows
+
+	// Forget the inferred schema
+	p.inferred = make(map[string]*inferredColumn)
+	p.resolved = false
+	p.headerChanges = 0
 }
 
 func (p *Parser) Init() error {
@@ -159,6 +171,18 @@ func (p *Parser) Init() error {
 		return errors.New("csv_column_names field count doesn't match with csv_column_types")
 	}
 
+	if p.InferSchema {
+		if len(p.ColumnTypes) > 0 {
+			return errors.New("csv_column_types cannot be used together with csv_infer_schema")
+		}
+		if p.InferRows < 0 {
+			return fmt.Errorf("invalid csv_infer_rows %d", p.InferRows)
+		}
+		if p.InferRows == 0 {
+			p.InferRows = 100
+		}
+	}
+
 	if err := p.initializeMetadataSeparators(); err != nil {
 		return fmt.Errorf("initializing separators failed: %w", err)
 	}
@@ -330,7 +354,21 @@ func parseCSV(p *Parser, r io.Reader) ([]telegraf.Metric, error) {
 	}
 
 	metrics := make([]telegraf.Metric, 0, len(table))
-	for _, record := range table {
+	var sampled int
+	for i, record := range table {
+		if p.InferSchema {
+			// Switch to the new columns if the header changes and look
+			// ahead to infer the schema if columns are still sampled
+			if p.isHeader(record) {
+				p.changeHeader(record)
+				continue
+			}
+			if i >= sampled {
+				sampled = i + max(p.sample(table[i:]), 1)
+			}
+			p.resolveSchema()
+		}
+
 		m, err := p.parseRecord(record)
 		if err != nil {
 			if p.SkipErrors {
@@ -360,6 +398,11 @@ func (p *Parser) parseRecord(record []string) (telegraf.Metric, error) {
 		}
 	}
 
+	timestampColumn, timestampFormat := p.TimestampColumn, p.TimestampFormat
+	if p.InferSchema {
+		timestampColumn, timestampFormat = p.schema.timestampColumn, p.schema.timestampFormat
+	}
+
 	// skip columns in record
 	record = record[p.SkipColumns:]
 outer:
@@ -384,12 +427,31 @@ outer:
 				}
 			}
 
+			if p.InferSchema && p.schema.columns[i].tag {
+				tags[fieldName] = value
+				continue
+			}
+
 			// If the field name is the timestamp column, then keep field name as is.
-			if fieldName == p.TimestampColumn {
+			if fieldName == timestampColumn {
 				recordFields[fieldName] = value
 				continue
 			}
 
+			// Convert the inferred types, unknown types are converted
+			// automatically and missing values are skipped
+			if p.InferSchema && p.schema.columns[i].typ != "" {
+				if value == "" && p.schema.columns[i].typ != "string" {
+					continue
+				}
+				val, err := convert(p.schema.columns[i].typ, value)
+				if err != nil {
+					return nil, fmt.Errorf("column %q: %w", fieldName, err)
+				}
+				recordFields[fieldName] = val
+				continue
+			}
+
 			// Try explicit conversion only when column types is defined.
 			if len(p.ColumnTypes) > 0 {
 				// Throw error if current column count exceeds defined types.
@@ -397,29 +459,10 @@ outer:
 					return nil, errors.New("column type: column count exceeded")
 				}
 
-				var val interface{}
-				var err error
-
-				switch p.ColumnTypes[i] {
-				case "int":
-					val, err = strconv.ParseInt(value, 10, 64)
-					if err != nil {
-						return nil, fmt.Errorf("column type: parse int error %w", err)
-					}
-				case "float":
-					val, err = strconv.ParseFloat(value, 64)
-					if err != nil {
-						return nil, fmt.Errorf("column type: parse float error %w", err)
-					}
-				case "bool":
-					val, err = strconv.ParseBool(value)
-					if err != nil {
-						return nil, fmt.Errorf("column type: parse bool error %w", err)
-					}
-				default:
-					val = value
+				val, err := convert(p.ColumnTypes[i], value)
+				if err != nil {
+					return nil, err
 				}
-
 				recordFields[fieldName] = val
 				continue
 			}
@@ -457,13 +500,13 @@ outer:
 		}
 	}
 
-	metricTime, err := parseTimestamp(p.timeFunc, recordFields, p.TimestampColumn, p.TimestampFormat, p.location)
+	metricTime, err := parseTimestamp(p.timeFunc, recordFields, timestampColumn, timestampFormat, p.location)
 	if err != nil {
 		return nil, err
 	}
 
 	// Exclude `TimestampColumn` and `MeasurementColumn`
-	delete(recordFields, p.TimestampColumn)
+	delete(recordFields, timestampColumn)
 	delete(recordFields, p.MeasurementColumn)
 
 	m := metric.New(measurementName, tags, recordFields, metricTime)
@@ -471,6 +514,31 @@ outer:
 	return m, nil
 }
 
+// convert converts the value to the given column type
+func convert(typ, value string) (interface{}, error) {
+	switch typ {
+	case "int":
+		val, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("column type: parse int error %w", err)
+		}
+		return val, nil
+	case "float":
+		val, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("column type: parse float error %w", err)
+		}
+		return val, nil
+	case "bool":
+		val, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("column type: parse bool error %w", err)
+		}
+		return val, nil
+	}
+	return value, nil
+}
+
 // ParseTimestamp return a timestamp, if there is no timestamp on the csv it
 // will be the current timestamp, else it will try to parse the time according
 // to the format.
@@ -0,0 +1,382 @@
+package csv
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// timestampLayouts lists the layouts tried when inferring the format of
+// timestamp columns. Ambiguous layouts, like day and month first dates, are
+// ordered by preference.
+var timestampLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05Z07:00",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006/01/02 15:04:05",
+	"01/02/2006 15:04:05",
+	"02/01/2006 15:04:05",
+	"02.01.2006 15:04:05",
+	time.RFC1123Z,
+	time.RFC1123,
+	time.ANSIC,
+	time.UnixDate,
+	"2006-01-02",
+}
+
+// unixRanges lists the numeric timestamp formats with the range of values
+// considered to be a timestamp, i.e. the years 1990 to 2100
+var unixRanges = []struct {
+	format     string
+	start, end float64
+}{
+	{"unix", 631152000, 4102444800},
+	{"unix_ms", 631152000e3, 4102444800e3},
+	{"unix_us", 631152000e6, 4102444800e6},
+	{"unix_ns", 631152000e9, 4102444800e9},
+}
+
+// inferredColumn collects the sampled values of a column to infer its type
+type inferredColumn struct {
+	rows     int // number of sampled rows including empty values
+	values   int // number of sampled non-empty values
+	distinct map[string]bool
+	notInt   bool
+	notFloat bool
+	notBool  bool
+	formats  []string // timestamp formats matching all sampled values
+}
+
+// columnSchema is the resolved type of a column
+type columnSchema struct {
+	typ string // "int", "float", "bool", "string", "timestamp" or "" if unknown
+	tag bool
+}
+
+type schema struct {
+	columns         []columnSchema
+	timestampColumn string
+	timestampFormat string
+}
+
+func (c *inferredColumn) observe(name, value string) {
+	c.rows++
+	if value == "" {
+		return
+	}
+	c.values++
+	c.distinct[value] = true
+
+	if !c.notInt {
+		_, err := strconv.ParseInt(value, 10, 64)
+		c.notInt = err != nil
+	}
+	if !c.notFloat {
+		_, err := strconv.ParseFloat(value, 64)
+		c.notFloat = err != nil
+	}
+	if !c.notBool {
+		_, err := strconv.ParseBool(value)
+		c.notBool = err != nil
+	}
+
+	// Only keep the timestamp formats matching all values
+	candidates := c.formats
+	if c.values == 1 {
+		candidates = timestampLayouts
+		if isTimeName(name) {
+			candidates = make([]string, 0, len(unixRanges)+len(timestampLayouts))
+			for _, r := range unixRanges {
+				candidates = append(candidates, r.format)
+			}
+			candidates = append(candidates, timestampLayouts...)
+		}
+	}
+	formats := make([]string, 0, len(candidates))
+	for _, format := range candidates {
+		if matchesTimestamp(format, value) {
+			formats = append(formats, format)
+		}
+	}
+	c.formats = formats
+}
+
+func (c *inferredColumn) kind() string {
+	switch {
+	case c.values == 0:
+		return ""
+	case len(c.formats) > 0:
+		return "timestamp"
+	case !c.notInt:
+		return "int"
+	case !c.notFloat:
+		return "float"
+	case !c.notBool:
+		return "bool"
+	}
+	return "string"
+}
+
+// repeating checks if the sampled values repeat often enough for the column
+// to be used as tag, i.e. at most half of the values are distinct
+func (c *inferredColumn) repeating() bool {
+	return c.values > 1 && len(c.distinct) <= c.values/2
+}
+
+// isTimeName checks if the column name suggests a numeric column to contain
+// unix timestamps
+func isTimeName(name string) bool {
+	name = strings.ToLower(name)
+	return strings.Contains(name, "time") || strings.Contains(name, "date") ||
+		name == "ts" || strings.HasSuffix(name, "_ts") || strings.HasSuffix(name, "_at")
+}
+
+func matchesTimestamp(format, value string) bool {
+	for _, r := range unixRanges {
+		if r.format == format {
+			v, err := strconv.ParseFloat(value, 64)
+			return err == nil && v >= r.start && v < r.end
+		}
+	}
+	_, err := time.Parse(format, value)
+	return err == nil
+}
+
+// isScalar checks if the value is a number or boolean
+func isScalar(value string) bool {
+	if _, err := strconv.ParseFloat(value, 64); err == nil {
+		return true
+	}
+	_, err := strconv.ParseBool(value)
+	return err == nil
+}
+
+func (p *Parser) column(name string) *inferredColumn {
+	c, found := p.inferred[name]
+	if !found {
+		c = &inferredColumn{distinct: make(map[string]bool)}
+		p.inferred[name] = c
+	}
+	return c
+}
+
+// sample observes the values of the given records until a header row is
+// found or all columns are sampled. It returns the number of records
+// processed.
+func (p *Parser) sample(records [][]string) int {
+	for n, record := range records {
+		if n > 0 && p.isHeader(record) {
+			return n
+		}
+
+		sampling := false
+		for i, name := range p.ColumnNames {
+			c := p.column(name)
+			if c.rows >= p.InferRows {
+				continue
+			}
+			sampling = true
+			c.observe(name, p.value(record, i))
+		}
+		if !sampling {
+			return n
+		}
+		p.resolved = false
+	}
+	return len(records)
+}
+
+// value returns the value of the given column in the record with skipped
+// values being returned as empty string
+func (p *Parser) value(record []string, column int) string {
+	column += p.SkipColumns
+	if column >= len(record) {
+		return ""
+	}
+	value := record[column]
+	if p.TrimSpace {
+		value = strings.Trim(value, " ")
+	}
+	for _, s := range p.SkipValues {
+		if value == s {
+			return ""
+		}
+	}
+	return value
+}
+
+// isHeader checks if the record is a header row in the middle of the data,
+// e.g. due to concatenated files or a changing upstream format. This is the
+// case if all values are distinct names, at least one of them is a current
+// column name and none of the values matches the inferred type of its column.
+// Rows with non-numeric placeholders like "N/A" are therefore treated as data.
+func (p *Parser) isHeader(record []string) bool {
+	if p.HeaderRowCount != 1 || p.gotInitialColumnNames || len(record) <= p.SkipColumns {
+		return false
+	}
+
+	values := make([]string, 0, len(record)-p.SkipColumns)
+	seen := make(map[string]bool, len(record)-p.SkipColumns)
+	for _, v := range record[p.SkipColumns:] {
+		if p.TrimSpace {
+			v = strings.Trim(v, " ")
+		}
+		if v == "" || seen[v] || isScalar(v) {
+			return false
+		}
+		seen[v] = true
+		values = append(values, v)
+	}
+	if slices.Equal(values, p.ColumnNames) {
+		return true
+	}
+	if !slices.ContainsFunc(values, func(v string) bool { return slices.Contains(p.ColumnNames, v) }) {
+		return false
+	}
+
+	// Numeric and boolean columns never match the non-scalar values, so only
+	// the timestamp column needs to be checked
+	p.resolveSchema()
+	for i, column := range p.schema.columns {
+		if column.typ == "timestamp" && i < len(values) && matchesTimestamp(p.schema.timestampFormat, values[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// changeHeader replaces the columns by the given header row. The inferred
+// types of columns with the same name are kept.
+func (p *Parser) changeHeader(record []string) {
+	names := make([]string, 0, len(record)-p.SkipColumns)
+	for _, name := range record[p.SkipColumns:] {
+		if p.TrimSpace {
+			name = strings.Trim(name, " ")
+		}
+		names = append(names, name)
+	}
+	if slices.Equal(names, p.ColumnNames) {
+		return
+	}
+
+	p.Log.Debugf("Header changed from %v to %v", p.ColumnNames, names)
+	p.ColumnNames = names
+	p.headerChanges++
+	p.resolved = false
+}
+
+// resolveSchema determines the column types and the timestamp column from
+// the sampled values
+func (p *Parser) resolveSchema() {
+	if p.resolved {
+		return
+	}
+	p.resolved = true
+
+	p.schema = schema{
+		columns:         make([]columnSchema, 0, len(p.ColumnNames)),
+		timestampColumn: p.TimestampColumn,
+		timestampFormat: p.TimestampFormat,
+	}
+	for _, name := range p.ColumnNames {
+		c := p.column(name)
+		typ := c.kind()
+		tag := p.InferTags && typ == "string" && name != p.MeasurementColumn && c.repeating()
+		for _, tagName := range p.TagColumns {
+			tag = tag || tagName == name
+		}
+		if tag {
+			typ = "string"
+		}
+
+		// Use the first timestamp column unless specified explicitly
+		if typ == "timestamp" {
+			switch {
+			case p.schema.timestampColumn == "":
+				p.schema.timestampColumn = name
+				p.schema.timestampFormat = c.formats[0]
+			case p.schema.timestampColumn == name:
+				if p.schema.timestampFormat == "" {
+					p.schema.timestampFormat = c.formats[0]
+				}
+			default:
+				typ = "string"
+			}
+		}
+		p.schema.columns = append(p.schema.columns, columnSchema{typ: typ, tag: tag})
+	}
+}
+
+// Schema returns the inferred schema together with the options used for
+// reading the data as parser options which can be used to pin the schema in
+// the configuration.
+func (p *Parser) Schema() string {
+	p.Lock()
+	defer p.Unlock()
+
+	p.resolveSchema()
+
+	var rows int
+	types := make([]string, 0, len(p.ColumnNames))
+	var tags []string
+	for i, name := range p.ColumnNames {
+		rows = max(rows, p.column(name).rows)
+		column := p.schema.columns[i]
+		switch {
+		case column.tag:
+			tags = append(tags, name)
+			types = append(types, "string")
+		case column.typ == "" || column.typ == "timestamp":
+			types = append(types, "string")
+		default:
+			types = append(types, column.typ)
+		}
+	}
+
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "## Schema inferred from %d sampled rows\n", rows)
+	if p.headerChanges > 0 {
+		fmt.Fprintf(&buf, "## The header changed %d time(s), the schema reflects the last header\n", p.headerChanges)
+	}
+	fmt.Fprintf(&buf, "csv_header_row_count = %d\n", p.HeaderRowCount)
+	if p.SkipRows > 0 {
+		fmt.Fprintf(&buf, "csv_skip_rows = %d\n", p.SkipRows)
+	}
+	if p.SkipColumns > 0 {
+		fmt.Fprintf(&buf, "csv_skip_columns = %d\n", p.SkipColumns)
+	}
+	if p.Delimiter != "" {
+		fmt.Fprintf(&buf, "csv_delimiter = %q\n", p.Delimiter)
+	}
+	if p.Comment != "" {
+		fmt.Fprintf(&buf, "csv_comment = %q\n", p.Comment)
+	}
+	if p.TrimSpace {
+		buf.WriteString("csv_trim_space = true\n")
+	}
+	if len(p.SkipValues) > 0 {
+		fmt.Fprintf(&buf, "csv_skip_values = %s\n", quoteList(p.SkipValues))
+	}
+	fmt.Fprintf(&buf, "csv_column_names = %s\n", quoteList(p.ColumnNames))
+	fmt.Fprintf(&buf, "csv_column_types = %s\n", quoteList(types))
+	if len(tags) > 0 {
+		fmt.Fprintf(&buf, "csv_tag_columns = %s\n", quoteList(tags))
+	}
+	if p.schema.timestampColumn != "" {
+		fmt.Fprintf(&buf, "csv_timestamp_column = %q\n", p.schema.timestampColumn)
+		fmt.Fprintf(&buf, "csv_timestamp_format = %q\n", p.schema.timestampFormat)
+	}
+	return buf.String()
+}
+
+func quoteList(values []string) string {
+	quoted := make([]string, 0, len(values))
+	for _, v := range values {
+		quoted = append(quoted, strconv.Quote(v))
+	}
+	return "[" + strings.Join(quoted, ", ") + "]"
+}
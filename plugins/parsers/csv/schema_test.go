@@ -0,0 +1,385 @@
+package csv
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInferSchema(t *testing.T) {
+	data := `time,host,usage,count,active,comment
+2024-03-01T10:00:00Z,server01,1,10,true,first
+2024-03-01T10:01:00Z,server01,1.5,20,false,
+2024-03-01T10:02:00Z,server02,2.25,30,true,third
+2024-03-01T10:03:00Z,server02,0,40,false,fourth
+`
+	expected := []telegraf.Metric{
+		metric.New("csv",
+			map[string]string{"host": "server01"},
+			map[string]interface{}{"usage": float64(1), "count": int64(10), "active": true, "comment": "first"},
+			time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC),
+		),
+		metric.New("csv",
+			map[string]string{"host": "server01"},
+			map[string]interface{}{"usage": 1.5, "count": int64(20), "active": false, "comment": ""},
+			time.Date(2024, 3, 1, 10, 1, 0, 0, time.UTC),
+		),
+		metric.New("csv",
+			map[string]string{"host": "server02"},
+			map[string]interface{}{"usage": 2.25, "count": int64(30), "active": true, "comment": "third"},
+			time.Date(2024, 3, 1, 10, 2, 0, 0, time.UTC),
+		),
+		metric.New("csv",
+			map[string]string{"host": "server02"},
+			map[string]interface{}{"usage": float64(0), "count": int64(40), "active": false, "comment": "fourth"},
+			time.Date(2024, 3, 1, 10, 3, 0, 0, time.UTC),
+		),
+	}
+
+	p := &Parser{
+		MetricName:     "csv",
+		HeaderRowCount: 1,
+		InferSchema:    true,
+		InferTags:      true,
+		Log:            testutil.Logger{},
+	}
+	require.NoError(t, p.Init())
+
+	actual, err := p.Parse([]byte(data))
+	require.NoError(t, err)
+	testutil.RequireMetricsEqual(t, expected, actual)
+
+	expectedSchema := `## Schema inferred from 4 sampled rows
+csv_header_row_count = 1
+csv_column_names = ["time", "host", "usage", "count", "active", "comment"]
+csv_column_types = ["string", "string", "float", "int", "bool", "string"]
+csv_tag_columns = ["host"]
+csv_timestamp_column = "time"
+csv_timestamp_format = "2006-01-02T15:04:05Z07:00"
+`
+	require.Equal(t, expectedSchema, p.Schema())
+}
+
+func TestInferSchemaTimestampFormats(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     string
+		column   string
+		format   string
+		expected time.Time
+	}{
+		{
+			name:     "unix seconds",
+			data:     "ts,value\n1700000000,1\n1700000060,2\n",
+			column:   "ts",
+			format:   "unix",
+			expected: time.Unix(1700000000, 0),
+		},
+		{
+			name:     "unix milliseconds",
+			data:     "created_at,value\n1700000000123,1\n1700000060000,2\n",
+			column:   "created_at",
+			format:   "unix_ms",
+			expected: time.UnixMilli(1700000000123),
+		},
+		{
+			name:     "date and time",
+			data:     "value,date\n1,2023-11-14 22:13:20\n2,2023-11-14 22:14:20.5\n",
+			column:   "date",
+			format:   "2006-01-02 15:04:05",
+			expected: time.Date(2023, 11, 14, 22, 13, 20, 0, time.UTC),
+		},
+		{
+			name:     "day first",
+			data:     "when,value\n14/11/2023 22:13:20,1\n01/12/2023 22:14:20,2\n",
+			column:   "when",
+			format:   "02/01/2006 15:04:05",
+			expected: time.Date(2023, 11, 14, 22, 13, 20, 0, time.UTC),
+		},
+		{
+			name:   "no timestamp column for numbers",
+			data:   "count,value\n1700000000,1\n1700000060,2\n",
+			column: "",
+			format: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &Parser{
+				HeaderRowCount: 1,
+				InferSchema:    true,
+				Log:            testutil.Logger{},
+			}
+			p.SetTimeFunc(func() time.Time { return time.Unix(0, 0) })
+			require.NoError(t, p.Init())
+
+			metrics, err := p.Parse([]byte(tt.data))
+			require.NoError(t, err)
+			require.Len(t, metrics, 2)
+			require.Equal(t, tt.column, p.schema.timestampColumn)
+			require.Equal(t, tt.format, p.schema.timestampFormat)
+			if tt.column != "" {
+				require.Equal(t, tt.expected.UTC(), metrics[0].Time().UTC())
+				require.NotContains(t, metrics[0].Fields(), tt.column)
+			}
+		})
+	}
+}
+
+func TestInferSchemaHeaderDrift(t *testing.T) {
+	data := `time,host,value
+2024-03-01T10:00:00Z,server01,1
+2024-03-01T10:01:00Z,server01,2
+time,host,value,errors
+2024-03-01T10:02:00Z,server01,3,0
+2024-03-01T10:03:00Z,server01,4,1
+time,host,value,errors
+2024-03-01T10:04:00Z,server01,5,0
+time,value
+2024-03-01T10:05:00Z,6.5
+`
+	expected := []telegraf.Metric{
+		metric.New("csv",
+			map[string]string{},
+			map[string]interface{}{"host": "server01", "value": int64(1)},
+			time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC),
+		),
+		metric.New("csv",
+			map[string]string{},
+			map[string]interface{}{"host": "server01", "value": int64(2)},
+			time.Date(2024, 3, 1, 10, 1, 0, 0, time.UTC),
+		),
+		metric.New("csv",
+			map[string]string{},
+			map[string]interface{}{"host": "server01", "value": int64(3), "errors": int64(0)},
+			time.Date(2024, 3, 1, 10, 2, 0, 0, time.UTC),
+		),
+		metric.New("csv",
+			map[string]string{},
+			map[string]interface{}{"host": "server01", "value": int64(4), "errors": int64(1)},
+			time.Date(2024, 3, 1, 10, 3, 0, 0, time.UTC),
+		),
+		metric.New("csv",
+			map[string]string{},
+			map[string]interface{}{"host": "server01", "value": int64(5), "errors": int64(0)},
+			time.Date(2024, 3, 1, 10, 4, 0, 0, time.UTC),
+		),
+		metric.New("csv",
+			map[string]string{},
+			map[string]interface{}{"value": 6.5},
+			time.Date(2024, 3, 1, 10, 5, 0, 0, time.UTC),
+		),
+	}
+
+	p := &Parser{
+		MetricName:     "csv",
+		HeaderRowCount: 1,
+		InferSchema:    true,
+		Log:            testutil.Logger{},
+	}
+	require.NoError(t, p.Init())
+
+	actual, err := p.Parse([]byte(data))
+	require.NoError(t, err)
+	testutil.RequireMetricsEqual(t, expected, actual)
+	require.Equal(t, []string{"time", "value"}, p.ColumnNames)
+	require.Equal(t, 2, p.headerChanges)
+	require.Contains(t, p.Schema(), "## The header changed 2 time(s), the schema reflects the last header\n")
+}
+
+func TestInferSchemaPlaceholders(t *testing.T) {
+	data := `time;host;value
+2024-03-01T10:00:00Z;server01;1.5
+2024-03-01T10:01:00Z;server02;N/A
+2024-03-01T10:02:00Z;server03;-
+2024-03-01T10:03:00Z;server04;2
+`
+	// Placeholders must not be mistaken for a header
+	p := &Parser{
+		HeaderRowCount: 1,
+		Delimiter:      ";",
+		InferSchema:    true,
+		InferRows:      1,
+		Log:            testutil.Logger{},
+	}
+	require.NoError(t, p.Init())
+
+	_, err := p.Parse([]byte(data))
+	require.ErrorContains(t, err, `column "value": column type: parse float error`)
+	require.Equal(t, []string{"time", "host", "value"}, p.ColumnNames)
+	require.Equal(t, "time", p.schema.timestampColumn)
+	require.Zero(t, p.headerChanges)
+
+	// Without a timestamp column the placeholders are treated as data as well
+	p = &Parser{
+		HeaderRowCount: 1,
+		Delimiter:      ";",
+		InferSchema:    true,
+		InferRows:      1,
+		SkipErrors:     true,
+		Log:            testutil.Logger{},
+	}
+	require.NoError(t, p.Init())
+
+	metrics, err := p.Parse([]byte("host;value\nserver01;1.5\nserver02;N/A\nserver03;2\n"))
+	require.NoError(t, err)
+	require.Len(t, metrics, 2)
+	require.Equal(t, []string{"host", "value"}, p.ColumnNames)
+	require.Zero(t, p.headerChanges)
+
+	// Skipped values are omitted
+	p = &Parser{
+		HeaderRowCount: 1,
+		Delimiter:      ";",
+		SkipValues:     []string{"N/A", "-"},
+		InferSchema:    true,
+		Log:            testutil.Logger{},
+	}
+	require.NoError(t, p.Init())
+
+	metrics, err = p.Parse([]byte(data))
+	require.NoError(t, err)
+	require.Len(t, metrics, 4)
+	require.Equal(t, map[string]interface{}{"host": "server01", "value": 1.5}, metrics[0].Fields())
+	require.Equal(t, map[string]interface{}{"host": "server02"}, metrics[1].Fields())
+	require.Equal(t, map[string]interface{}{"host": "server03"}, metrics[2].Fields())
+	require.Equal(t, map[string]interface{}{"host": "server04", "value": float64(2)}, metrics[3].Fields())
+
+	expected := `## Schema inferred from 4 sampled rows
+csv_header_row_count = 1
+csv_delimiter = ";"
+csv_skip_values = ["N/A", "-"]
+csv_column_names = ["time", "host", "value"]
+csv_column_types = ["string", "string", "float"]
+csv_timestamp_column = "time"
+csv_timestamp_format = "2006-01-02T15:04:05Z07:00"
+`
+	require.Equal(t, expected, p.Schema())
+}
+
+func TestInferSchemaLinewise(t *testing.T) {
+	p := &Parser{
+		MetricName:     "csv",
+		HeaderRowCount: 1,
+		InferSchema:    true,
+		InferRows:      2,
+		Log:            testutil.Logger{},
+	}
+	p.SetTimeFunc(func() time.Time { return time.Unix(0, 0) })
+	require.NoError(t, p.Init())
+
+	m, err := p.ParseLine("name,value")
+	require.NoError(t, err)
+	require.Nil(t, m)
+
+	// Types are inferred from the rows seen so far
+	m, err = p.ParseLine("a,1")
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{"name": "a", "value": int64(1)}, m.Fields())
+
+	m, err = p.ParseLine("b,1.5")
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{"name": "b", "value": 1.5}, m.Fields())
+
+	// The schema is fixed after sampling the given number of rows
+	m, err = p.ParseLine("c,2")
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{"name": "c", "value": float64(2)}, m.Fields())
+
+	_, err = p.ParseLine("d,true")
+	require.ErrorContains(t, err, `column "value": column type: parse float error`)
+
+	// A new header changes the columns
+	m, err = p.ParseLine("name,value,state")
+	require.NoError(t, err)
+	require.Nil(t, m)
+
+	m, err = p.ParseLine("e,3,ok")
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{"name": "e", "value": float64(3), "state": "ok"}, m.Fields())
+}
+
+func TestInferSchemaMissingValues(t *testing.T) {
+	data := "name,value\na,1\nb,\nc,3\n"
+
+	p := &Parser{
+		HeaderRowCount: 1,
+		InferSchema:    true,
+		Log:            testutil.Logger{},
+	}
+	require.NoError(t, p.Init())
+
+	metrics, err := p.Parse([]byte(data))
+	require.NoError(t, err)
+	require.Len(t, metrics, 3)
+	require.Equal(t, map[string]interface{}{"name": "a", "value": int64(1)}, metrics[0].Fields())
+	require.Equal(t, map[string]interface{}{"name": "b"}, metrics[1].Fields())
+	require.Equal(t, map[string]interface{}{"name": "c", "value": int64(3)}, metrics[2].Fields())
+}
+
+func TestInferSchemaReset(t *testing.T) {
+	p := &Parser{
+		HeaderRowCount: 1,
+		InferSchema:    true,
+		ResetMode:      "always",
+		Log:            testutil.Logger{},
+	}
+	require.NoError(t, p.Init())
+
+	metrics, err := p.Parse([]byte("value\n1\n2\n"))
+	require.NoError(t, err)
+	require.Len(t, metrics, 2)
+	require.Equal(t, int64(1), metrics[0].Fields()["value"])
+
+	// Each call infers its own schema
+	metrics, err = p.Parse([]byte("value\nfoo\nbar\n"))
+	require.NoError(t, err)
+	require.Len(t, metrics, 2)
+	require.Equal(t, "foo", metrics[0].Fields()["value"])
+}
+
+func TestInferSchemaInitErrors(t *testing.T) {
+	p := &Parser{
+		HeaderRowCount: 1,
+		InferSchema:    true,
+		ColumnTypes:    []string{"int"},
+	}
+	require.ErrorContains(t, p.Init(), "csv_column_types cannot be used together with csv_infer_schema")
+
+	p = &Parser{
+		HeaderRowCount: 1,
+		InferSchema:    true,
+		InferRows:      -1,
+	}
+	require.ErrorContains(t, p.Init(), "invalid csv_infer_rows -1")
+}
+
+func BenchmarkInferSchema(b *testing.B) {
+	var data strings.Builder
+	data.WriteString("time,host,usage,count,active\n")
+	for i := range 1000 {
+		ts := time.Unix(1700000000+int64(i), 0).UTC().Format(time.RFC3339)
+		data.WriteString(ts + ",server01,1.5,42,true\n")
+	}
+	buf := []byte(data.String())
+
+	p := &Parser{
+		HeaderRowCount: 1,
+		InferSchema:    true,
+		ResetMode:      "always",
+		Log:            testutil.Logger{},
+	}
+	require.NoError(b, p.Init())
+
+	for n := 0; n < b.N; n++ {
+		//nolint:errcheck // Benchmarking so skip the error check to avoid the unnecessary operations
+		p.Parse(buf)
+	}
+}
@@ -0,0 +1,87 @@
+package logformat
+
+import (
+	"fmt"
+	"time"
+)
+
+// combinedDecoder decodes the combined log format used by Apache httpd and
+// nginx, e.g.
+//
+//	127.0.0.1 - frank [10/Oct/2000:13:55:36 -0700] "GET /apache_pb.gif HTTP/1.0" 200 2326 "http://example.com/" "Mozilla/4.08"
+//
+// The referrer and agent are optional, so the common log format is supported
+// as well. Additional trailing elements are ignored.
+type combinedDecoder struct{}
+
+func (*combinedDecoder) records(data []byte) [][]byte {
+	return splitLines(data)
+}
+
+func (*combinedDecoder) decode(record []byte) (*entry, error) {
+	s := &scanner{line: string(record)}
+	e := newEntry()
+
+	clientIP, err := s.token()
+	if err != nil {
+		return nil, err
+	}
+	setString(e, "client_ip", clientIP)
+
+	ident, err := s.token()
+	if err != nil {
+		return nil, err
+	}
+	setString(e, "ident", ident)
+
+	auth, err := s.token()
+	if err != nil {
+		return nil, err
+	}
+	setString(e, "auth", auth)
+
+	ts, err := s.enclosed('[', ']')
+	if err != nil {
+		return nil, err
+	}
+	if e.timestamp, err = time.Parse("02/Jan/2006:15:04:05 -0700", ts); err != nil {
+		return nil, fmt.Errorf("invalid timestamp: %w", err)
+	}
+
+	request, err := s.quoted()
+	if err != nil {
+		return nil, err
+	}
+	setRequest(e, "verb", "request", request)
+
+	status, err := s.token()
+	if err != nil {
+		return nil, err
+	}
+	setTag(e, "resp_code", status)
+
+	size, err := s.token()
+	if err != nil {
+		return nil, err
+	}
+	if err := setInt(e, "resp_bytes", size); err != nil {
+		return nil, err
+	}
+
+	if s.peek() != '"' {
+		return e, nil
+	}
+	referrer, err := s.quoted()
+	if err != nil {
+		return nil, err
+	}
+	setString(e, "referrer", referrer)
+
+	agent, err := s.quoted()
+	if err != nil {
+		return nil, err
+	}
+	setString(e, "agent", agent)
+
+	return e, nil
+}
@@ -0,0 +1,175 @@
+package logformat
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// haproxyDecoder decodes the HAProxy HTTP log format (option httplog), e.g.
+//
+//	10.0.1.2:33317 [06/Feb/2009:12:14:14.655] http-in static/srv1 10/0/30/69/109 200 2750 - - ---- 1/1/1/1/0 0/0 {1wt.eu} {} "GET /index.html HTTP/1.1"
+//
+// and the TCP log format (option tcplog), e.g.
+//
+//	10.0.1.2:33313 [06/Feb/2009:12:12:51.443] fnt bck/srv1 0/0/5007 212 -- 0/0/0/0/3 0/0
+//
+// optionally prefixed by a syslog header.
+type haproxyDecoder struct {
+	http bool
+	loc  *time.Location
+}
+
+var (
+	haproxyHTTPTimers = []string{"time_request", "time_queue", "time_backend_connect", "time_backend_response", "time_duration"}
+	haproxyTCPTimers  = []string{"time_queue", "time_backend_connect", "time_duration"}
+	haproxyConns      = []string{"actconn", "feconn", "beconn", "srvconn", "retries"}
+	haproxyQueues     = []string{"srv_queue", "backend_queue"}
+)
+
+func (*haproxyDecoder) records(data []byte) [][]byte {
+	return splitLines(data)
+}
+
+func (d *haproxyDecoder) decode(record []byte) (*entry, error) {
+	line := string(record)
+
+	// The client address is the last element before the bracketed accept
+	// date, everything before belongs to the syslog header if any
+	idx := strings.Index(line, " [")
+	if idx < 0 {
+		return nil, errUnexpectedEnd
+	}
+	client := line[:idx]
+	if i := strings.LastIndex(client, ": "); i >= 0 {
+		client = client[i+2:]
+	}
+	s := &scanner{line: line, pos: idx}
+	e := newEntry()
+
+	i := strings.LastIndexByte(client, ':')
+	if i < 0 {
+		return nil, fmt.Errorf("invalid client address %q", client)
+	}
+	setString(e, "client_ip", client[:i])
+	if err := setInt(e, "client_port", client[i+1:]); err != nil {
+		return nil, err
+	}
+
+	ts, err := s.enclosed('[', ']')
+	if err != nil {
+		return nil, err
+	}
+	if e.timestamp, err = time.ParseInLocation("02/Jan/2006:15:04:05.000", ts, d.loc); err != nil {
+		return nil, fmt.Errorf("invalid accept date: %w", err)
+	}
+
+	frontend, err := s.token()
+	if err != nil {
+		return nil, err
+	}
+	setTag(e, "frontend_name", frontend)
+
+	server, err := s.token()
+	if err != nil {
+		return nil, err
+	}
+	parts, err := split(server, '/', 2)
+	if err != nil {
+		return nil, err
+	}
+	setTag(e, "backend_name", parts[0])
+	setTag(e, "server_name", parts[1])
+
+	timers := haproxyTCPTimers
+	if d.http {
+		timers = haproxyHTTPTimers
+	}
+	if err := d.ints(s, e, timers); err != nil {
+		return nil, err
+	}
+
+	if d.http {
+		status, err := s.token()
+		if err != nil {
+			return nil, err
+		}
+		setTag(e, "http_status_code", status)
+	}
+
+	bytesRead, err := s.token()
+	if err != nil {
+		return nil, err
+	}
+	if err := setInt(e, "bytes_read", bytesRead); err != nil {
+		return nil, err
+	}
+
+	if d.http {
+		for _, key := range []string{"captured_request_cookie", "captured_response_cookie"} {
+			cookie, err := s.token()
+			if err != nil {
+				return nil, err
+			}
+			setString(e, key, cookie)
+		}
+	}
+
+	state, err := s.token()
+	if err != nil {
+		return nil, err
+	}
+	setString(e, "termination_state", state)
+
+	if err := d.ints(s, e, haproxyConns); err != nil {
+		return nil, err
+	}
+	if err := d.ints(s, e, haproxyQueues); err != nil {
+		return nil, err
+	}
+
+	if !d.http {
+		return e, nil
+	}
+
+	// Captured headers are optional. If only one block is present, it is
+	// assumed to contain the request headers.
+	for _, key := range []string{"captured_request_headers", "captured_response_headers"} {
+		if s.peek() != '{' {
+			break
+		}
+		headers, err := s.enclosed('{', '}')
+		if err != nil {
+			return nil, err
+		}
+		setString(e, key, headers)
+	}
+
+	request, err := s.quoted()
+	if err != nil {
+		return nil, err
+	}
+	if request != "<BADREQ>" {
+		setRequest(e, "http_verb", "http_request", request)
+	}
+
+	return e, nil
+}
+
+// ints decodes a slash-separated list of integers like "1/1/1/1/0"
+func (*haproxyDecoder) ints(s *scanner, e *entry, keys []string) error {
+	token, err := s.token()
+	if err != nil {
+		return err
+	}
+	values, err := split(token, '/', len(keys))
+	if err != nil {
+		return err
+	}
+	for i, key := range keys {
+		if err := setInt(e, key, values[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
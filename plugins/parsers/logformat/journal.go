@@ -0,0 +1,179 @@
+package logformat
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+var journalSeverities = []string{"emerg", "alert", "crit", "err", "warning", "notice", "info", "debug"}
+
+var journalFacilities = []string{
+	"kern", "user", "mail", "daemon", "auth", "syslog", "lpr", "news",
+	"uucp", "cron", "authpriv", "ftp", "ntp", "security", "console", "solaris-cron",
+	"local0", "local1", "local2", "local3", "local4", "local5", "local6", "local7",
+}
+
+// journalTags maps journal fields to tags
+var journalTags = map[string]string{
+	"_HOSTNAME":         "hostname",
+	"SYSLOG_IDENTIFIER": "appname",
+	"_SYSTEMD_UNIT":     "unit",
+	"_TRANSPORT":        "transport",
+}
+
+// journalInts lists the journal fields containing integer values
+var journalInts = map[string]bool{
+	"__MONOTONIC_TIMESTAMP":      true,
+	"_SOURCE_REALTIME_TIMESTAMP": true,
+	"_PID":                       true,
+	"_UID":                       true,
+	"_GID":                       true,
+	"SYSLOG_PID":                 true,
+	"ERRNO":                      true,
+	"CODE_LINE":                  true,
+	"TID":                        true,
+}
+
+// journalDecoder decodes the systemd journal export format as produced by
+// "journalctl -o export". Entries are separated by an empty line. Each field
+// is either written as "KEY=value" line or, for binary values and values
+// containing line breaks, as key line followed by the little-endian 64-bit
+// size and the raw data.
+type journalDecoder struct{}
+
+type journalField struct {
+	key   string
+	value []byte
+}
+
+func (*journalDecoder) records(data []byte) [][]byte {
+	var records [][]byte
+	start := 0
+	pos := 0
+	for pos < len(data) {
+		end := bytes.IndexByte(data[pos:], '\n')
+		if end < 0 {
+			break
+		}
+		end += pos
+		line := data[pos:end]
+
+		switch {
+		case len(line) == 0:
+			if pos > start {
+				records = append(records, data[start:pos])
+			}
+			start = end + 1
+		case bytes.IndexByte(line, '=') < 0:
+			// Skip the binary data of the field
+			if end+9 > len(data) {
+				return append(records, data[start:])
+			}
+			size := binary.LittleEndian.Uint64(data[end+1 : end+9])
+			if size > uint64(len(data)-end-9) {
+				return append(records, data[start:])
+			}
+			end += 8 + int(size) + 1
+		}
+		pos = end + 1
+	}
+	if start < len(data) && len(bytes.TrimSpace(data[start:])) > 0 {
+		records = append(records, data[start:])
+	}
+	return records
+}
+
+func (d *journalDecoder) decode(record []byte) (*entry, error) {
+	fields, err := d.fields(record)
+	if err != nil {
+		return nil, err
+	}
+
+	e := newEntry()
+	for _, f := range fields {
+		value := string(f.value)
+		if f.key == "__REALTIME_TIMESTAMP" {
+			us, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid realtime timestamp: %w", err)
+			}
+			e.timestamp = time.UnixMicro(us)
+			continue
+		}
+		if tag, found := journalTags[f.key]; found {
+			setTag(e, tag, value)
+			continue
+		}
+
+		switch f.key {
+		case "PRIORITY":
+			if p, err := strconv.Atoi(value); err == nil && p >= 0 && p < len(journalSeverities) {
+				e.tags["severity"] = journalSeverities[p]
+			}
+			continue
+		case "SYSLOG_FACILITY":
+			if p, err := strconv.Atoi(value); err == nil && p >= 0 && p < len(journalFacilities) {
+				e.tags["facility"] = journalFacilities[p]
+			}
+			continue
+		}
+
+		// Binary data not representable as string is dropped
+		if !utf8.Valid(f.value) {
+			continue
+		}
+		key := strings.ToLower(strings.TrimLeft(f.key, "_"))
+		if journalInts[f.key] {
+			if err := setInt(e, key, value); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		setString(e, key, value)
+	}
+	if e.timestamp.IsZero() {
+		return nil, errors.New("missing realtime timestamp")
+	}
+	return e, nil
+}
+
+// fields returns the fields of a journal entry
+func (*journalDecoder) fields(record []byte) ([]journalField, error) {
+	var fields []journalField
+	for len(record) > 0 {
+		end := bytes.IndexByte(record, '\n')
+		if end < 0 {
+			end = len(record)
+		}
+		line := record[:end]
+		if len(line) == 0 {
+			record = record[min(end+1, len(record)):]
+			continue
+		}
+
+		if key, value, found := bytes.Cut(line, []byte{'='}); found {
+			fields = append(fields, journalField{key: string(key), value: value})
+			record = record[min(end+1, len(record)):]
+			continue
+		}
+
+		// Binary field
+		if end+9 > len(record) {
+			return nil, fmt.Errorf("missing size of binary field %q", line)
+		}
+		size := binary.LittleEndian.Uint64(record[end+1 : end+9])
+		if size > uint64(len(record)-end-9) {
+			return nil, fmt.Errorf("truncated binary field %q", line)
+		}
+		start := end + 9
+		fields = append(fields, journalField{key: string(line), value: record[start : start+int(size)]})
+		record = record[min(start+int(size)+1, len(record)):]
+	}
+	return fields, nil
+}
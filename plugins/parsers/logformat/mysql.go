@@ -0,0 +1,179 @@
+package logformat
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// mysqlSlowlogDecoder decodes the multi-line entries of the MySQL and
+// MariaDB slow query log, e.g.
+//
+//	# Time: 2023-11-14T22:13:20.123456Z
+//	# User@Host: app[app] @ localhost [127.0.0.1]  Id:     8
+//	# Query_time: 2.000143  Lock_time: 0.000003 Rows_sent: 1  Rows_examined: 0
+//	use shop;
+//	SET timestamp=1700000000;
+//	SELECT SLEEP(2);
+type mysqlSlowlogDecoder struct {
+	loc *time.Location
+}
+
+// records splits the data into entries. An entry starts with the "# Time"
+// or "# User@Host" header and ends before the next header following the
+// statement. Lines preceding the first entry, e.g. the server startup
+// banner, are dropped.
+func (*mysqlSlowlogDecoder) records(data []byte) [][]byte {
+	var records [][]byte
+	start := -1
+	var user, statement bool
+	for pos := 0; pos < len(data); {
+		end := bytes.IndexByte(data[pos:], '\n')
+		if end < 0 {
+			end = len(data)
+		} else {
+			end += pos
+		}
+		line := data[pos:end]
+
+		var begin bool
+		switch {
+		case bytes.HasPrefix(line, []byte("# Time:")):
+			begin = start < 0 || user || statement
+		case bytes.HasPrefix(line, []byte("# User@Host:")):
+			begin = start < 0 || user || statement
+			user = true
+		case bytes.HasPrefix(line, []byte("# ")) && !bytes.HasPrefix(line, []byte("# administrator command:")):
+			begin = statement
+		case start >= 0 && len(bytes.TrimSpace(line)) > 0:
+			statement = true
+		}
+		if begin {
+			if start >= 0 {
+				records = append(records, data[start:pos])
+			}
+			start = pos
+			user = bytes.HasPrefix(line, []byte("# User@Host:"))
+			statement = false
+		}
+		pos = end + 1
+	}
+	if start >= 0 {
+		records = append(records, data[start:])
+	}
+	return records
+}
+
+func (d *mysqlSlowlogDecoder) decode(record []byte) (*entry, error) {
+	e := newEntry()
+	var statement []string
+	var header bool
+	for _, line := range strings.Split(string(record), "\n") {
+		line = strings.TrimSuffix(line, "\r")
+		switch {
+		case strings.HasPrefix(line, "# Time:"):
+			ts, err := d.parseTime(strings.TrimSpace(line[len("# Time:"):]))
+			if err != nil {
+				return nil, err
+			}
+			e.timestamp = ts
+		case strings.HasPrefix(line, "# User@Host:"):
+			if err := d.decodeUser(e, line[len("# User@Host:"):]); err != nil {
+				return nil, err
+			}
+			header = true
+		case strings.HasPrefix(line, "# ") && !strings.HasPrefix(line, "# administrator command:"):
+			d.decodeAttributes(e, line[2:])
+			header = true
+		case strings.HasPrefix(line, "use ") && strings.HasSuffix(line, ";") && len(statement) == 0:
+			setTag(e, "schema", strings.TrimSuffix(line[len("use "):], ";"))
+		case strings.HasPrefix(line, "SET timestamp=") && strings.HasSuffix(line, ";"):
+			// Only use the statement timestamp if there is no time header
+			if e.timestamp.IsZero() {
+				sec, err := strconv.ParseInt(strings.TrimSuffix(line[len("SET timestamp="):], ";"), 10, 64)
+				if err != nil {
+					return nil, fmt.Errorf("invalid statement timestamp: %w", err)
+				}
+				e.timestamp = time.Unix(sec, 0)
+			}
+		case len(strings.TrimSpace(line)) > 0 && !isMySQLBanner(line):
+			statement = append(statement, line)
+		}
+	}
+	if !header {
+		return nil, errors.New("missing entry header")
+	}
+	setString(e, "query", strings.Join(statement, "\n"))
+
+	return e, nil
+}
+
+// parseTime parses the time header of MySQL 5.7 and later, e.g.
+// "2023-11-14T22:13:20.123456Z", and of older versions, e.g.
+// "231114 22:13:20" with the hour padded by a space.
+func (d *mysqlSlowlogDecoder) parseTime(value string) (time.Time, error) {
+	if ts, err := time.Parse(time.RFC3339Nano, value); err == nil {
+		return ts, nil
+	}
+	ts, err := time.ParseInLocation("060102 15:04:05", strings.Join(strings.Fields(value), " "), d.loc)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time %q", value)
+	}
+	return ts, nil
+}
+
+// decodeUser decodes the user header, e.g.
+// "app[app] @ localhost [127.0.0.1]  Id:     8"
+func (*mysqlSlowlogDecoder) decodeUser(e *entry, line string) error {
+	account, rest, found := strings.Cut(line, "@")
+	if !found {
+		return fmt.Errorf("invalid user header %q", line)
+	}
+	user, _, _ := strings.Cut(strings.TrimSpace(account), "[")
+	setTag(e, "user", user)
+
+	rest, id, _ := strings.Cut(rest, "Id:")
+	host, ip, _ := strings.Cut(strings.TrimSpace(rest), "[")
+	setString(e, "client_host", strings.TrimSpace(host))
+	setString(e, "client_ip", strings.TrimSuffix(strings.TrimSpace(ip), "]"))
+	return setInt(e, "connection_id", strings.TrimSpace(id))
+}
+
+// decodeAttributes decodes a header line of "Key: value" pairs, e.g.
+// "Query_time: 2.000143  Lock_time: 0.000003 Rows_sent: 1  Rows_examined: 0".
+// Keys are converted to lowercase and values to numbers or booleans if
+// possible. The schema is added as tag.
+func (*mysqlSlowlogDecoder) decodeAttributes(e *entry, line string) {
+	items := strings.Fields(line)
+	for i := 0; i+1 < len(items); i += 2 {
+		key, found := strings.CutSuffix(items[i], ":")
+		if !found {
+			return
+		}
+		key = strings.ToLower(key)
+		value := items[i+1]
+		if key == "schema" {
+			setTag(e, key, value)
+			continue
+		}
+		if v, err := strconv.ParseInt(value, 10, 64); err == nil {
+			e.fields[key] = v
+		} else if v, err := strconv.ParseFloat(value, 64); err == nil {
+			e.fields[key] = v
+		} else if value == "Yes" || value == "No" {
+			e.fields[key] = value == "Yes"
+		} else {
+			e.fields[key] = value
+		}
+	}
+}
+
+// isMySQLBanner checks for the lines written to the log at server startup
+func isMySQLBanner(line string) bool {
+	return strings.HasSuffix(line, "started with:") ||
+		strings.HasPrefix(line, "Tcp port:") ||
+		(strings.HasPrefix(line, "Time ") && strings.Contains(line, "Id Command"))
+}
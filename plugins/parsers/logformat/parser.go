@@ -0,0 +1,126 @@
+package logformat
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/plugins/parsers"
+)
+
+// decoder converts the records of a log format into entries
+type decoder interface {
+	// records splits the data into the individual, possibly multi-line,
+	// log records
+	records(data []byte) [][]byte
+
+	// decode converts a single log record
+	decode(record []byte) (*entry, error)
+}
+
+// entry holds the information decoded from a single log record
+type entry struct {
+	tags      map[string]string
+	fields    map[string]interface{}
+	timestamp time.Time
+}
+
+func newEntry() *entry {
+	return &entry{
+		tags:   make(map[string]string),
+		fields: make(map[string]interface{}),
+	}
+}
+
+type Parser struct {
+	Format      string            `toml:"logformat_format"`
+	Timezone    string            `toml:"logformat_timezone"`
+	DefaultTags map[string]string `toml:"-"`
+	Log         telegraf.Logger   `toml:"-"`
+
+	metricName string
+	decoder    decoder
+}
+
+func (p *Parser) Init() error {
+	if p.Timezone == "" {
+		p.Timezone = "UTC"
+	}
+	loc, err := time.LoadLocation(p.Timezone)
+	if err != nil {
+		return fmt.Errorf("invalid 'logformat_timezone' %q: %w", p.Timezone, err)
+	}
+
+	switch p.Format {
+	case "":
+		return errors.New("'logformat_format' is required")
+	case "combined":
+		p.decoder = &combinedDecoder{}
+	case "haproxy_http":
+		p.decoder = &haproxyDecoder{http: true, loc: loc}
+	case "haproxy_tcp":
+		p.decoder = &haproxyDecoder{loc: loc}
+	case "postgresql_csvlog":
+		p.decoder = &postgresqlCSVDecoder{loc: loc}
+	case "postgresql_jsonlog":
+		p.decoder = &postgresqlJSONDecoder{loc: loc}
+	case "mysql_slowlog":
+		p.decoder = &mysqlSlowlogDecoder{loc: loc}
+	case "journal_export":
+		p.decoder = &journalDecoder{}
+	default:
+		return fmt.Errorf("invalid 'logformat_format' %q", p.Format)
+	}
+
+	return nil
+}
+
+func (p *Parser) Parse(data []byte) ([]telegraf.Metric, error) {
+	records := p.decoder.records(data)
+	metrics := make([]telegraf.Metric, 0, len(records))
+	for _, record := range records {
+		e, err := p.decoder.decode(record)
+		if err != nil {
+			// Log files often contain unrelated lines, e.g. startup messages,
+			// so skip invalid records instead of dropping the whole data
+			p.Log.Debugf("Skipping record %q: %v", record, err)
+			continue
+		}
+		metrics = append(metrics, p.createMetric(e))
+	}
+	return metrics, nil
+}
+
+func (p *Parser) ParseLine(line string) (telegraf.Metric, error) {
+	e, err := p.decoder.decode([]byte(line))
+	if err != nil {
+		return nil, err
+	}
+	return p.createMetric(e), nil
+}
+
+func (p *Parser) SetDefaultTags(tags map[string]string) {
+	p.DefaultTags = tags
+}
+
+func (p *Parser) createMetric(e *entry) telegraf.Metric {
+	for k, v := range p.DefaultTags {
+		if _, found := e.tags[k]; !found {
+			e.tags[k] = v
+		}
+	}
+	if e.timestamp.IsZero() {
+		e.timestamp = time.Now()
+	}
+	return metric.New(p.metricName, e.tags, e.fields, e.timestamp)
+}
+
+func init() {
+	parsers.Add("logformat",
+		func(defaultMetricName string) telegraf.Parser {
+			return &Parser{metricName: defaultMetricName}
+		},
+	)
+}
@@ -0,0 +1,346 @@
+package logformat
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/plugins/parsers/grok"
+	"github.com/influxdata/telegraf/testutil"
+	test "github.com/influxdata/telegraf/testutil/plugin_input"
+)
+
+func TestCases(t *testing.T) {
+	folders, err := os.ReadDir("testcases")
+	require.NoError(t, err)
+	require.NotEmpty(t, folders)
+
+	for _, f := range folders {
+		testcasePath := filepath.Join("testcases", f.Name())
+		configFilename := filepath.Join(testcasePath, "telegraf.conf")
+		t.Run(f.Name(), func(t *testing.T) {
+			// Configure the plugin
+			cfg := config.NewConfig()
+			require.NoError(t, cfg.LoadConfig(configFilename))
+			require.Len(t, cfg.Inputs, 1)
+
+			// Tune the test-plugin
+			plugin := cfg.Inputs[0].Input.(*test.Plugin)
+			plugin.Path = testcasePath
+			require.NoError(t, plugin.Init())
+
+			var options []cmp.Option
+			if plugin.ShouldIgnoreTimestamp {
+				options = append(options, testutil.IgnoreTime())
+			}
+
+			var acc testutil.Accumulator
+			require.NoError(t, plugin.Gather(&acc))
+			testutil.RequireMetricsEqual(t, plugin.Expected, acc.GetTelegrafMetrics(), options...)
+		})
+	}
+}
+
+func TestParseLine(t *testing.T) {
+	parser := &Parser{
+		Format:     "combined",
+		Log:        testutil.Logger{},
+		metricName: "nginx",
+	}
+	require.NoError(t, parser.Init())
+	parser.SetDefaultTags(map[string]string{"source": "web01", "verb": "default"})
+
+	actual, err := parser.ParseLine(`10.0.0.1 - - [14/Nov/2023:22:13:20 +0000] "GET /healthz HTTP/1.1" 200 2 "-" "kube-probe/1.28"`)
+	require.NoError(t, err)
+
+	expected := metric.New(
+		"nginx",
+		map[string]string{"source": "web01", "verb": "GET", "resp_code": "200"},
+		map[string]interface{}{
+			"client_ip":    "10.0.0.1",
+			"request":      "/healthz",
+			"http_version": 1.1,
+			"resp_bytes":   int64(2),
+			"agent":        "kube-probe/1.28",
+		},
+		time.Unix(1700000000, 0),
+	)
+	testutil.RequireMetricEqual(t, expected, actual)
+
+	_, err = parser.ParseLine("invalid")
+	require.ErrorContains(t, err, "unexpected end of record")
+}
+
+func TestMultilineValues(t *testing.T) {
+	tests := []struct {
+		format   string
+		input    string
+		key      string
+		expected []string
+	}{
+		{
+			format: "postgresql_csvlog",
+			input: "2023-11-14 22:13:20.123 UTC,\"app\",\"shop\",4711,\"10.0.0.5:52044\",6553f0a0.1267,3,\"SELECT\",2023-11-14 22:00:00 UTC," +
+				"3/42,0,LOG,00000,\"statement: SELECT *\nFROM orders\n\nWHERE id = 1\",,,,,,,,,\"psql\"\n" +
+				"2023-11-14 22:13:21.000 UTC,,,1,,6553f000.1,1,,2023-11-14 21:59:00 UTC,,0,LOG,00000,\"checkpoint starting: time\",,,,,,,,,\"\"\n",
+			key:      "message",
+			expected: []string{"statement: SELECT *\nFROM orders\n\nWHERE id = 1", "checkpoint starting: time"},
+		},
+		{
+			format: "postgresql_jsonlog",
+			input: `{"timestamp":"2023-11-14 22:13:20.123 UTC","error_severity":"LOG","message":"statement: SELECT *\nFROM orders"}` + "\n" +
+				`{"timestamp":"2023-11-14 22:13:21.000 UTC","error_severity":"LOG","message":"checkpoint starting: time"}`,
+			key:      "message",
+			expected: []string{"statement: SELECT *\nFROM orders", "checkpoint starting: time"},
+		},
+		{
+			format: "mysql_slowlog",
+			input: "# Time: 2023-11-14T22:13:20.000000Z\n" +
+				"# User@Host: app[app] @ localhost []  Id:     8\n" +
+				"# Query_time: 2.000143  Lock_time: 0.000003 Rows_sent: 1  Rows_examined: 0\n" +
+				"SET timestamp=1700000000;\n" +
+				"SELECT customer, SUM(total)\nFROM orders\nGROUP BY customer;\n" +
+				"# User@Host: app[app] @ localhost []  Id:     8\n" +
+				"# Query_time: 1.000000  Lock_time: 0.000000 Rows_sent: 1  Rows_examined: 0\n" +
+				"SET timestamp=1700000001;\n" +
+				"SELECT 1;\n",
+			key:      "query",
+			expected: []string{"SELECT customer, SUM(total)\nFROM orders\nGROUP BY customer;", "SELECT 1;"},
+		},
+		{
+			format: "journal_export",
+			input: "__REALTIME_TIMESTAMP=1700000000000000\nMESSAGE\n" +
+				"\x21\x00\x00\x00\x00\x00\x00\x00rsync failed:\n\nconnection refused\n\n" +
+				"__REALTIME_TIMESTAMP=1700000001000000\nMESSAGE=done\n",
+			key:      "message",
+			expected: []string{"rsync failed:\n\nconnection refused", "done"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			parser := &Parser{Format: tt.format, Log: testutil.Logger{}, metricName: "test"}
+			require.NoError(t, parser.Init())
+
+			metrics, err := parser.Parse([]byte(tt.input))
+			require.NoError(t, err)
+			require.Len(t, metrics, len(tt.expected))
+			for i, m := range metrics {
+				actual, found := m.GetField(tt.key)
+				require.True(t, found)
+				require.Equal(t, tt.expected[i], actual)
+			}
+		})
+	}
+}
+
+func TestTimezone(t *testing.T) {
+	parser := &Parser{
+		Format:     "haproxy_tcp",
+		Timezone:   "Europe/Berlin",
+		Log:        testutil.Logger{},
+		metricName: "haproxy",
+	}
+	require.NoError(t, parser.Init())
+
+	actual, err := parser.ParseLine("10.0.1.2:33313 [14/Nov/2023:23:13:20.000] fnt bck/srv1 0/0/5007 212 -- 0/0/0/0/3 0/0")
+	require.NoError(t, err)
+	require.Equal(t, time.Unix(1700000000, 0).UTC(), actual.Time().UTC())
+}
+
+func TestInitErrors(t *testing.T) {
+	parser := &Parser{}
+	require.ErrorContains(t, parser.Init(), "'logformat_format' is required")
+
+	parser = &Parser{Format: "syslog"}
+	require.ErrorContains(t, parser.Init(), `invalid 'logformat_format' "syslog"`)
+
+	parser = &Parser{Format: "combined", Timezone: "Mars/Olympus_Mons"}
+	require.ErrorContains(t, parser.Init(), `invalid 'logformat_timezone' "Mars/Olympus_Mons"`)
+}
+
+// benchmarks contains a sample record for each format together with a grok
+// configuration producing the equivalent metric where possible
+var benchmarks = []struct {
+	format         string
+	input          string
+	patterns       []string
+	customPatterns string
+	multiline      bool
+}{
+	{
+		format: "combined",
+		input: `127.0.0.1 - frank [10/Oct/2000:13:55:36 -0700] "GET /apache_pb.gif HTTP/1.0" 200 2326 ` +
+			`"http://www.example.com/start.html" "Mozilla/4.08 [en] (Win98; I ;Nav)"`,
+		patterns: []string{"%{COMBINED_LOG_FORMAT}"},
+	},
+	{
+		format: "haproxy_http",
+		input: `Feb  6 12:14:14 localhost haproxy[14389]: 10.0.1.2:33317 [06/Feb/2009:12:14:14.655] http-in static/srv1 ` +
+			`10/0/30/69/109 200 2750 - - ---- 1/1/1/1/0 0/0 {1wt.eu} {} "GET /index.html HTTP/1.1"`,
+		patterns: []string{
+			`%{HAPROXY_CLIENT} %{HAPROXY_SERVER} %{INT:time_request:int}/%{INT:time_queue:int}/%{INT:time_backend_connect:int}/` +
+				`%{INT:time_backend_response:int}/\+?%{INT:time_duration:int} %{INT:http_status_code:tag} \+?%{INT:bytes_read:int} ` +
+				`%{NOTSPACE:captured_request_cookie} %{NOTSPACE:captured_response_cookie} %{HAPROXY_STATE} ` +
+				`(?:\{%{DATA:captured_request_headers}\} )?(?:\{%{DATA:captured_response_headers}\} )?` +
+				`"%{WORD:http_verb:tag} %{NOTSPACE:http_request}(?: HTTP/%{NUMBER:http_version:float})?"`,
+		},
+		customPatterns: haproxyPatterns,
+	},
+	{
+		format: "haproxy_tcp",
+		input:  `Feb  6 12:12:56 localhost haproxy[14387]: 10.0.1.2:33313 [06/Feb/2009:12:12:51.443] fnt bck/srv1 0/0/5007 212 -- 0/0/0/0/3 0/0`,
+		patterns: []string{
+			`%{HAPROXY_CLIENT} %{HAPROXY_SERVER} %{INT:time_queue:int}/%{INT:time_backend_connect:int}/\+?%{INT:time_duration:int} ` +
+				`\+?%{INT:bytes_read:int} %{HAPROXY_STATE}`,
+		},
+		customPatterns: haproxyPatterns,
+	},
+	{
+		format: "postgresql_csvlog",
+		input: `2023-11-14 22:13:21.456 UTC,"app","shop",4711,"10.0.0.5:52044",6553f0a0.1267,4,"INSERT",2023-11-14 22:00:00 UTC,3/43,1234,ERROR,23505,` +
+			`"duplicate key value violates unique constraint ""orders_pkey""","Key (id)=(1) already exists.",,,,,` +
+			`"INSERT INTO orders VALUES (1)",,,"psql","client backend",,0`,
+		patterns: []string{
+			`^%{PG_TIMESTAMP:log_time:ts-"2006-01-02 15:04:05.000 MST"},"%{DATA:user_name:tag}","%{DATA:database_name:tag}",%{INT:process_id:int},` +
+				`"%{DATA:connection_from}",%{NOTSPACE:session_id},%{INT:session_line_num:int},"%{DATA:command_tag:tag}",%{DATA:session_start_time},` +
+				`%{DATA:virtual_transaction_id},%{INT:transaction_id:int},%{WORD:error_severity:tag},%{WORD:sql_state_code:tag},` +
+				`"%{PG_QUOTED:message}","%{PG_QUOTED:detail}",%{DATA},%{DATA},%{DATA},%{DATA},"%{PG_QUOTED:query}",%{DATA},%{DATA},` +
+				`"%{DATA:application_name:tag}","%{DATA:backend_type:tag}",%{DATA},%{INT:query_id:int}$`,
+		},
+		customPatterns: `
+			PG_TIMESTAMP %{YEAR}-%{MONTHNUM}-%{MONTHDAY} %{TIME} %{WORD}
+			PG_QUOTED (?:[^"]|"")*
+		`,
+	},
+	{
+		format: "postgresql_jsonlog",
+		input: `{"timestamp":"2023-11-14 22:13:21.456 UTC","user":"app","dbname":"shop","pid":4711,"remote_host":"10.0.0.5","remote_port":52044,` +
+			`"session_id":"6553f0a0.1267","line_num":4,"ps":"INSERT","session_start":"2023-11-14 22:00:00 UTC","vxid":"3/43","txid":1234,` +
+			`"error_severity":"ERROR","state_code":"23505","message":"duplicate key value violates unique constraint \"orders_pkey\"",` +
+			`"detail":"Key (id)=(1) already exists.","statement":"INSERT INTO orders VALUES (1)","application_name":"psql",` +
+			`"backend_type":"client backend","query_id":0}`,
+	},
+	{
+		format: "mysql_slowlog",
+		input: "# Time: 2023-11-14T22:13:25.000001Z\n" +
+			"# User@Host: report[report] @ localhost [10.0.0.7]  Id:    12\n" +
+			"# Query_time: 5.311402  Lock_time: 0.000120 Rows_sent: 1520  Rows_examined: 2000000\n" +
+			"use shop;\n" +
+			"SET timestamp=1700000005;\n" +
+			"SELECT customer, SUM(total)\nFROM orders\nGROUP BY customer;\n",
+		patterns: []string{
+			`(?s)# Time: %{TIMESTAMP_ISO8601:time:ts-rfc3339nano}\n` +
+				`# User@Host: %{USER:user:tag}\[[^\]]*\] @ %{DATA:client_host} \[%{IP:client_ip}?\]\s+Id:\s+%{INT:connection_id:int}\n` +
+				`# Query_time: %{NUMBER:query_time:float}\s+Lock_time: %{NUMBER:lock_time:float}\s+` +
+				`Rows_sent: %{INT:rows_sent:int}\s+Rows_examined: %{INT:rows_examined:int}\n` +
+				`(?:use %{WORD:schema:tag};\n)?SET timestamp=%{INT};\n%{GREEDYDATA:query}`,
+		},
+		multiline: true,
+	},
+	{
+		format: "journal_export",
+		input: "__CURSOR=s=739ad463348b4ceca5a9e69c95a3c93f;i=4ece7\n" +
+			"__REALTIME_TIMESTAMP=1700000000123456\n" +
+			"__MONOTONIC_TIMESTAMP=1100779402\n" +
+			"PRIORITY=6\n" +
+			"SYSLOG_FACILITY=3\n" +
+			"SYSLOG_IDENTIFIER=sshd\n" +
+			"_PID=1423\n" +
+			"_SYSTEMD_UNIT=ssh.service\n" +
+			"_TRANSPORT=syslog\n" +
+			"_HOSTNAME=web01\n" +
+			"MESSAGE=Accepted publickey for deploy from 10.0.0.5 port 52044 ssh2\n",
+	},
+}
+
+const haproxyPatterns = `
+	HAPROXY_DATE %{MONTHDAY}/%{MONTH}/%{YEAR}:%{TIME}
+	HAPROXY_CLIENT %{IP:client_ip}:%{INT:client_port:int} \[%{HAPROXY_DATE:accept_date:ts-"02/Jan/2006:15:04:05.000"}\]
+	HAPROXY_SERVER %{NOTSPACE:frontend_name:tag} %{NOTSPACE:backend_name:tag}/%{NOTSPACE:server_name:tag}
+	HAPROXY_CONNS %{INT:actconn:int}/%{INT:feconn:int}/%{INT:beconn:int}/%{INT:srvconn:int}/\+?%{INT:retries:int}
+	HAPROXY_STATE %{NOTSPACE:termination_state} %{HAPROXY_CONNS} %{INT:srv_queue:int}/%{INT:backend_queue:int}
+`
+
+func TestBenchmarkData(t *testing.T) {
+	for _, bm := range benchmarks {
+		t.Run(bm.format, func(t *testing.T) {
+			parser := &Parser{Format: bm.format, Log: testutil.Logger{}, metricName: "test"}
+			require.NoError(t, parser.Init())
+			actual, err := parser.Parse([]byte(bm.input))
+			require.NoError(t, err)
+			require.Len(t, actual, 1)
+
+			if len(bm.patterns) == 0 {
+				return
+			}
+
+			// Make sure the grok pattern is a fair comparison by checking it
+			// produces the same tags and fields
+			g := &grok.Parser{
+				Measurement:    "test",
+				Patterns:       bm.patterns,
+				CustomPatterns: bm.customPatterns,
+				Multiline:      bm.multiline,
+				Log:            testutil.Logger{},
+			}
+			require.NoError(t, g.Init())
+			expected, err := g.Parse([]byte(bm.input))
+			require.NoError(t, err)
+			require.Len(t, expected, 1)
+			// Placeholders are kept by grok but omitted by the decoders
+			for key, value := range expected[0].Tags() {
+				if value != "-" {
+					require.Containsf(t, actual[0].Tags(), key, "tag %q missing", key)
+				}
+			}
+			for key, value := range expected[0].Fields() {
+				if value != "-" {
+					require.Containsf(t, actual[0].Fields(), key, "field %q missing", key)
+				}
+			}
+			require.Equal(t, expected[0].Time().UnixMilli(), actual[0].Time().UnixMilli())
+		})
+	}
+}
+
+func BenchmarkParsing(b *testing.B) {
+	for _, bm := range benchmarks {
+		b.Run(bm.format+"/logformat", func(b *testing.B) {
+			parser := &Parser{Format: bm.format, Log: testutil.Logger{}, metricName: "test"}
+			require.NoError(b, parser.Init())
+			benchmarkParser(b, parser, bm.input)
+		})
+
+		if len(bm.patterns) == 0 {
+			continue
+		}
+		b.Run(bm.format+"/grok", func(b *testing.B) {
+			parser := &grok.Parser{
+				Measurement:    "test",
+				Patterns:       bm.patterns,
+				CustomPatterns: bm.customPatterns,
+				Multiline:      bm.multiline,
+				Log:            testutil.Logger{},
+			}
+			require.NoError(b, parser.Init())
+			benchmarkParser(b, parser, bm.input)
+		})
+	}
+}
+
+func benchmarkParser(b *testing.B, parser telegraf.Parser, input string) {
+	data := []byte(input)
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		//nolint:errcheck // Benchmarking so skip the error check to avoid the unnecessary operations
+		parser.Parse(data)
+	}
+}
@@ -0,0 +1,276 @@
+package logformat
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const postgresqlTimeLayout = "2006-01-02 15:04:05.000 MST"
+
+type columnKind int
+
+const (
+	columnString columnKind = iota
+	columnTag
+	columnInt
+	columnTime
+)
+
+// postgresqlColumns lists the columns of the csvlog format in the order
+// written by PostgreSQL. Older versions omit the trailing columns.
+var postgresqlColumns = []struct {
+	name string
+	kind columnKind
+}{
+	{"log_time", columnTime},
+	{"user_name", columnTag},
+	{"database_name", columnTag},
+	{"process_id", columnInt},
+	{"connection_from", columnString},
+	{"session_id", columnString},
+	{"session_line_num", columnInt},
+	{"command_tag", columnTag},
+	{"session_start_time", columnString},
+	{"virtual_transaction_id", columnString},
+	{"transaction_id", columnInt},
+	{"error_severity", columnTag},
+	{"sql_state_code", columnTag},
+	{"message", columnString},
+	{"detail", columnString},
+	{"hint", columnString},
+	{"internal_query", columnString},
+	{"internal_query_pos", columnInt},
+	{"context", columnString},
+	{"query", columnString},
+	{"query_pos", columnInt},
+	{"location", columnString},
+	{"application_name", columnTag},
+	{"backend_type", columnTag},
+	{"leader_pid", columnInt},
+	{"query_id", columnInt},
+}
+
+// postgresqlCSVDecoder decodes the PostgreSQL csvlog format
+type postgresqlCSVDecoder struct {
+	loc *time.Location
+}
+
+// records splits the data at line breaks outside of quoted values, as
+// messages and queries may span multiple lines
+func (*postgresqlCSVDecoder) records(data []byte) [][]byte {
+	var records [][]byte
+	var quoted bool
+	start := 0
+	for i, c := range data {
+		switch c {
+		case '"':
+			quoted = !quoted
+		case '\n':
+			if quoted {
+				continue
+			}
+			if record := trimRecord(data[start:i]); len(record) > 0 {
+				records = append(records, record)
+			}
+			start = i + 1
+		}
+	}
+	if record := trimRecord(data[start:]); len(record) > 0 {
+		records = append(records, record)
+	}
+	return records
+}
+
+func (d *postgresqlCSVDecoder) decode(record []byte) (*entry, error) {
+	values, err := splitCSV(string(trimRecord(record)))
+	if err != nil {
+		return nil, err
+	}
+	// The location column is the last one present in all supported versions
+	if len(values) < 22 {
+		return nil, fmt.Errorf("expected at least 22 columns but got %d", len(values))
+	}
+
+	e := newEntry()
+	for i, value := range values {
+		if i >= len(postgresqlColumns) {
+			break
+		}
+		column := postgresqlColumns[i]
+		switch column.kind {
+		case columnString:
+			setString(e, column.name, value)
+		case columnTag:
+			setTag(e, column.name, value)
+		case columnInt:
+			if err := setInt(e, column.name, value); err != nil {
+				return nil, err
+			}
+		case columnTime:
+			if e.timestamp, err = time.ParseInLocation(postgresqlTimeLayout, value, d.loc); err != nil {
+				return nil, fmt.Errorf("invalid log time: %w", err)
+			}
+		}
+	}
+	return e, nil
+}
+
+// splitCSV splits a CSV record into its values, unquoting quoted values
+func splitCSV(record string) ([]string, error) {
+	values := make([]string, 0, len(postgresqlColumns))
+	for {
+		if !strings.HasPrefix(record, `"`) {
+			idx := strings.IndexByte(record, ',')
+			if idx < 0 {
+				return append(values, record), nil
+			}
+			values = append(values, record[:idx])
+			record = record[idx+1:]
+			continue
+		}
+
+		// Quoted value with quotes escaped by doubling them
+		var value strings.Builder
+		i := 1
+		for {
+			idx := strings.IndexByte(record[i:], '"')
+			if idx < 0 {
+				return nil, errors.New("unterminated quoted value")
+			}
+			value.WriteString(record[i : i+idx])
+			i += idx + 1
+			if i < len(record) && record[i] == '"' {
+				value.WriteByte('"')
+				i++
+				continue
+			}
+			break
+		}
+		values = append(values, value.String())
+		if i >= len(record) {
+			return values, nil
+		}
+		if record[i] != ',' {
+			return nil, fmt.Errorf("unexpected character %q after quoted value", record[i])
+		}
+		record = record[i+1:]
+	}
+}
+
+func trimRecord(record []byte) []byte {
+	for len(record) > 0 && (record[len(record)-1] == '\r' || record[len(record)-1] == '\n') {
+		record = record[:len(record)-1]
+	}
+	return record
+}
+
+// postgresqlJSONDecoder decodes the PostgreSQL jsonlog format. The keys are
+// mapped to the names of the csvlog columns for consistency.
+type postgresqlJSONDecoder struct {
+	loc *time.Location
+}
+
+type postgresqlJSONRecord struct {
+	Timestamp        string `json:"timestamp"`
+	User             string `json:"user"`
+	DBName           string `json:"dbname"`
+	PID              *int64 `json:"pid"`
+	RemoteHost       string `json:"remote_host"`
+	RemotePort       *int64 `json:"remote_port"`
+	SessionID        string `json:"session_id"`
+	LineNum          *int64 `json:"line_num"`
+	PS               string `json:"ps"`
+	SessionStart     string `json:"session_start"`
+	VXID             string `json:"vxid"`
+	TXID             *int64 `json:"txid"`
+	ErrorSeverity    string `json:"error_severity"`
+	StateCode        string `json:"state_code"`
+	Message          string `json:"message"`
+	Detail           string `json:"detail"`
+	Hint             string `json:"hint"`
+	InternalQuery    string `json:"internal_query"`
+	InternalPosition *int64 `json:"internal_position"`
+	Context          string `json:"context"`
+	Statement        string `json:"statement"`
+	CursorPosition   *int64 `json:"cursor_position"`
+	FuncName         string `json:"func_name"`
+	FileName         string `json:"file_name"`
+	FileLineNum      *int64 `json:"file_line_num"`
+	ApplicationName  string `json:"application_name"`
+	BackendType      string `json:"backend_type"`
+	LeaderPID        *int64 `json:"leader_pid"`
+	QueryID          *int64 `json:"query_id"`
+}
+
+func (*postgresqlJSONDecoder) records(data []byte) [][]byte {
+	return splitLines(data)
+}
+
+func (d *postgresqlJSONDecoder) decode(record []byte) (*entry, error) {
+	var r postgresqlJSONRecord
+	if err := json.Unmarshal(record, &r); err != nil {
+		return nil, err
+	}
+
+	e := newEntry()
+	var err error
+	if e.timestamp, err = time.ParseInLocation(postgresqlTimeLayout, r.Timestamp, d.loc); err != nil {
+		return nil, fmt.Errorf("invalid timestamp: %w", err)
+	}
+
+	setTag(e, "user_name", r.User)
+	setTag(e, "database_name", r.DBName)
+	setTag(e, "command_tag", r.PS)
+	setTag(e, "error_severity", r.ErrorSeverity)
+	setTag(e, "sql_state_code", r.StateCode)
+	setTag(e, "application_name", r.ApplicationName)
+	setTag(e, "backend_type", r.BackendType)
+
+	connection := r.RemoteHost
+	if r.RemotePort != nil {
+		connection += ":" + strconv.FormatInt(*r.RemotePort, 10)
+	}
+	setString(e, "connection_from", connection)
+	setString(e, "session_id", r.SessionID)
+	setString(e, "session_start_time", r.SessionStart)
+	setString(e, "virtual_transaction_id", r.VXID)
+	setString(e, "message", r.Message)
+	setString(e, "detail", r.Detail)
+	setString(e, "hint", r.Hint)
+	setString(e, "internal_query", r.InternalQuery)
+	setString(e, "context", r.Context)
+	setString(e, "query", r.Statement)
+
+	// Use the same location format as csvlog, i.e. "function, file:line"
+	var location string
+	if r.FileName != "" {
+		location = r.FileName
+		if r.FileLineNum != nil {
+			location += ":" + strconv.FormatInt(*r.FileLineNum, 10)
+		}
+		if r.FuncName != "" {
+			location = r.FuncName + ", " + location
+		}
+	}
+	setString(e, "location", location)
+
+	setOptionalInt(e, "process_id", r.PID)
+	setOptionalInt(e, "session_line_num", r.LineNum)
+	setOptionalInt(e, "transaction_id", r.TXID)
+	setOptionalInt(e, "internal_query_pos", r.InternalPosition)
+	setOptionalInt(e, "query_pos", r.CursorPosition)
+	setOptionalInt(e, "leader_pid", r.LeaderPID)
+	setOptionalInt(e, "query_id", r.QueryID)
+
+	return e, nil
+}
+
+func setOptionalInt(e *entry, key string, value *int64) {
+	if value != nil {
+		e.fields[key] = *value
+	}
+}
@@ -0,0 +1,182 @@
+package logformat
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var errUnexpectedEnd = errors.New("unexpected end of record")
+
+// quoteReplacer unescapes quotes and backslashes in quoted strings, other
+// escape sequences like "\x16" are kept as-is
+var quoteReplacer = strings.NewReplacer(`\"`, `"`, `\\`, `\`)
+
+// scanner extracts the space-separated elements of a log line without using
+// regular expressions
+type scanner struct {
+	line string
+	pos  int
+}
+
+func (s *scanner) done() bool {
+	s.skipSpaces()
+	return s.pos >= len(s.line)
+}
+
+func (s *scanner) skipSpaces() {
+	for s.pos < len(s.line) && s.line[s.pos] == ' ' {
+		s.pos++
+	}
+}
+
+// peek returns the next non-space character or zero at the end of the line
+func (s *scanner) peek() byte {
+	s.skipSpaces()
+	if s.pos >= len(s.line) {
+		return 0
+	}
+	return s.line[s.pos]
+}
+
+// token returns the characters up to the next space
+func (s *scanner) token() (string, error) {
+	s.skipSpaces()
+	if s.pos >= len(s.line) {
+		return "", errUnexpectedEnd
+	}
+	start := s.pos
+	if idx := strings.IndexByte(s.line[start:], ' '); idx >= 0 {
+		s.pos += idx
+	} else {
+		s.pos = len(s.line)
+	}
+	return s.line[start:s.pos], nil
+}
+
+// enclosed returns the characters between the given delimiters, e.g. a
+// bracketed timestamp
+func (s *scanner) enclosed(open, closing byte) (string, error) {
+	s.skipSpaces()
+	if s.pos >= len(s.line) {
+		return "", errUnexpectedEnd
+	}
+	if s.line[s.pos] != open {
+		return "", fmt.Errorf("expected %q at position %d", open, s.pos)
+	}
+	start := s.pos + 1
+	idx := strings.IndexByte(s.line[start:], closing)
+	if idx < 0 {
+		return "", errUnexpectedEnd
+	}
+	s.pos = start + idx + 1
+	return s.line[start : start+idx], nil
+}
+
+// quoted returns the content of a double-quoted string, skipping quotes
+// escaped by a backslash
+func (s *scanner) quoted() (string, error) {
+	s.skipSpaces()
+	if s.pos >= len(s.line) {
+		return "", errUnexpectedEnd
+	}
+	if s.line[s.pos] != '"' {
+		return "", fmt.Errorf("expected '\"' at position %d", s.pos)
+	}
+	start := s.pos + 1
+	var escaped bool
+	for i := start; i < len(s.line); i++ {
+		switch s.line[i] {
+		case '\\':
+			escaped = true
+			i++
+		case '"':
+			s.pos = i + 1
+			if escaped {
+				return quoteReplacer.Replace(s.line[start:i]), nil
+			}
+			return s.line[start:i], nil
+		}
+	}
+	return "", errUnexpectedEnd
+}
+
+// split cuts the string into the given number of parts at the separator
+func split(s string, sep byte, n int) ([]string, error) {
+	parts := strings.Split(s, string(sep))
+	if len(parts) != n {
+		return nil, fmt.Errorf("expected %d elements in %q", n, s)
+	}
+	return parts, nil
+}
+
+// splitLines returns the non-empty lines of the data
+func splitLines(data []byte) [][]byte {
+	lines := make([][]byte, 0, bytes.Count(data, []byte{'\n'})+1)
+	for len(data) > 0 {
+		var line []byte
+		if idx := bytes.IndexByte(data, '\n'); idx >= 0 {
+			line, data = data[:idx], data[idx+1:]
+		} else {
+			line, data = data, nil
+		}
+		line = bytes.TrimSuffix(line, []byte{'\r'})
+		if len(bytes.TrimSpace(line)) > 0 {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// setInt adds the value as integer field, ignoring placeholders like "-"
+// and a leading '+' used e.g. by HAProxy for retried or truncated values
+func setInt(e *entry, key, value string) error {
+	if value == "" || value == "-" {
+		return nil
+	}
+	v, err := strconv.ParseInt(strings.TrimPrefix(value, "+"), 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid value for %q: %w", key, err)
+	}
+	e.fields[key] = v
+	return nil
+}
+
+// setString adds the value as string field, ignoring empty values and
+// placeholders
+func setString(e *entry, key, value string) {
+	if value == "" || value == "-" {
+		return
+	}
+	e.fields[key] = value
+}
+
+// setTag adds the value as tag, ignoring empty values and placeholders
+func setTag(e *entry, key, value string) {
+	if value == "" || value == "-" {
+		return
+	}
+	e.tags[key] = value
+}
+
+// setRequest splits a HTTP request line like "GET /index.html HTTP/1.1"
+// into the verb tag and the request and HTTP version fields. Invalid request
+// lines are stored as request field.
+func setRequest(e *entry, verbKey, requestKey, line string) {
+	parts := strings.Split(line, " ")
+	if len(parts) < 2 || len(parts) > 3 {
+		setString(e, requestKey, line)
+		return
+	}
+	setTag(e, verbKey, parts[0])
+	setString(e, requestKey, parts[1])
+	if len(parts) == 3 {
+		if version, found := strings.CutPrefix(parts[2], "HTTP/"); found {
+			if v, err := strconv.ParseFloat(version, 64); err == nil {
+				e.fields["http_version"] = v
+			}
+		}
+	}
+}
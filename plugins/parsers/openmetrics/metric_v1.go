@@ -125,7 +125,7 @@ func (p *Parser) extractMetricsV1(ometrics *MetricFamily) []telegraf.Metric {
 					}
 				}
 				if ts := histogram.GetCreated(); ts != nil {
-					fields["created"] = float64(ts.Seconds) + float64(ts.Nanos)/float64(time.Nanosecond)
+					fields["created"] = float64(ts.Seconds) + float64(ts.Nanos)/float64(time.Second)
 				}
 				for _, b := range histogram.Buckets {
 					fname := strconv.FormatFloat(b.GetUpperBound(), 'g', -1, 64)
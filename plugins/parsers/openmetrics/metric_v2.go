@@ -87,7 +87,14 @@ func (p *Parser) extractMetricsV2(ometrics *MetricFamily) []telegraf.Metric {
 					continue
 				}
 				fields := map[string]interface{}{metricName: value}
-				metrics = append(metrics, metric.New("openmetric", tags, fields, t, telegraf.Counter))
+				if ts := omp.GetCounterValue().GetCreated(); ts != nil {
+					fields[metricName+"_created"] = float64(ts.Seconds) + float64(ts.Nanos)/float64(time.Second)
+				}
+				ctags := tags
+				if p.IncludeExemplars {
+					ctags = addExemplar(tags, fields, metricName, omp.GetCounterValue().GetExemplar())
+				}
+				metrics = append(metrics, metric.New("openmetric", ctags, fields, t, telegraf.Counter))
 			case MetricType_STATE_SET:
 				stateset := omp.GetStateSetValue()
 
@@ -123,7 +130,7 @@ func (p *Parser) extractMetricsV2(ometrics *MetricFamily) []telegraf.Metric {
 					}
 				}
 				if ts := histogram.GetCreated(); ts != nil {
-					histFields[metricName+"_created"] = float64(ts.Seconds) + float64(ts.Nanos)/float64(time.Nanosecond)
+					histFields[metricName+"_created"] = float64(ts.Seconds) + float64(ts.Nanos)/float64(time.Second)
 				}
 				metrics = append(metrics, metric.New("openmetric", tags, histFields, t, telegraf.Histogram))
 
@@ -135,6 +142,9 @@ func (p *Parser) extractMetricsV2(ometrics *MetricFamily) []telegraf.Metric {
 					bucketFields := map[string]interface{}{
 						metricName + "_bucket": float64(b.GetCount()),
 					}
+					if p.IncludeExemplars {
+						bucketTags = addExemplar(bucketTags, bucketFields, metricName+"_bucket", b.GetExemplar())
+					}
 					m := metric.New("openmetric", bucketTags, bucketFields, t, telegraf.Histogram)
 					metrics = append(metrics, m)
 
@@ -168,7 +178,7 @@ func (p *Parser) extractMetricsV2(ometrics *MetricFamily) []telegraf.Metric {
 					}
 				}
 				if ts := summary.GetCreated(); ts != nil {
-					summaryFields[metricName+"_created"] = float64(ts.Seconds) + float64(ts.Nanos)/float64(time.Nanosecond)
+					summaryFields[metricName+"_created"] = float64(ts.Seconds) + float64(ts.Nanos)/float64(time.Second)
 				}
 				metrics = append(metrics, metric.New("openmetric", tags, summaryFields, t, telegraf.Summary))
 
@@ -187,3 +197,26 @@ func (p *Parser) extractMetricsV2(ometrics *MetricFamily) []telegraf.Metric {
 	}
 	return metrics
 }
+
+// addExemplar adds the exemplar value and timestamp as fields named after the
+// sample and returns a copy of the tags with the exemplar labels prefixed
+// by "exemplar_".
+func addExemplar(tags map[string]string, fields map[string]interface{}, name string, e *Exemplar) map[string]string {
+	if e == nil {
+		return tags
+	}
+
+	fields[name+"_exemplar"] = e.GetValue()
+	if ts := e.GetTimestamp(); ts != nil {
+		fields[name+"_exemplar_timestamp"] = float64(ts.Seconds) + float64(ts.Nanos)/float64(time.Second)
+	}
+
+	result := make(map[string]string, len(tags)+len(e.GetLabel()))
+	for k, v := range tags {
+		result[k] = v
+	}
+	for _, label := range e.GetLabel() {
+		result["exemplar_"+label.GetName()] = label.GetValue()
+	}
+	return result
+}
@@ -16,11 +16,12 @@ import (
 )
 
 type Parser struct {
-	IgnoreTimestamp bool              `toml:"openmetrics_ignore_timestamp"`
-	MetricVersion   int               `toml:"openmetrics_metric_version"`
-	Header          http.Header       `toml:"-"` // set by the input plugin
-	DefaultTags     map[string]string `toml:"-"`
-	Log             telegraf.Logger   `toml:"-"`
+	IgnoreTimestamp  bool              `toml:"openmetrics_ignore_timestamp"`
+	MetricVersion    int               `toml:"openmetrics_metric_version"`
+	IncludeExemplars bool              `toml:"openmetrics_include_exemplars"`
+	Header           http.Header       `toml:"-"` // set by the input plugin
+	DefaultTags      map[string]string `toml:"-"`
+	Log              telegraf.Logger   `toml:"-"`
 
 	timeFunc func() time.Time
 }
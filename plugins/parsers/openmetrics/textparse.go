@@ -13,6 +13,7 @@ import (
 	"time"
 
 	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/exemplar"
 	"github.com/prometheus/prometheus/model/labels"
 	"github.com/prometheus/prometheus/model/textparse"
 	"google.golang.org/protobuf/types/known/timestamppb"
@@ -169,7 +170,9 @@ func TextToMetricFamilies(data []byte) ([]*MetricFamily, error) {
 			if ts != nil {
 				timestamp = *ts * int64(time.Millisecond)
 			}
-			if mpTimestamp != timestamp {
+			// Created samples are usually exposed without a timestamp
+			// but still belong to the current metric-point.
+			if mpTimestamp != timestamp && (sampleType != "created" || ts != nil) {
 				if mfMetricPoint.Value != nil {
 					mfMetric.MetricPoints = append(mfMetric.MetricPoints, mfMetricPoint)
 				}
@@ -181,6 +184,12 @@ func TextToMetricFamilies(data []byte) ([]*MetricFamily, error) {
 
 			// Fill in the metric-point
 			mfMetricPoint.set(mf.Name, mf.Type, sampleType, value, &metricLabels)
+
+			// Attach the exemplar of the sample if any
+			var e exemplar.Exemplar
+			if parser.Exemplar(&e) {
+				mfMetricPoint.setExemplar(mf.Type, sampleType, &e)
+			}
 		case textparse.EntryComment:
 			// ignore comments
 		case textparse.EntryUnit:
@@ -277,8 +286,7 @@ func (mp *MetricPoint) set(mname string, mtype MetricType, stype string, value f
 		case "total":
 			v.CounterValue.Total = &CounterValue_DoubleValue{DoubleValue: value}
 		case "created":
-			t := time.Unix(0, int64(value*float64(time.Second)))
-			v.CounterValue.Created = timestamppb.New(t)
+			v.CounterValue.Created = secondsToTimestamp(value)
 		}
 		mp.Value = v
 	case MetricType_STATE_SET:
@@ -322,8 +330,7 @@ func (mp *MetricPoint) set(mname string, mtype MetricType, stype string, value f
 		case "count", "gcount":
 			v.HistogramValue.Count = uint64(value)
 		case "created":
-			t := time.Unix(0, int64(value*float64(time.Second)))
-			v.HistogramValue.Created = timestamppb.New(t)
+			v.HistogramValue.Created = secondsToTimestamp(value)
 		case "bucket":
 			var boundLabel string
 			mlabels.Range(func(l labels.Label) {
@@ -363,8 +370,7 @@ func (mp *MetricPoint) set(mname string, mtype MetricType, stype string, value f
 		case "count":
 			v.SummaryValue.Count = uint64(value)
 		case "created":
-			t := time.Unix(0, int64(value*float64(time.Second)))
-			v.SummaryValue.Created = timestamppb.New(t)
+			v.SummaryValue.Created = secondsToTimestamp(value)
 		default:
 			var quantileLabel string
 			mlabels.Range(func(l labels.Label) {
@@ -390,3 +396,34 @@ func (mp *MetricPoint) set(mname string, mtype MetricType, stype string, value f
 		mp.Value = v
 	}
 }
+
+func (mp *MetricPoint) setExemplar(mtype MetricType, stype string, e *exemplar.Exemplar) {
+	ex := &Exemplar{
+		Value: e.Value,
+		Label: make([]*Label, 0, e.Labels.Len()),
+	}
+	if e.HasTs {
+		ex.Timestamp = timestamppb.New(time.UnixMilli(e.Ts))
+	}
+	e.Labels.Range(func(l labels.Label) {
+		ex.Label = append(ex.Label, &Label{Name: l.Name, Value: l.Value})
+	})
+
+	switch mtype {
+	case MetricType_COUNTER:
+		if v, ok := mp.Value.(*MetricPoint_CounterValue); ok && stype == "total" {
+			v.CounterValue.Exemplar = ex
+		}
+	case MetricType_HISTOGRAM, MetricType_GAUGE_HISTOGRAM:
+		if v, ok := mp.Value.(*MetricPoint_HistogramValue); ok && stype == "bucket" {
+			if n := len(v.HistogramValue.Buckets); n > 0 {
+				v.HistogramValue.Buckets[n-1].Exemplar = ex
+			}
+		}
+	}
+}
+
+func secondsToTimestamp(v float64) *timestamppb.Timestamp {
+	sec, frac := math.Modf(v)
+	return &timestamppb.Timestamp{Seconds: int64(sec), Nanos: int32(math.Round(frac * 1e9))}
+}
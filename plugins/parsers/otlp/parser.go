@@ -0,0 +1,164 @@
+package otlp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/influxdata/influxdb-observability/common"
+	"github.com/influxdata/influxdb-observability/otel2influx"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/plugins/parsers"
+)
+
+var metricsSchemata = map[string]common.MetricsSchema{
+	"prometheus-v1": common.MetricsSchemaTelegrafPrometheusV1,
+	"prometheus-v2": common.MetricsSchemaTelegrafPrometheusV2,
+}
+
+type Parser struct {
+	Encoding      string            `toml:"otlp_encoding"`
+	MetricsSchema string            `toml:"otlp_metrics_schema"`
+	DefaultTags   map[string]string `toml:"-"`
+	Log           telegraf.Logger   `toml:"-"`
+
+	schema      common.MetricsSchema
+	unmarshaler pmetric.Unmarshaler
+}
+
+func (p *Parser) Init() error {
+	switch p.Encoding {
+	case "", "protobuf":
+		p.Encoding = "protobuf"
+		p.unmarshaler = &pmetric.ProtoUnmarshaler{}
+	case "json":
+		p.unmarshaler = &pmetric.JSONUnmarshaler{}
+	default:
+		return fmt.Errorf("invalid 'otlp_encoding' %q", p.Encoding)
+	}
+
+	if p.MetricsSchema == "" {
+		p.MetricsSchema = "prometheus-v1"
+	}
+	schema, found := metricsSchemata[p.MetricsSchema]
+	if !found {
+		return fmt.Errorf("invalid 'otlp_metrics_schema' %q", p.MetricsSchema)
+	}
+	p.schema = schema
+
+	return nil
+}
+
+func (p *Parser) Parse(buf []byte) ([]telegraf.Metric, error) {
+	md, err := p.unmarshaler.UnmarshalMetrics(buf)
+	if err != nil {
+		return nil, fmt.Errorf("unmarshalling %s metrics failed: %w", p.Encoding, err)
+	}
+
+	writer := &collector{defaultTags: p.DefaultTags}
+	cfg := otel2influx.DefaultOtelMetricsToLineProtocolConfig()
+	cfg.Logger = &otelLogger{p.Log}
+	cfg.Writer = writer
+	cfg.Schema = p.schema
+	converter, err := otel2influx.NewOtelMetricsToLineProtocol(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if err := converter.WriteMetrics(context.Background(), md); err != nil {
+		return nil, err
+	}
+
+	return writer.metrics, nil
+}
+
+func (p *Parser) ParseLine(line string) (telegraf.Metric, error) {
+	metrics, err := p.Parse([]byte(line))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(metrics) < 1 {
+		return nil, errors.New("no metrics in line")
+	}
+
+	if len(metrics) > 1 {
+		return nil, errors.New("more than one metric in line")
+	}
+
+	return metrics[0], nil
+}
+
+func (p *Parser) SetDefaultTags(tags map[string]string) {
+	p.DefaultTags = tags
+}
+
+// collector gathers the points produced by the converter as metrics
+type collector struct {
+	defaultTags map[string]string
+	metrics     []telegraf.Metric
+}
+
+func (c *collector) NewBatch() otel2influx.InfluxWriterBatch {
+	return c
+}
+
+func (c *collector) EnqueuePoint(
+	_ context.Context,
+	measurement string,
+	tags map[string]string,
+	fields map[string]interface{},
+	ts time.Time,
+	vType common.InfluxMetricValueType,
+) error {
+	var tp telegraf.ValueType
+	switch vType {
+	case common.InfluxMetricValueTypeUntyped:
+		tp = telegraf.Untyped
+	case common.InfluxMetricValueTypeGauge:
+		tp = telegraf.Gauge
+	case common.InfluxMetricValueTypeSum:
+		tp = telegraf.Counter
+	case common.InfluxMetricValueTypeHistogram:
+		tp = telegraf.Histogram
+	case common.InfluxMetricValueTypeSummary:
+		tp = telegraf.Summary
+	default:
+		return fmt.Errorf("unrecognized InfluxMetricValueType %q", vType)
+	}
+
+	m := metric.New(measurement, tags, fields, ts, tp)
+	for k, v := range c.defaultTags {
+		if !m.HasTag(k) {
+			m.AddTag(k, v)
+		}
+	}
+	c.metrics = append(c.metrics, m)
+	return nil
+}
+
+func (*collector) WriteBatch(context.Context) error {
+	return nil
+}
+
+type otelLogger struct {
+	telegraf.Logger
+}
+
+// Debug logs a debug message, patterned after log.Print.
+func (l otelLogger) Debug(msg string, kv ...interface{}) {
+	format := msg + strings.Repeat(" %s=%q", len(kv)/2)
+	l.Logger.Debugf(format, kv...)
+}
+
+func init() {
+	parsers.Add("otlp",
+		func(string) telegraf.Parser {
+			return &Parser{}
+		},
+	)
+}
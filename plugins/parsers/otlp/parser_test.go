@@ -0,0 +1,133 @@
+package otlp
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/models"
+	"github.com/influxdata/telegraf/testutil"
+	test "github.com/influxdata/telegraf/testutil/plugin_input"
+)
+
+func TestCases(t *testing.T) {
+	// Get all directories in testdata
+	folders, err := os.ReadDir("testcases")
+	require.NoError(t, err)
+	require.NotEmpty(t, folders)
+
+	for _, f := range folders {
+		testcasePath := filepath.Join("testcases", f.Name())
+		configFilename := filepath.Join(testcasePath, "telegraf.conf")
+
+		// Configure the plugin
+		cfg := config.NewConfig()
+		require.NoError(t, cfg.LoadConfig(configFilename))
+		require.Len(t, cfg.Inputs, 1)
+
+		// Tune the test-plugin
+		plugin := cfg.Inputs[0].Input.(*test.Plugin)
+		plugin.Path = testcasePath
+		plugin.UseTypeTag = "_type"
+		require.NoError(t, plugin.Init())
+
+		var options []cmp.Option
+		if plugin.ShouldIgnoreTimestamp {
+			options = append(options, testutil.IgnoreTime())
+		}
+
+		t.Run(f.Name()+"_json", func(t *testing.T) {
+			var acc testutil.Accumulator
+			require.NoError(t, plugin.Gather(&acc))
+			testutil.RequireMetricsEqual(t, plugin.Expected, acc.GetTelegrafMetrics(), options...)
+		})
+
+		t.Run(f.Name()+"_protobuf", func(t *testing.T) {
+			// Convert the input to the protobuf encoding
+			buf, err := os.ReadFile(filepath.Join(testcasePath, "input.json"))
+			require.NoError(t, err)
+			md, err := (&pmetric.JSONUnmarshaler{}).UnmarshalMetrics(buf)
+			require.NoError(t, err)
+			buf, err = (&pmetric.ProtoMarshaler{}).MarshalMetrics(md)
+			require.NoError(t, err)
+
+			jsonParser := plugin.Parser.(*models.RunningParser).Parser.(*Parser)
+			parser := &Parser{
+				MetricsSchema: jsonParser.MetricsSchema,
+				DefaultTags:   jsonParser.DefaultTags,
+				Log:           testutil.Logger{},
+			}
+			require.NoError(t, parser.Init())
+
+			actual, err := parser.Parse(buf)
+			require.NoError(t, err)
+			testutil.RequireMetricsEqual(t, plugin.Expected, actual, options...)
+		})
+	}
+}
+
+func TestInvalidInput(t *testing.T) {
+	parser := &Parser{Encoding: "json", Log: testutil.Logger{}}
+	require.NoError(t, parser.Init())
+	_, err := parser.Parse([]byte(`{"resourceMetrics": 42}`))
+	require.ErrorContains(t, err, "unmarshalling json metrics failed")
+
+	parser = &Parser{Log: testutil.Logger{}}
+	require.NoError(t, parser.Init())
+	_, err = parser.Parse([]byte{0xff, 0xff, 0xff})
+	require.ErrorContains(t, err, "unmarshalling protobuf metrics failed")
+}
+
+func TestInitErrors(t *testing.T) {
+	parser := &Parser{Encoding: "xml"}
+	require.ErrorContains(t, parser.Init(), `invalid 'otlp_encoding' "xml"`)
+
+	parser = &Parser{MetricsSchema: "prometheus-v3"}
+	require.ErrorContains(t, parser.Init(), `invalid 'otlp_metrics_schema' "prometheus-v3"`)
+}
+
+func TestParseLine(t *testing.T) {
+	parser := &Parser{Encoding: "json", Log: testutil.Logger{}}
+	require.NoError(t, parser.Init())
+
+	input := `{"resourceMetrics":[{"scopeMetrics":[{"metrics":[` +
+		`{"name":"temperature","gauge":{"dataPoints":[{"timeUnixNano":"1700000000000000000","asDouble":21.5}]}}` +
+		`]}]}]}`
+	expected := metric.New(
+		"temperature",
+		map[string]string{},
+		map[string]interface{}{"gauge": 21.5},
+		time.Unix(1700000000, 0),
+		telegraf.Gauge,
+	)
+
+	actual, err := parser.ParseLine(input)
+	require.NoError(t, err)
+	testutil.RequireMetricsEqual(t, []telegraf.Metric{expected}, []telegraf.Metric{actual})
+}
+
+func BenchmarkParsing(b *testing.B) {
+	buf, err := os.ReadFile(filepath.Join("testcases", "histogram", "input.json"))
+	require.NoError(b, err)
+	md, err := (&pmetric.JSONUnmarshaler{}).UnmarshalMetrics(buf)
+	require.NoError(b, err)
+	buf, err = (&pmetric.ProtoMarshaler{}).MarshalMetrics(md)
+	require.NoError(b, err)
+
+	parser := &Parser{Log: testutil.Logger{}}
+	require.NoError(b, parser.Init())
+
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		//nolint:errcheck // Benchmarking so skip the error check to avoid the unnecessary operations
+		parser.Parse(buf)
+	}
+}
@@ -10,6 +10,7 @@ import (
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/plugins/common/nativehistogram"
 )
 
 func (p *Parser) extractMetricsV1(ts *prompb.TimeSeries) ([]telegraf.Metric, error) {
@@ -62,14 +63,7 @@ func (p *Parser) extractMetricsV1(ts *prompb.TimeSeries) ([]telegraf.Metric, err
 			t = time.Unix(0, hp.Timestamp*1000000)
 		}
 
-		fields := map[string]any{
-			"counter_reset_hint": uint64(h.CounterResetHint),
-			"schema":             int64(h.Schema),
-			"zero_threshold":     h.ZeroThreshold,
-			"zero_count":         h.ZeroCount,
-			"count":              h.Count,
-			"sum":                h.Sum,
-		}
+		fields := nativehistogram.Fields(h)
 
 		count := 0.0
 		iter := h.AllBucketIterator()
@@ -79,25 +73,6 @@ func (p *Parser) extractMetricsV1(ts *prompb.TimeSeries) ([]telegraf.Metric, err
 			fields[fmt.Sprintf("%g", bucket.Upper)] = count
 		}
 
-		// expand positiveSpans and negativeSpans into fields
-		for i, span := range h.PositiveSpans {
-			fields[fmt.Sprintf("positive_span_%d_offset", i)] = int64(span.Offset)
-			fields[fmt.Sprintf("positive_span_%d_length", i)] = uint64(span.Length)
-		}
-
-		for i, span := range h.NegativeSpans {
-			fields[fmt.Sprintf("negative_span_%d_offset", i)] = int64(span.Offset)
-			fields[fmt.Sprintf("negative_span_%d_length", i)] = uint64(span.Length)
-		}
-		// expand positiveBuckets and negativeBuckets into fields
-		for i, bucket := range h.PositiveBuckets {
-			fields[fmt.Sprintf("positive_bucket_%d", i)] = bucket
-		}
-
-		for i, bucket := range h.NegativeBuckets {
-			fields[fmt.Sprintf("negative_bucket_%d", i)] = bucket
-		}
-
 		m := metric.New(metricName, tags, fields, t, telegraf.Histogram)
 		metrics = append(metrics, m)
 	}
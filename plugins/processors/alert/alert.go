@@ -0,0 +1,269 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package alert
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/cel-go/cel"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/models"
+	"github.com/influxdata/telegraf/plugins/processors"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+// Alert states ordered by severity
+const (
+	stateOK = iota
+	stateWarn
+	stateCrit
+)
+
+var stateNames = []string{"ok", "warn", "crit"}
+
+type Alert struct {
+	Alert         string          `toml:"alert"`
+	Measurement   string          `toml:"measurement"`
+	Crit          string          `toml:"crit"`
+	Warn          string          `toml:"warn"`
+	CritRecover   string          `toml:"crit_recover"`
+	WarnRecover   string          `toml:"warn_recover"`
+	For           config.Duration `toml:"for"`
+	ValueField    string          `toml:"value_field"`
+	SeriesTimeout config.Duration `toml:"series_timeout"`
+	Log           telegraf.Logger `toml:"-"`
+
+	crit        cel.Program
+	warn        cel.Program
+	critRecover cel.Program
+	warnRecover cel.Program
+	cache       map[uint64]*series
+	nextExpiry  time.Time
+}
+
+// series contains the alert state of a series and is persisted as state
+type series struct {
+	State        int       `json:"state"`
+	Since        time.Time `json:"since"`
+	Pending      int       `json:"pending"`
+	PendingSince time.Time `json:"pending_since"`
+
+	lastSeen time.Time
+}
+
+func (*Alert) SampleConfig() string {
+	return sampleConfig
+}
+
+func (a *Alert) Init() error {
+	if a.Crit == "" && a.Warn == "" {
+		return errors.New("either 'crit' or 'warn' condition must be set")
+	}
+	if a.Crit == "" && a.CritRecover != "" {
+		return errors.New("'crit_recover' requires a 'crit' condition")
+	}
+	if a.Warn == "" && a.WarnRecover != "" {
+		return errors.New("'warn_recover' requires a 'warn' condition")
+	}
+	if a.Measurement == "" {
+		a.Measurement = "alert"
+	}
+	if a.SeriesTimeout < 0 {
+		return errors.New("'series_timeout' must not be negative")
+	}
+	if a.SeriesTimeout == 0 {
+		a.SeriesTimeout = config.Duration(time.Hour)
+	}
+
+	for _, p := range []struct {
+		setting    string
+		expression string
+		prog       *cel.Program
+	}{
+		{"crit", a.Crit, &a.crit},
+		{"warn", a.Warn, &a.warn},
+		{"crit_recover", a.CritRecover, &a.critRecover},
+		{"warn_recover", a.WarnRecover, &a.warnRecover},
+	} {
+		if p.expression == "" {
+			continue
+		}
+		prog, err := models.CompileMetricExpression(p.expression)
+		if err != nil {
+			return fmt.Errorf("compiling %q condition failed: %w", p.setting, err)
+		}
+		*p.prog = prog
+	}
+
+	a.cache = make(map[uint64]*series)
+
+	return nil
+}
+
+func (a *Alert) GetState() interface{} {
+	return a.cache
+}
+
+func (a *Alert) SetState(state interface{}) error {
+	cache, ok := state.(map[uint64]*series)
+	if !ok {
+		return errors.New("state has to be of type 'map[uint64]*series'")
+	}
+
+	// Restored series expire relative to the restore time
+	now := time.Now()
+	for id, s := range cache {
+		if s == nil || s.State < stateOK || s.State > stateCrit {
+			continue
+		}
+		s.lastSeen = now
+		a.cache[id] = s
+	}
+	return nil
+}
+
+func (a *Alert) Apply(in ...telegraf.Metric) []telegraf.Metric {
+	now := time.Now()
+	defer a.expire(now)
+
+	out := make([]telegraf.Metric, 0, len(in))
+	for _, m := range in {
+		out = append(out, m)
+
+		vars := map[string]interface{}{
+			"name":   m.Name(),
+			"tags":   m.Tags(),
+			"fields": m.Fields(),
+			"time":   m.Time(),
+		}
+
+		id := m.HashID()
+		s, found := a.cache[id]
+		if !found {
+			s = &series{State: stateOK, Since: m.Time(), Pending: stateOK}
+		}
+
+		target, err := a.target(s.State, vars)
+		if err != nil {
+			a.Log.Debugf("Evaluating conditions for %q failed: %v", m.Name(), err)
+			continue
+		}
+		s.lastSeen = now
+		a.cache[id] = s
+
+		// Escalations need to be pending for the configured duration while
+		// de-escalations happen immediately
+		if target > s.State {
+			if s.Pending <= s.State {
+				s.PendingSince = m.Time()
+			}
+			s.Pending = target
+			if m.Time().Sub(s.PendingSince) < time.Duration(a.For) {
+				continue
+			}
+		}
+		s.Pending = stateOK
+		if target == s.State {
+			continue
+		}
+
+		out = append(out, a.event(m, s, target))
+		s.State = target
+		s.Since = m.Time()
+	}
+	return out
+}
+
+// target determines the state of the series for the given metric taking
+// hysteresis into account
+func (a *Alert) target(current int, vars map[string]interface{}) (int, error) {
+	if a.crit != nil {
+		active, err := a.active(current >= stateCrit, a.crit, a.critRecover, vars)
+		if err != nil {
+			return current, err
+		}
+		if active {
+			return stateCrit, nil
+		}
+	}
+	if a.warn != nil {
+		active, err := a.active(current >= stateWarn, a.warn, a.warnRecover, vars)
+		if err != nil {
+			return current, err
+		}
+		if active {
+			return stateWarn, nil
+		}
+	}
+	return stateOK, nil
+}
+
+// active checks if a condition is active. Conditions already active stay
+// active until the recover condition is met.
+func (*Alert) active(isActive bool, condition, recover cel.Program, vars map[string]interface{}) (bool, error) {
+	if isActive && recover != nil {
+		recovered, err := eval(recover, vars)
+		return !recovered, err
+	}
+	return eval(condition, vars)
+}
+
+func (a *Alert) event(m telegraf.Metric, s *series, state int) telegraf.Metric {
+	tags := m.Tags()
+	tags["measurement"] = m.Name()
+	if a.Alert != "" {
+		tags["alert"] = a.Alert
+	}
+
+	fields := map[string]interface{}{
+		"state":          stateNames[state],
+		"previous_state": stateNames[s.State],
+		"duration":       m.Time().Sub(s.Since).Seconds(),
+	}
+	if a.ValueField != "" {
+		if v, found := m.GetField(a.ValueField); found {
+			fields["value"] = v
+		}
+	}
+
+	return metric.New(a.Measurement, tags, fields, m.Time())
+}
+
+// expire removes series without metrics within the series timeout to limit
+// the memory used by vanished series. The check runs at most once per timeout.
+func (a *Alert) expire(now time.Time) {
+	if now.Before(a.nextExpiry) {
+		return
+	}
+	a.nextExpiry = now.Add(time.Duration(a.SeriesTimeout))
+
+	for id, s := range a.cache {
+		if now.Sub(s.lastSeen) > time.Duration(a.SeriesTimeout) {
+			delete(a.cache, id)
+		}
+	}
+}
+
+func eval(prog cel.Program, vars map[string]interface{}) (bool, error) {
+	result, _, err := prog.Eval(vars)
+	if err != nil {
+		return false, err
+	}
+	if r, ok := result.Value().(bool); ok {
+		return r, nil
+	}
+	return false, fmt.Errorf("invalid result type %T", result.Value())
+}
+
+func init() {
+	processors.Add("alert", func() telegraf.Processor {
+		return &Alert{}
+	})
+}
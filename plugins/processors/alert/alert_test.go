@@ -0,0 +1,233 @@
+package alert
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInitInvalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *Alert
+		expected string
+	}{
+		{
+			name:     "no condition",
+			plugin:   &Alert{},
+			expected: "either 'crit' or 'warn' condition must be set",
+		},
+		{
+			name:     "recover without condition",
+			plugin:   &Alert{Crit: "fields.value > 1", WarnRecover: "fields.value < 1"},
+			expected: "'warn_recover' requires a 'warn' condition",
+		},
+		{
+			name:     "invalid expression",
+			plugin:   &Alert{Crit: "fields.value >"},
+			expected: `compiling "crit" condition failed`,
+		},
+		{
+			name:     "non-boolean expression",
+			plugin:   &Alert{Warn: "fields.value"},
+			expected: "expression needs to return a boolean",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.plugin.Log = testutil.Logger{}
+			require.ErrorContains(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestStateChanges(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+	newMetric := func(idle float64, offset int) telegraf.Metric {
+		return metric.New(
+			"cpu",
+			map[string]string{"cpu": "cpu-total"},
+			map[string]interface{}{"usage_idle": idle},
+			start.Add(time.Duration(offset)*time.Second),
+		)
+	}
+	newEvent := func(state, previous string, duration, value float64, offset int) telegraf.Metric {
+		return metric.New(
+			"alert",
+			map[string]string{"cpu": "cpu-total", "measurement": "cpu", "alert": "cpu_idle"},
+			map[string]interface{}{
+				"state":          state,
+				"previous_state": previous,
+				"duration":       duration,
+				"value":          value,
+			},
+			start.Add(time.Duration(offset)*time.Second),
+		)
+	}
+
+	tests := []struct {
+		name     string
+		plugin   *Alert
+		input    []telegraf.Metric
+		expected []telegraf.Metric
+	}{
+		{
+			name: "thresholds",
+			plugin: &Alert{
+				Crit: "fields.usage_idle < 5.0",
+				Warn: "fields.usage_idle < 20.0",
+			},
+			input: []telegraf.Metric{
+				newMetric(50, 0),
+				newMetric(15, 10),
+				newMetric(3, 20),
+				newMetric(4, 30),
+				newMetric(19, 40),
+				newMetric(21, 50),
+			},
+			expected: []telegraf.Metric{
+				newEvent("warn", "ok", 10, 15, 10),
+				newEvent("crit", "warn", 10, 3, 20),
+				newEvent("warn", "crit", 20, 19, 40),
+				newEvent("ok", "warn", 10, 21, 50),
+			},
+		},
+		{
+			name: "hysteresis",
+			plugin: &Alert{
+				Crit:        "fields.usage_idle < 5.0",
+				CritRecover: "fields.usage_idle > 10.0",
+			},
+			input: []telegraf.Metric{
+				newMetric(4, 0),
+				newMetric(6, 10),
+				newMetric(9, 20),
+				newMetric(11, 30),
+				newMetric(6, 40),
+			},
+			expected: []telegraf.Metric{
+				newEvent("crit", "ok", 0, 4, 0),
+				newEvent("ok", "crit", 30, 11, 30),
+			},
+		},
+		{
+			name: "for duration",
+			plugin: &Alert{
+				Crit: "fields.usage_idle < 5.0",
+				For:  config.Duration(20 * time.Second),
+			},
+			input: []telegraf.Metric{
+				newMetric(4, 0),
+				newMetric(4, 10),
+				newMetric(6, 20),
+				newMetric(4, 30),
+				newMetric(4, 40),
+				newMetric(4, 50),
+				newMetric(6, 60),
+			},
+			expected: []telegraf.Metric{
+				newEvent("crit", "ok", 50, 4, 50),
+				newEvent("ok", "crit", 10, 6, 60),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.plugin.Alert = "cpu_idle"
+			tt.plugin.ValueField = "usage_idle"
+			tt.plugin.Log = testutil.Logger{}
+			require.NoError(t, tt.plugin.Init())
+
+			var actual []telegraf.Metric
+			for _, m := range tt.plugin.Apply(tt.input...) {
+				if m.Name() == "alert" {
+					actual = append(actual, m)
+				}
+			}
+			testutil.RequireMetricsEqual(t, tt.expected, actual)
+		})
+	}
+}
+
+func TestPassThrough(t *testing.T) {
+	plugin := &Alert{Warn: "fields.value > 1", Log: testutil.Logger{}}
+	require.NoError(t, plugin.Init())
+
+	// Metrics failing to evaluate are passed without changing the state
+	input := []telegraf.Metric{
+		metric.New("test", nil, map[string]interface{}{"other": 2}, time.Unix(0, 0)),
+		metric.New("test", nil, map[string]interface{}{"value": 0}, time.Unix(1, 0)),
+	}
+	actual := plugin.Apply(input...)
+	testutil.RequireMetricsEqual(t, input, actual)
+}
+
+func TestSeriesTimeout(t *testing.T) {
+	plugin := &Alert{
+		Warn:          "fields.value > 1",
+		SeriesTimeout: config.Duration(10 * time.Millisecond),
+		Log:           testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	now := time.Now()
+	plugin.Apply(
+		metric.New("test", map[string]string{"host": "a"}, map[string]interface{}{"value": 2}, now),
+		metric.New("test", map[string]string{"host": "b"}, map[string]interface{}{"value": 0}, now),
+	)
+	require.Len(t, plugin.cache, 2)
+
+	// Idle series must be removed independent of their state
+	time.Sleep(20 * time.Millisecond)
+	plugin.Apply(metric.New("test", map[string]string{"host": "c"}, map[string]interface{}{"value": 0}, now))
+	require.Len(t, plugin.cache, 1)
+
+	// Series seen again start in ok state
+	actual := plugin.Apply(metric.New("test", map[string]string{"host": "a"}, map[string]interface{}{"value": 2}, now))
+	require.Len(t, actual, 2)
+	require.Equal(t, "ok", actual[1].Fields()["previous_state"])
+}
+
+func TestState(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+	plugin := &Alert{Warn: "fields.value > 1", Log: testutil.Logger{}}
+	require.NoError(t, plugin.Init())
+	actual := plugin.Apply(metric.New("test", nil, map[string]interface{}{"value": 2}, start))
+	require.Len(t, actual, 2)
+
+	// Roundtrip the state the same way as the persister
+	buf, err := json.Marshal(plugin.GetState())
+	require.NoError(t, err)
+	state := reflect.New(reflect.TypeOf(plugin.GetState()))
+	require.NoError(t, json.Unmarshal(buf, state.Interface()))
+
+	restored := &Alert{Warn: "fields.value > 1", Log: testutil.Logger{}}
+	require.NoError(t, restored.Init())
+	require.NoError(t, restored.SetState(state.Elem().Interface()))
+
+	// The restored series is in warning state already, so recovering must
+	// create an event with the correct duration
+	input := metric.New("test", nil, map[string]interface{}{"value": 0}, start.Add(time.Minute))
+	expected := []telegraf.Metric{
+		input,
+		metric.New(
+			"alert",
+			map[string]string{"measurement": "test"},
+			map[string]interface{}{
+				"state":          "ok",
+				"previous_state": "warn",
+				"duration":       60.0,
+			},
+			start.Add(time.Minute),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, restored.Apply(input))
+}
@@ -0,0 +1,5 @@
+//go:build !custom || processors || processors.alert
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/processors/alert" // register plugin
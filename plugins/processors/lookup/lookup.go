@@ -3,6 +3,8 @@ package lookup
 
 import (
 	"bytes"
+	"context"
+	dbsql "database/sql"
 	_ "embed"
 	"encoding/csv"
 	"encoding/json"
@@ -11,23 +13,48 @@ import (
 	"io"
 	"os"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"text/template"
+	"time"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/plugins/common/sqldriver"
 	"github.com/influxdata/telegraf/plugins/processors"
+	"github.com/influxdata/telegraf/selfstat"
 )
 
 //go:embed sample.conf
 var sampleConfig string
 
 type Processor struct {
-	Filenames   []string        `toml:"files"`
-	Fileformat  string          `toml:"format"`
-	KeyTemplate string          `toml:"key"`
-	Log         telegraf.Logger `toml:"-"`
+	Filenames      []string            `toml:"files"`
+	Fileformat     string              `toml:"format"`
+	ReloadInterval config.Duration     `toml:"reload_interval"`
+	Driver         string              `toml:"driver"`
+	Dsn            config.Secret       `toml:"dsn"`
+	Query          string              `toml:"query"`
+	TTL            config.Duration     `toml:"ttl"`
+	Timeout        config.Duration     `toml:"timeout"`
+	KeyTemplate    string              `toml:"key"`
+	OnMiss         string              `toml:"on_miss"`
+	DefaultTags    map[string]string   `toml:"default_tags"`
+	Log            telegraf.Logger     `toml:"-"`
+	Statistics     *selfstat.Collector `toml:"-"`
 
-	tmpl     *template.Template
-	mappings map[string][]telegraf.Tag
+	tmpl       *template.Template
+	driverName string
+	defaults   []telegraf.Tag
+	mappings   atomic.Pointer[map[string][]telegraf.Tag]
+	modified   map[string]fileState
+	cancel     context.CancelFunc
+	wg         sync.WaitGroup
+
+	hits    selfstat.Stat
+	misses  selfstat.Stat
+	reloads selfstat.Stat
+	errors  selfstat.Stat
 }
 
 func (*Processor) SampleConfig() string {
@@ -35,8 +62,11 @@ func (*Processor) SampleConfig() string {
 }
 
 func (p *Processor) Init() error {
-	if len(p.Filenames) < 1 {
-		return errors.New("missing 'files'")
+	if len(p.Filenames) < 1 && p.Driver == "" {
+		return errors.New("missing 'files' or 'driver'")
+	}
+	if len(p.Filenames) > 0 && p.Driver != "" {
+		return errors.New("'files' and 'driver' are mutually exclusive")
 	}
 
 	if p.KeyTemplate == "" {
@@ -49,20 +79,95 @@ func (p *Processor) Init() error {
 	}
 	p.tmpl = tmpl
 
-	p.mappings = make(map[string][]telegraf.Tag)
-	switch strings.ToLower(p.Fileformat) {
-	case "", "json":
-		return p.loadJSONFiles()
-	case "csv_key_name_value":
-		return p.loadCSVKeyNameValueFiles()
-	case "csv_key_values":
-		return p.loadCSVKeyValuesFiles()
+	switch p.OnMiss {
+	case "":
+		p.OnMiss = "pass"
+	case "pass", "drop":
+	default:
+		return fmt.Errorf("invalid 'on_miss' value %q", p.OnMiss)
+	}
+	for k, v := range p.DefaultTags {
+		p.defaults = append(p.defaults, telegraf.Tag{Key: k, Value: v})
+	}
+
+	if p.Driver != "" {
+		if p.Query == "" {
+			return errors.New("missing 'query'")
+		}
+		driverName, err := sqldriver.Resolve(p.Driver)
+		if err != nil {
+			return err
+		}
+		p.driverName = driverName
+		if p.Timeout <= 0 {
+			p.Timeout = config.Duration(5 * time.Second)
+		}
+		p.ReloadInterval = p.TTL
+	} else {
+		switch strings.ToLower(p.Fileformat) {
+		case "", "json", "csv_key_name_value", "csv_key_values":
+		default:
+			return fmt.Errorf("invalid format %q", p.Fileformat)
+		}
+	}
+
+	if p.Statistics == nil {
+		p.Statistics = selfstat.NewCollector(nil)
+	}
+	p.hits = p.Statistics.Register("lookup", "hits", nil)
+	p.misses = p.Statistics.Register("lookup", "misses", nil)
+	p.reloads = p.Statistics.Register("lookup", "reloads", nil)
+	p.errors = p.Statistics.Register("lookup", "reload_errors", nil)
+
+	mappings, err := p.load(context.Background())
+	if err != nil {
+		return err
+	}
+	p.mappings.Store(&mappings)
+
+	return nil
+}
+
+// Start periodically reloads the lookup-table in the background if a reload
+// interval is configured
+func (p *Processor) Start(telegraf.Accumulator) error {
+	if p.ReloadInterval <= 0 {
+		return nil
 	}
 
-	return fmt.Errorf("invalid format %q", p.Fileformat)
+	ctx, cancel := context.WithCancel(context.Background())
+	p.cancel = cancel
+
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+
+		ticker := time.NewTicker(time.Duration(p.ReloadInterval))
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				p.reload(ctx)
+			}
+		}
+	}()
+
+	return nil
+}
+
+// Stop cancels the background reloading and waits for in-flight reloads
+func (p *Processor) Stop() {
+	if p.cancel != nil {
+		p.cancel()
+	}
+	p.wg.Wait()
 }
 
 func (p *Processor) Apply(in ...telegraf.Metric) []telegraf.Metric {
+	mappings := *p.mappings.Load()
+
 	out := make([]telegraf.Metric, 0, len(in))
 	for _, raw := range in {
 		m := raw
@@ -74,17 +179,176 @@ func (p *Processor) Apply(in ...telegraf.Metric) []telegraf.Metric {
 		if err := p.tmpl.Execute(&buf, m); err != nil {
 			p.Log.Errorf("generating key failed: %v", err)
 			p.Log.Debugf("metric was %v", m)
-		} else if tags, found := p.mappings[buf.String()]; found {
+		} else if tags, found := mappings[buf.String()]; found {
+			p.hits.Incr(1)
 			for _, tag := range tags {
 				m.AddTag(tag.Key, tag.Value)
 			}
+		} else {
+			p.misses.Incr(1)
+			if p.OnMiss == "drop" {
+				raw.Drop()
+				continue
+			}
+			for _, tag := range p.defaults {
+				m.AddTag(tag.Key, tag.Value)
+			}
 		}
 		out = append(out, raw)
 	}
 	return out
 }
 
-func (p *Processor) loadJSONFiles() error {
+// reload replaces the lookup-table if the files changed or the SQL
+// data source is used. The current table is kept if loading fails or results
+// in an empty table, e.g. due to a truncated file.
+func (p *Processor) reload(ctx context.Context) {
+	if p.Driver == "" && !p.filesChanged() {
+		return
+	}
+
+	mappings, err := p.load(ctx)
+	if err == nil && len(mappings) == 0 {
+		err = errors.New("lookup-table is empty")
+	}
+	if err != nil {
+		if ctx.Err() != nil {
+			return
+		}
+		p.errors.Incr(1)
+		p.Log.Errorf("Reloading lookup-table failed, keeping the current one: %v", err)
+		return
+	}
+	p.mappings.Store(&mappings)
+	p.reloads.Incr(1)
+	p.Log.Debugf("Reloaded lookup-table with %d entries", len(mappings))
+}
+
+// fileState is used to detect modifications of the lookup files
+type fileState struct {
+	modified time.Time
+	size     int64
+}
+
+func (s fileState) equal(other fileState) bool {
+	return s.modified.Equal(other.modified) && s.size == other.size
+}
+
+func statFiles(filenames []string) map[string]fileState {
+	states := make(map[string]fileState, len(filenames))
+	for _, fn := range filenames {
+		if info, err := os.Stat(fn); err == nil {
+			states[fn] = fileState{modified: info.ModTime(), size: info.Size()}
+		}
+	}
+	return states
+}
+
+// load reads the lookup-table from the configured source
+func (p *Processor) load(ctx context.Context) (map[string][]telegraf.Tag, error) {
+	mappings := make(map[string][]telegraf.Tag)
+	if p.Driver != "" {
+		return mappings, p.loadSQL(ctx, mappings)
+	}
+
+	// Record the state of the files before loading to detect files being
+	// written while loading. Failed loads are only retried once the files
+	// change again.
+	modified := statFiles(p.Filenames)
+	p.modified = modified
+
+	var err error
+	switch strings.ToLower(p.Fileformat) {
+	case "", "json":
+		err = p.loadJSONFiles(mappings)
+	case "csv_key_name_value":
+		err = p.loadCSVKeyNameValueFiles(mappings)
+	case "csv_key_values":
+		err = p.loadCSVKeyValuesFiles(mappings)
+	}
+	if err != nil {
+		return nil, err
+	}
+	for fn, state := range statFiles(p.Filenames) {
+		if !state.equal(modified[fn]) {
+			return nil, fmt.Errorf("file %q changed while loading", fn)
+		}
+	}
+
+	return mappings, nil
+}
+
+// filesChanged checks if any file was modified since the last load
+func (p *Processor) filesChanged() bool {
+	for _, fn := range p.Filenames {
+		info, err := os.Stat(fn)
+		if err != nil {
+			// Let the load report the error
+			return true
+		}
+		state := fileState{modified: info.ModTime(), size: info.Size()}
+		if !state.equal(p.modified[fn]) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *Processor) loadSQL(ctx context.Context, mappings map[string][]telegraf.Tag) error {
+	dsnSecret, err := p.Dsn.Get()
+	if err != nil {
+		return fmt.Errorf("getting DSN failed: %w", err)
+	}
+	dsn := dsnSecret.String()
+	dsnSecret.Destroy()
+
+	db, err := dbsql.Open(p.driverName, dsn)
+	if err != nil {
+		return fmt.Errorf("opening database failed: %w", err)
+	}
+	defer db.Close()
+
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(p.Timeout))
+	defer cancel()
+
+	rows, err := db.QueryContext(ctx, p.Query)
+	if err != nil {
+		return fmt.Errorf("querying lookup-table failed: %w", err)
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return fmt.Errorf("getting columns failed: %w", err)
+	}
+	if len(columns) < 2 {
+		return errors.New("query result has not enough columns, requiring at least `key,value`")
+	}
+
+	values := make([]dbsql.NullString, len(columns))
+	ptrs := make([]interface{}, len(columns))
+	for i := range values {
+		ptrs[i] = &values[i]
+	}
+	for rows.Next() {
+		if err := rows.Scan(ptrs...); err != nil {
+			return fmt.Errorf("scanning row failed: %w", err)
+		}
+		if !values[0].Valid {
+			continue
+		}
+
+		key := values[0].String
+		for i, v := range values[1:] {
+			if v.Valid && v.String != "" {
+				mappings[key] = append(mappings[key], telegraf.Tag{Key: columns[i+1], Value: v.String})
+			}
+		}
+	}
+	return rows.Err()
+}
+
+func (p *Processor) loadJSONFiles(mappings map[string][]telegraf.Tag) error {
 	for _, fn := range p.Filenames {
 		buf, err := os.ReadFile(fn)
 		if err != nil {
@@ -98,23 +362,23 @@ func (p *Processor) loadJSONFiles() error {
 
 		for key, tags := range data {
 			for k, v := range tags {
-				p.mappings[key] = append(p.mappings[key], telegraf.Tag{Key: k, Value: v})
+				mappings[key] = append(mappings[key], telegraf.Tag{Key: k, Value: v})
 			}
 		}
 	}
 	return nil
 }
 
-func (p *Processor) loadCSVKeyNameValueFiles() error {
+func (p *Processor) loadCSVKeyNameValueFiles(mappings map[string][]telegraf.Tag) error {
 	for _, fn := range p.Filenames {
-		if err := p.loadCSVKeyNameValueFile(fn); err != nil {
+		if err := loadCSVKeyNameValueFile(mappings, fn); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
-func (p *Processor) loadCSVKeyNameValueFile(fn string) error {
+func loadCSVKeyNameValueFile(mappings map[string][]telegraf.Tag, fn string) error {
 	f, err := os.Open(fn)
 	if err != nil {
 		return fmt.Errorf("loading %q failed: %w", fn, err)
@@ -146,23 +410,23 @@ func (p *Processor) loadCSVKeyNameValueFile(fn string) error {
 		key := data[0]
 		for i := 1; i < len(data)-1; i += 2 {
 			k, v := data[i], data[i+1]
-			p.mappings[key] = append(p.mappings[key], telegraf.Tag{Key: k, Value: v})
+			mappings[key] = append(mappings[key], telegraf.Tag{Key: k, Value: v})
 		}
 	}
 
 	return nil
 }
 
-func (p *Processor) loadCSVKeyValuesFiles() error {
+func (p *Processor) loadCSVKeyValuesFiles(mappings map[string][]telegraf.Tag) error {
 	for _, fn := range p.Filenames {
-		if err := p.loadCSVKeyValuesFile(fn); err != nil {
+		if err := loadCSVKeyValuesFile(mappings, fn); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
-func (p *Processor) loadCSVKeyValuesFile(fn string) error {
+func loadCSVKeyValuesFile(mappings map[string][]telegraf.Tag, fn string) error {
 	f, err := os.Open(fn)
 	if err != nil {
 		return fmt.Errorf("loading %q failed: %w", fn, err)
@@ -201,7 +465,7 @@ func (p *Processor) loadCSVKeyValuesFile(fn string) error {
 		for i, v := range data[1:] {
 			v = strings.TrimSpace(v)
 			if v != "" {
-				p.mappings[key] = append(p.mappings[key], telegraf.Tag{Key: header[i], Value: v})
+				mappings[key] = append(mappings[key], telegraf.Tag{Key: header[i], Value: v})
 			}
 		}
 	}
@@ -1,6 +1,7 @@
 package lookup
 
 import (
+	dbsql "database/sql"
 	"os"
 	"path/filepath"
 	"sync"
@@ -8,12 +9,14 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/require"
+	_ "modernc.org/sqlite"
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/metric"
 	"github.com/influxdata/telegraf/plugins/parsers/influx"
 	"github.com/influxdata/telegraf/plugins/processors"
+	"github.com/influxdata/telegraf/selfstat"
 	"github.com/influxdata/telegraf/testutil"
 )
 
@@ -176,3 +179,205 @@ func TestCasesTracking(t *testing.T) {
 		})
 	}
 }
+
+func TestOnMiss(t *testing.T) {
+	now := time.Now()
+	input := []telegraf.Metric{
+		metric.New("cpu", map[string]string{"host": "Hugin"}, map[string]interface{}{"value": 1}, now),
+		metric.New("cpu", map[string]string{"host": "Odin"}, map[string]interface{}{"value": 2}, now),
+	}
+
+	tests := []struct {
+		name     string
+		onMiss   string
+		defaults map[string]string
+		expected []telegraf.Metric
+	}{
+		{
+			name:   "pass",
+			onMiss: "pass",
+			expected: []telegraf.Metric{
+				metric.New("cpu", map[string]string{"host": "Hugin", "location": "at home", "type": "desktop"}, map[string]interface{}{"value": 1}, now),
+				metric.New("cpu", map[string]string{"host": "Odin"}, map[string]interface{}{"value": 2}, now),
+			},
+		},
+		{
+			name:     "default tags",
+			defaults: map[string]string{"type": "unknown"},
+			expected: []telegraf.Metric{
+				metric.New("cpu", map[string]string{"host": "Hugin", "location": "at home", "type": "desktop"}, map[string]interface{}{"value": 1}, now),
+				metric.New("cpu", map[string]string{"host": "Odin", "type": "unknown"}, map[string]interface{}{"value": 2}, now),
+			},
+		},
+		{
+			name:   "drop",
+			onMiss: "drop",
+			expected: []telegraf.Metric{
+				metric.New("cpu", map[string]string{"host": "Hugin", "location": "at home", "type": "desktop"}, map[string]interface{}{"value": 1}, now),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := &Processor{
+				Filenames:   []string{filepath.Join("testcases", "normal_lookup_json", "lut.json")},
+				KeyTemplate: `{{.Name}}-{{.Tag "host"}}`,
+				OnMiss:      tt.onMiss,
+				DefaultTags: tt.defaults,
+				Statistics:  selfstat.NewCollector(map[string]string{"test": tt.name}),
+				Log:         testutil.Logger{},
+			}
+			require.NoError(t, plugin.Init())
+
+			var in []telegraf.Metric
+			for _, m := range input {
+				in = append(in, m.Copy())
+			}
+			testutil.RequireMetricsEqual(t, tt.expected, plugin.Apply(in...))
+			require.Equal(t, int64(1), plugin.hits.Get())
+			require.Equal(t, int64(1), plugin.misses.Get())
+		})
+	}
+}
+
+func TestReloadFiles(t *testing.T) {
+	fn := filepath.Join(t.TempDir(), "lut.csv")
+	require.NoError(t, os.WriteFile(fn, []byte("Hugin,type,desktop\n"), 0600))
+
+	plugin := &Processor{
+		Filenames:      []string{fn},
+		Fileformat:     "csv_key_name_value",
+		KeyTemplate:    `{{.Tag "host"}}`,
+		ReloadInterval: config.Duration(10 * time.Millisecond),
+		Statistics:     selfstat.NewCollector(map[string]string{"test": t.Name()}),
+		Log:            testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Start(nil))
+	defer plugin.Stop()
+
+	now := time.Now()
+	m := metric.New("cpu", map[string]string{"host": "Hugin"}, map[string]interface{}{"value": 1}, now)
+	expected := metric.New("cpu", map[string]string{"host": "Hugin", "type": "desktop"}, map[string]interface{}{"value": 1}, now)
+	testutil.RequireMetricsEqual(t, []telegraf.Metric{expected}, plugin.Apply(m.Copy()))
+
+	// Unchanged files must not be reloaded
+	time.Sleep(50 * time.Millisecond)
+	require.Zero(t, plugin.reloads.Get())
+
+	// Invalid files must keep the current table
+	require.NoError(t, os.WriteFile(fn, []byte("Hugin,type\n"), 0600))
+	require.NoError(t, os.Chtimes(fn, now, now.Add(time.Minute)))
+	require.Eventually(t, func() bool {
+		return plugin.errors.Get() == 1
+	}, time.Second, time.Millisecond)
+	testutil.RequireMetricsEqual(t, []telegraf.Metric{expected}, plugin.Apply(m.Copy()))
+
+	// Empty files, e.g. truncated for rewriting, must keep the current table
+	require.NoError(t, os.WriteFile(fn, nil, 0600))
+	require.NoError(t, os.Chtimes(fn, now, now.Add(2*time.Minute)))
+	require.Eventually(t, func() bool {
+		return plugin.errors.Get() == 2
+	}, time.Second, time.Millisecond)
+	testutil.RequireMetricsEqual(t, []telegraf.Metric{expected}, plugin.Apply(m.Copy()))
+
+	// Valid changes must replace the table
+	require.NoError(t, os.WriteFile(fn, []byte("Hugin,type,server\n"), 0600))
+	require.NoError(t, os.Chtimes(fn, now, now.Add(3*time.Minute)))
+	require.Eventually(t, func() bool {
+		return plugin.reloads.Get() > 0
+	}, time.Second, time.Millisecond)
+
+	expected = metric.New("cpu", map[string]string{"host": "Hugin", "type": "server"}, map[string]interface{}{"value": 1}, now)
+	testutil.RequireMetricsEqual(t, []telegraf.Metric{expected}, plugin.Apply(m.Copy()))
+}
+
+func TestReloadStop(t *testing.T) {
+	fn := filepath.Join(t.TempDir(), "lut.csv")
+	require.NoError(t, os.WriteFile(fn, []byte("Hugin,type,desktop\n"), 0600))
+
+	plugin := &Processor{
+		Filenames:      []string{fn},
+		Fileformat:     "csv_key_name_value",
+		KeyTemplate:    `{{.Tag "host"}}`,
+		ReloadInterval: config.Duration(time.Millisecond),
+		Statistics:     selfstat.NewCollector(map[string]string{"test": t.Name()}),
+		Log:            testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Start(nil))
+	plugin.Stop()
+
+	// No reload must happen after stopping the plugin
+	require.NoError(t, os.WriteFile(fn, []byte("Hugin,type,server\n"), 0600))
+	require.NoError(t, os.Chtimes(fn, time.Now(), time.Now().Add(time.Minute)))
+	time.Sleep(20 * time.Millisecond)
+	require.Zero(t, plugin.reloads.Get())
+}
+
+func TestSQL(t *testing.T) {
+	// Setup a database with the lookup-table
+	dsn := "file:" + filepath.Join(t.TempDir(), "cmdb.sqlite") + "?_pragma=busy_timeout(5000)"
+	db, err := dbsql.Open("sqlite", dsn)
+	require.NoError(t, err)
+	defer db.Close()
+	_, err = db.Exec("CREATE TABLE hosts (name TEXT, location TEXT, owner TEXT)")
+	require.NoError(t, err)
+	_, err = db.Exec("INSERT INTO hosts VALUES ('Hugin', 'at home', 'alice'), ('Thor', 'eu-west1', NULL)")
+	require.NoError(t, err)
+
+	plugin := &Processor{
+		Driver:      "sqlite",
+		Dsn:         config.NewSecret([]byte(dsn)),
+		Query:       "SELECT name, location, owner FROM hosts",
+		TTL:         config.Duration(10 * time.Millisecond),
+		KeyTemplate: `{{.Tag "host"}}`,
+		Statistics:  selfstat.NewCollector(map[string]string{"test": t.Name()}),
+		Log:         testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Start(nil))
+	defer plugin.Stop()
+
+	now := time.Now()
+	input := []telegraf.Metric{
+		metric.New("cpu", map[string]string{"host": "Hugin"}, map[string]interface{}{"value": 1}, now),
+		metric.New("cpu", map[string]string{"host": "Thor"}, map[string]interface{}{"value": 2}, now),
+	}
+	expected := []telegraf.Metric{
+		metric.New("cpu", map[string]string{"host": "Hugin", "location": "at home", "owner": "alice"}, map[string]interface{}{"value": 1}, now),
+		metric.New("cpu", map[string]string{"host": "Thor", "location": "eu-west1"}, map[string]interface{}{"value": 2}, now),
+	}
+	testutil.RequireMetricsEqual(t, expected, plugin.Apply(input[0].Copy(), input[1].Copy()))
+
+	// Changes must be picked up after the TTL
+	_, err = db.Exec("UPDATE hosts SET owner = 'bob' WHERE name = 'Thor'")
+	require.NoError(t, err)
+	expected[1].AddTag("owner", "bob")
+	require.Eventually(t, func() bool {
+		actual := plugin.Apply(input[0].Copy(), input[1].Copy())
+		return testutil.MetricEqual(expected[1], actual[1])
+	}, time.Second, time.Millisecond)
+}
+
+func TestInitSQL(t *testing.T) {
+	plugin := &Processor{
+		Filenames:   []string{"lut.json"},
+		Driver:      "sqlite",
+		KeyTemplate: "lala",
+	}
+	require.ErrorContains(t, plugin.Init(), "'files' and 'driver' are mutually exclusive")
+
+	plugin = &Processor{
+		Driver:      "sqlite",
+		KeyTemplate: "lala",
+	}
+	require.ErrorContains(t, plugin.Init(), "missing 'query'")
+
+	plugin = &Processor{
+		Driver:      "foo",
+		Query:       "SELECT 1",
+		KeyTemplate: "lala",
+	}
+	require.ErrorContains(t, plugin.Init(), `driver "foo" not supported`)
+}
@@ -0,0 +1,285 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package moving_stats
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"maps"
+	"math"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/filter"
+	"github.com/influxdata/telegraf/internal/choice"
+	"github.com/influxdata/telegraf/plugins/processors"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+// Maximum number of values of a time-based window without size limit
+const maxSamples = 10000
+
+var availableStats = []string{"ewma", "mean", "stddev", "zscore", "min", "max", "count"}
+
+type MovingStats struct {
+	ExpiryInterval config.Duration `toml:"expiry_interval"`
+	Windows        []*window       `toml:"window"`
+	Log            telegraf.Logger `toml:"-"`
+
+	cache map[uint64]*series
+}
+
+type window struct {
+	Fields   []string        `toml:"fields"`
+	Stats    []string        `toml:"stats"`
+	Size     int             `toml:"size"`
+	Duration config.Duration `toml:"duration"`
+	Alpha    float64         `toml:"alpha"`
+
+	accept filter.Filter
+}
+
+type series struct {
+	fields map[string]*samples
+	seen   time.Time
+}
+
+// samples is a ring-buffer of the values in the window of a field
+type samples struct {
+	values []float64
+	times  []time.Time
+	start  int
+	ewma   float64
+}
+
+func (*MovingStats) SampleConfig() string {
+	return sampleConfig
+}
+
+func (p *MovingStats) Init() error {
+	if len(p.Windows) == 0 {
+		return errors.New("no window configured")
+	}
+
+	for i, w := range p.Windows {
+		if len(w.Fields) == 0 {
+			w.Fields = []string{"*"}
+		}
+		f, err := filter.Compile(w.Fields)
+		if err != nil {
+			return fmt.Errorf("failed to create field filter for window %d: %w", i+1, err)
+		}
+		w.accept = f
+
+		if len(w.Stats) == 0 {
+			w.Stats = []string{"mean", "stddev"}
+		}
+		if err := choice.CheckSlice(w.Stats, availableStats); err != nil {
+			return fmt.Errorf("invalid statistic in window %d: %w", i+1, err)
+		}
+
+		if w.Size < 0 || w.Duration < 0 {
+			return fmt.Errorf("negative size or duration for window %d", i+1)
+		}
+		if w.Size == 0 && w.Duration == 0 {
+			return fmt.Errorf("size or duration required for window %d", i+1)
+		}
+		if w.Size == 0 {
+			w.Size = maxSamples
+		}
+
+		if w.Alpha == 0 {
+			w.Alpha = 0.3
+		}
+		if w.Alpha < 0 || w.Alpha > 1 {
+			return fmt.Errorf("alpha of window %d out of range (0, 1]", i+1)
+		}
+	}
+
+	p.cache = make(map[uint64]*series)
+
+	return nil
+}
+
+func (p *MovingStats) Apply(in ...telegraf.Metric) []telegraf.Metric {
+	now := time.Now()
+
+	for _, m := range in {
+		id := m.HashID()
+		s, found := p.cache[id]
+		if !found {
+			s = &series{fields: make(map[string]*samples)}
+			p.cache[id] = s
+		}
+		s.seen = now
+
+		for _, field := range m.FieldList() {
+			w := p.window(field.Key)
+			if w == nil {
+				continue
+			}
+
+			var value float64
+			switch v := field.Value.(type) {
+			case float64:
+				value = v
+			case int64:
+				value = float64(v)
+			case uint64:
+				value = float64(v)
+			default:
+				p.Log.Tracef("Skipping non-numeric field %q with value %v (%T)", field.Key, field.Value, field.Value)
+				continue
+			}
+			if math.IsNaN(value) || math.IsInf(value, 0) {
+				continue
+			}
+
+			buf, found := s.fields[field.Key]
+			if !found {
+				buf = &samples{ewma: value}
+				s.fields[field.Key] = buf
+			}
+			w.add(m, field.Key, buf, value)
+		}
+	}
+
+	// Cleanup cache entries that are too old
+	if p.ExpiryInterval > 0 {
+		threshold := now.Add(-time.Duration(p.ExpiryInterval))
+		maps.DeleteFunc(p.cache, func(_ uint64, s *series) bool {
+			return s.seen.Before(threshold)
+		})
+	}
+
+	return in
+}
+
+// window returns the first window handling the given field or nil
+func (p *MovingStats) window(field string) *window {
+	for _, w := range p.Windows {
+		if w.accept.Match(field) {
+			return w
+		}
+	}
+	return nil
+}
+
+// add puts the value into the window and annotates the metric with the
+// configured statistics
+func (w *window) add(m telegraf.Metric, key string, buf *samples, value float64) {
+	ts := m.Time()
+
+	// Remove values outside of the window
+	if w.Duration > 0 {
+		threshold := ts.Add(-time.Duration(w.Duration))
+		for buf.len() > 0 && !buf.times[buf.start].After(threshold) {
+			buf.pop()
+		}
+	}
+	for buf.len() >= w.Size {
+		buf.pop()
+	}
+
+	// The z-score uses the statistics of the preceding values to not dilute
+	// outliers by the value itself
+	prevMean, prevStddev := buf.meanStddev()
+	prevCount := buf.len()
+
+	buf.push(value, ts)
+	buf.ewma = w.Alpha*value + (1-w.Alpha)*buf.ewma
+
+	mean, stddev := buf.meanStddev()
+	for _, stat := range w.Stats {
+		switch stat {
+		case "ewma":
+			m.AddField(key+"_ewma", buf.ewma)
+		case "mean":
+			m.AddField(key+"_mean", mean)
+		case "stddev":
+			if buf.len() > 1 {
+				m.AddField(key+"_stddev", stddev)
+			}
+		case "zscore":
+			if prevCount > 1 && prevStddev > 0 {
+				m.AddField(key+"_zscore", (value-prevMean)/prevStddev)
+			}
+		case "min":
+			m.AddField(key+"_min", buf.min())
+		case "max":
+			m.AddField(key+"_max", buf.max())
+		case "count":
+			m.AddField(key+"_count", int64(buf.len()))
+		}
+	}
+}
+
+func (s *samples) len() int {
+	return len(s.values) - s.start
+}
+
+func (s *samples) push(value float64, ts time.Time) {
+	// Compact the buffer to keep the memory bounded
+	if s.start > 0 && s.start >= len(s.values)/2 {
+		s.values = append(s.values[:0], s.values[s.start:]...)
+		s.times = append(s.times[:0], s.times[s.start:]...)
+		s.start = 0
+	}
+	s.values = append(s.values, value)
+	s.times = append(s.times, ts)
+}
+
+func (s *samples) pop() {
+	s.start++
+}
+
+// meanStddev computes the mean and the sample standard deviation of the
+// values in the window
+func (s *samples) meanStddev() (mean, stddev float64) {
+	n := s.len()
+	if n == 0 {
+		return 0, 0
+	}
+
+	var sum float64
+	for _, v := range s.values[s.start:] {
+		sum += v
+	}
+	mean = sum / float64(n)
+	if n == 1 {
+		return mean, 0
+	}
+
+	var m2 float64
+	for _, v := range s.values[s.start:] {
+		m2 += (v - mean) * (v - mean)
+	}
+	return mean, math.Sqrt(m2 / float64(n-1))
+}
+
+func (s *samples) min() float64 {
+	result := math.Inf(1)
+	for _, v := range s.values[s.start:] {
+		result = math.Min(result, v)
+	}
+	return result
+}
+
+func (s *samples) max() float64 {
+	result := math.Inf(-1)
+	for _, v := range s.values[s.start:] {
+		result = math.Max(result, v)
+	}
+	return result
+}
+
+func init() {
+	processors.Add("moving_stats", func() telegraf.Processor {
+		return &MovingStats{
+			ExpiryInterval: config.Duration(time.Hour),
+		}
+	})
+}
@@ -0,0 +1,183 @@
+package moving_stats
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInitInvalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		windows  []*window
+		expected string
+	}{
+		{
+			name:     "no window",
+			expected: "no window configured",
+		},
+		{
+			name:     "no size",
+			windows:  []*window{{}},
+			expected: "size or duration required for window 1",
+		},
+		{
+			name:     "invalid statistic",
+			windows:  []*window{{Size: 5, Stats: []string{"median"}}},
+			expected: "invalid statistic in window 1",
+		},
+		{
+			name:     "invalid alpha",
+			windows:  []*window{{Size: 5, Alpha: 1.5}},
+			expected: "alpha of window 1 out of range",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := &MovingStats{Windows: tt.windows, Log: testutil.Logger{}}
+			require.ErrorContains(t, plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestCountWindow(t *testing.T) {
+	plugin := &MovingStats{
+		Windows: []*window{
+			{
+				Fields: []string{"value"},
+				Stats:  []string{"ewma", "mean", "stddev", "min", "max", "count"},
+				Size:   3,
+				Alpha:  0.5,
+			},
+		},
+		Log: testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	start := time.Unix(1700000000, 0)
+	var actual []telegraf.Metric
+	for i, v := range []float64{2, 4, 6, 8} {
+		m := metric.New("test", map[string]string{"host": "a"}, map[string]interface{}{"value": v, "other": 1}, start.Add(time.Duration(i)*time.Second))
+		actual = append(actual, plugin.Apply(m)...)
+	}
+
+	expected := []telegraf.Metric{
+		metric.New("test", map[string]string{"host": "a"}, map[string]interface{}{
+			"value": 2.0, "other": 1, "value_ewma": 2.0, "value_mean": 2.0, "value_min": 2.0, "value_max": 2.0, "value_count": int64(1),
+		}, start),
+		metric.New("test", map[string]string{"host": "a"}, map[string]interface{}{
+			"value": 4.0, "other": 1, "value_ewma": 3.0, "value_mean": 3.0, "value_stddev": math.Sqrt(2),
+			"value_min": 2.0, "value_max": 4.0, "value_count": int64(2),
+		}, start.Add(time.Second)),
+		metric.New("test", map[string]string{"host": "a"}, map[string]interface{}{
+			"value": 6.0, "other": 1, "value_ewma": 4.5, "value_mean": 4.0, "value_stddev": 2.0,
+			"value_min": 2.0, "value_max": 6.0, "value_count": int64(3),
+		}, start.Add(2*time.Second)),
+		metric.New("test", map[string]string{"host": "a"}, map[string]interface{}{
+			"value": 8.0, "other": 1, "value_ewma": 6.25, "value_mean": 6.0, "value_stddev": 2.0,
+			"value_min": 4.0, "value_max": 8.0, "value_count": int64(3),
+		}, start.Add(3*time.Second)),
+	}
+	testutil.RequireMetricsEqual(t, expected, actual, cmpopts.EquateApprox(0, 1e-9))
+}
+
+func TestTimeWindow(t *testing.T) {
+	plugin := &MovingStats{
+		Windows: []*window{
+			{
+				Stats:    []string{"count", "min"},
+				Duration: config.Duration(10 * time.Second),
+			},
+		},
+		Log: testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	start := time.Unix(1700000000, 0)
+	var counts []int64
+	var minimums []float64
+	for _, offset := range []int{0, 5, 9, 10, 30} {
+		m := metric.New("test", nil, map[string]interface{}{"value": int64(offset)}, start.Add(time.Duration(offset)*time.Second))
+		out := plugin.Apply(m)
+		require.Len(t, out, 1)
+		c, found := out[0].GetField("value_count")
+		require.True(t, found)
+		counts = append(counts, c.(int64))
+		v, found := out[0].GetField("value_min")
+		require.True(t, found)
+		minimums = append(minimums, v.(float64))
+	}
+	require.Equal(t, []int64{1, 2, 3, 3, 1}, counts)
+	require.Equal(t, []float64{0, 0, 0, 5, 30}, minimums)
+}
+
+func TestZScore(t *testing.T) {
+	plugin := &MovingStats{
+		Windows: []*window{{Stats: []string{"zscore"}, Size: 10}},
+		Log:     testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	now := time.Now()
+	var last telegraf.Metric
+	for _, v := range []float64{9, 10, 11, 10, 20} {
+		out := plugin.Apply(metric.New("test", nil, map[string]interface{}{"value": v}, now))
+		last = out[0]
+	}
+
+	// Mean and standard deviation of the preceding values are 10 and
+	// sqrt(2/3)
+	z, found := last.GetField("value_zscore")
+	require.True(t, found)
+	require.InDelta(t, 10/math.Sqrt(2.0/3.0), z, 1e-9)
+}
+
+func TestSeriesAndWindowSelection(t *testing.T) {
+	plugin := &MovingStats{
+		Windows: []*window{
+			{Fields: []string{"a"}, Stats: []string{"count"}, Size: 5},
+			{Fields: []string{"*"}, Stats: []string{"max"}, Size: 5},
+		},
+		Log: testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	now := time.Now()
+	plugin.Apply(metric.New("test", map[string]string{"host": "a"}, map[string]interface{}{"a": 1, "b": 5}, now))
+	actual := plugin.Apply(
+		metric.New("test", map[string]string{"host": "a"}, map[string]interface{}{"a": 1, "b": 3, "c": "text"}, now),
+		metric.New("test", map[string]string{"host": "b"}, map[string]interface{}{"a": 1, "b": 3}, now),
+	)
+
+	expected := []telegraf.Metric{
+		metric.New("test", map[string]string{"host": "a"}, map[string]interface{}{
+			"a": 1, "b": 3, "c": "text", "a_count": int64(2), "b_max": 5.0,
+		}, now),
+		metric.New("test", map[string]string{"host": "b"}, map[string]interface{}{
+			"a": 1, "b": 3, "a_count": int64(1), "b_max": 3.0,
+		}, now),
+	}
+	testutil.RequireMetricsEqual(t, expected, actual)
+}
+
+func TestExpiry(t *testing.T) {
+	plugin := &MovingStats{
+		ExpiryInterval: config.Duration(time.Nanosecond),
+		Windows:        []*window{{Size: 5}},
+		Log:            testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	plugin.Apply(metric.New("test", nil, map[string]interface{}{"value": 1}, time.Now()))
+	time.Sleep(time.Millisecond)
+	plugin.Apply(metric.New("other", nil, map[string]interface{}{"value": 1}, time.Now()))
+	require.Len(t, plugin.cache, 1)
+}
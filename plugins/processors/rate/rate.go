@@ -0,0 +1,201 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package rate
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"maps"
+	"math"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/filter"
+	"github.com/influxdata/telegraf/plugins/processors"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type Rate struct {
+	Fields         []string        `toml:"fields"`
+	Suffix         *string         `toml:"suffix"`
+	CounterWidth   int             `toml:"counter_width"`
+	ExpiryInterval config.Duration `toml:"expiry_interval"`
+	Log            telegraf.Logger `toml:"-"`
+
+	suffix string
+	limit  float64
+	accept filter.Filter
+	cache  map[uint64]*series
+}
+
+// series contains the last values of a series and is persisted as state
+type series struct {
+	Values map[string]float64 `json:"values"`
+	Time   time.Time          `json:"time"`
+	seen   time.Time
+}
+
+func (*Rate) SampleConfig() string {
+	return sampleConfig
+}
+
+func (r *Rate) Init() error {
+	if len(r.Fields) == 0 {
+		r.Fields = []string{"*"}
+	}
+	f, err := filter.Compile(r.Fields)
+	if err != nil {
+		return fmt.Errorf("failed to create new field filter: %w", err)
+	}
+	r.accept = f
+
+	r.suffix = "_rate"
+	if r.Suffix != nil {
+		r.suffix = *r.Suffix
+	}
+
+	switch r.CounterWidth {
+	case 0:
+	case 32, 64:
+		r.limit = math.Pow(2, float64(r.CounterWidth))
+	default:
+		return fmt.Errorf("invalid counter width %d", r.CounterWidth)
+	}
+
+	r.cache = make(map[uint64]*series)
+
+	return nil
+}
+
+func (r *Rate) GetState() interface{} {
+	return r.cache
+}
+
+func (r *Rate) SetState(state interface{}) error {
+	cache, ok := state.(map[uint64]*series)
+	if !ok {
+		return errors.New("state has to be of type 'map[uint64]*series'")
+	}
+
+	// Consider restored series as seen now to not expire them immediately
+	now := time.Now()
+	for id, s := range cache {
+		if s == nil || s.Values == nil {
+			continue
+		}
+		s.seen = now
+		r.cache[id] = s
+	}
+	return nil
+}
+
+func (r *Rate) Apply(in ...telegraf.Metric) []telegraf.Metric {
+	now := time.Now()
+
+	out := make([]telegraf.Metric, 0, len(in))
+	for _, m := range in {
+		id := m.HashID()
+		ts := m.Time()
+
+		// Create a new entry for unseen series
+		stored, found := r.cache[id]
+		if !found {
+			stored = &series{Values: make(map[string]float64)}
+			r.cache[id] = stored
+		}
+		stored.seen = now
+
+		// Metrics not newer than the stored values cannot produce a rate and
+		// must not overwrite the stored values
+		elapsed := ts.Sub(stored.Time).Seconds()
+		outdated := found && elapsed <= 0
+		if outdated {
+			r.Log.Tracef("Metric %q with timestamp %v is not newer than the last one at %v", m.Name(), ts, stored.Time)
+		}
+
+		var remove []string
+		for _, field := range m.FieldList() {
+			if !r.accept.Match(field.Key) {
+				continue
+			}
+
+			var value float64
+			switch v := field.Value.(type) {
+			case float64:
+				value = v
+			case int64:
+				value = float64(v)
+			case uint64:
+				value = float64(v)
+			default:
+				r.Log.Tracef("Skipping non-numeric field %q with value %v (%T)", field.Key, field.Value, field.Value)
+				continue
+			}
+
+			// Without a previous value we cannot compute a rate so remove the
+			// counter if it should be replaced
+			last, hasLast := stored.Values[field.Key]
+			if outdated || !hasLast {
+				if !outdated {
+					stored.Values[field.Key] = value
+				}
+				if r.suffix == "" {
+					remove = append(remove, field.Key)
+				}
+				continue
+			}
+			stored.Values[field.Key] = value
+
+			m.AddField(field.Key+r.suffix, r.delta(last, value)/elapsed)
+		}
+		if !outdated {
+			stored.Time = ts
+		}
+
+		for _, key := range remove {
+			m.RemoveField(key)
+		}
+		if len(m.FieldList()) == 0 {
+			m.Drop()
+			continue
+		}
+		out = append(out, m)
+	}
+
+	// Cleanup cache entries that are too old
+	if r.ExpiryInterval > 0 {
+		threshold := now.Add(-time.Duration(r.ExpiryInterval))
+		maps.DeleteFunc(r.cache, func(_ uint64, s *series) bool {
+			return s.seen.Before(threshold)
+		})
+	}
+
+	return out
+}
+
+// delta computes the increase of a counter taking wraps and resets into account
+func (r *Rate) delta(last, value float64) float64 {
+	if value >= last {
+		return value - last
+	}
+
+	// Check if the counter wrapped, i.e. the increase across the wrap is
+	// small compared to the counter range. Otherwise assume the counter was
+	// reset and restarted from zero.
+	if r.limit > 0 && last < r.limit {
+		if wrapped := r.limit - last + value; wrapped < r.limit/2 {
+			return wrapped
+		}
+	}
+	r.Log.Debugf("Counter reset detected from %v to %v", last, value)
+	return value
+}
+
+func init() {
+	processors.Add("rate", func() telegraf.Processor {
+		return &Rate{}
+	})
+}
@@ -0,0 +1,192 @@
+package rate
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestApply(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+	tags := map[string]string{"interface": "eth0"}
+
+	tests := []struct {
+		name     string
+		suffix   *string
+		width    int
+		input    []telegraf.Metric
+		expected []telegraf.Metric
+	}{
+		{
+			name: "increasing counter",
+			input: []telegraf.Metric{
+				metric.New("net", tags, map[string]interface{}{"bytes": int64(1000), "state": "up"}, start),
+				metric.New("net", tags, map[string]interface{}{"bytes": int64(3000), "state": "up"}, start.Add(10*time.Second)),
+				metric.New("net", tags, map[string]interface{}{"bytes": uint64(3500), "state": "up"}, start.Add(20*time.Second)),
+			},
+			expected: []telegraf.Metric{
+				metric.New("net", tags, map[string]interface{}{"bytes": int64(1000), "state": "up"}, start),
+				metric.New("net", tags, map[string]interface{}{"bytes": int64(3000), "bytes_rate": float64(200), "state": "up"}, start.Add(10*time.Second)),
+				metric.New("net", tags, map[string]interface{}{"bytes": uint64(3500), "bytes_rate": float64(50), "state": "up"}, start.Add(20*time.Second)),
+			},
+		},
+		{
+			name: "counter reset",
+			input: []telegraf.Metric{
+				metric.New("net", tags, map[string]interface{}{"bytes": int64(1000)}, start),
+				metric.New("net", tags, map[string]interface{}{"bytes": int64(500)}, start.Add(10*time.Second)),
+			},
+			expected: []telegraf.Metric{
+				metric.New("net", tags, map[string]interface{}{"bytes": int64(1000)}, start),
+				metric.New("net", tags, map[string]interface{}{"bytes": int64(500), "bytes_rate": float64(50)}, start.Add(10*time.Second)),
+			},
+		},
+		{
+			name:  "32-bit wrap",
+			width: 32,
+			input: []telegraf.Metric{
+				metric.New("net", tags, map[string]interface{}{"bytes": uint64(4294967000)}, start),
+				metric.New("net", tags, map[string]interface{}{"bytes": uint64(704)}, start.Add(10*time.Second)),
+			},
+			expected: []telegraf.Metric{
+				metric.New("net", tags, map[string]interface{}{"bytes": uint64(4294967000)}, start),
+				metric.New("net", tags, map[string]interface{}{"bytes": uint64(704), "bytes_rate": float64(100)}, start.Add(10*time.Second)),
+			},
+		},
+		{
+			name:  "reset with counter width",
+			width: 32,
+			input: []telegraf.Metric{
+				metric.New("net", tags, map[string]interface{}{"bytes": uint64(1000000)}, start),
+				metric.New("net", tags, map[string]interface{}{"bytes": uint64(100)}, start.Add(10*time.Second)),
+			},
+			expected: []telegraf.Metric{
+				metric.New("net", tags, map[string]interface{}{"bytes": uint64(1000000)}, start),
+				metric.New("net", tags, map[string]interface{}{"bytes": uint64(100), "bytes_rate": float64(10)}, start.Add(10*time.Second)),
+			},
+		},
+		{
+			name:   "replace counters",
+			suffix: new(string),
+			input: []telegraf.Metric{
+				metric.New("net", tags, map[string]interface{}{"bytes": int64(1000)}, start),
+				metric.New("net", tags, map[string]interface{}{"bytes": int64(3000), "state": "up"}, start.Add(10*time.Second)),
+				metric.New("net", tags, map[string]interface{}{"bytes": int64(2000), "state": "up"}, start.Add(5*time.Second)),
+			},
+			expected: []telegraf.Metric{
+				metric.New("net", tags, map[string]interface{}{"bytes": float64(200), "state": "up"}, start.Add(10*time.Second)),
+				metric.New("net", tags, map[string]interface{}{"state": "up"}, start.Add(5*time.Second)),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := &Rate{
+				Suffix:       tt.suffix,
+				CounterWidth: tt.width,
+				Log:          &testutil.Logger{},
+			}
+			require.NoError(t, plugin.Init())
+
+			var actual []telegraf.Metric
+			for _, m := range tt.input {
+				actual = append(actual, plugin.Apply(m)...)
+			}
+			testutil.RequireMetricsEqual(t, tt.expected, actual)
+		})
+	}
+}
+
+func TestFieldFilter(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+	plugin := &Rate{
+		Fields: []string{"bytes_*"},
+		Log:    &testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	input := []telegraf.Metric{
+		metric.New("net", map[string]string{}, map[string]interface{}{"bytes_recv": 10, "errors": 1}, start),
+		metric.New("net", map[string]string{}, map[string]interface{}{"bytes_recv": 20, "errors": 3}, start.Add(time.Second)),
+	}
+	expected := []telegraf.Metric{
+		metric.New("net", map[string]string{}, map[string]interface{}{"bytes_recv": 10, "errors": 1}, start),
+		metric.New("net", map[string]string{}, map[string]interface{}{"bytes_recv": 20, "bytes_recv_rate": float64(10), "errors": 3}, start.Add(time.Second)),
+	}
+	testutil.RequireMetricsEqual(t, expected, plugin.Apply(input...))
+}
+
+func TestInvalidCounterWidth(t *testing.T) {
+	plugin := &Rate{CounterWidth: 16}
+	require.ErrorContains(t, plugin.Init(), "invalid counter width")
+}
+
+func TestExpiry(t *testing.T) {
+	start := time.Now()
+	plugin := &Rate{
+		ExpiryInterval: config.Duration(time.Minute),
+		Log:            &testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	plugin.Apply(metric.New("net", map[string]string{"interface": "eth0"}, map[string]interface{}{"bytes": 1}, start))
+	require.Len(t, plugin.cache, 1)
+
+	// Age the entry and trigger the cleanup with another series
+	for _, s := range plugin.cache {
+		s.seen = start.Add(-2 * time.Minute)
+	}
+	plugin.Apply(metric.New("net", map[string]string{"interface": "eth1"}, map[string]interface{}{"bytes": 1}, start))
+	require.Len(t, plugin.cache, 1)
+	require.NotContains(t, plugin.cache, metric.New("net", map[string]string{"interface": "eth0"}, nil, start).HashID())
+}
+
+func TestState(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+	tags := map[string]string{"interface": "eth0"}
+
+	plugin := &Rate{Log: &testutil.Logger{}}
+	require.NoError(t, plugin.Init())
+	plugin.Apply(metric.New("net", tags, map[string]interface{}{"bytes": 1000}, start))
+
+	// Serialize and restore the state the same way the persister does
+	serialized, err := json.Marshal(plugin.GetState())
+	require.NoError(t, err)
+	var state map[uint64]*series
+	require.NoError(t, json.Unmarshal(serialized, &state))
+
+	restored := &Rate{Log: &testutil.Logger{}}
+	require.NoError(t, restored.Init())
+	require.NoError(t, restored.SetState(state))
+
+	// The first metric after the restart must produce a rate
+	expected := []telegraf.Metric{
+		metric.New("net", tags, map[string]interface{}{"bytes": 2000, "bytes_rate": float64(100)}, start.Add(10*time.Second)),
+	}
+	actual := restored.Apply(metric.New("net", tags, map[string]interface{}{"bytes": 2000}, start.Add(10*time.Second)))
+	testutil.RequireMetricsEqual(t, expected, actual)
+
+	require.ErrorContains(t, restored.SetState("foo"), "state has to be of type")
+}
+
+func TestTracking(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+	plugin := &Rate{Suffix: new(string), Log: &testutil.Logger{}}
+	require.NoError(t, plugin.Init())
+
+	var delivered int
+	notify := func(telegraf.DeliveryInfo) { delivered++ }
+	m, _ := metric.WithTracking(metric.New("net", map[string]string{}, map[string]interface{}{"bytes": 1}, start), notify)
+
+	// The metric is dropped as it only contains the first counter value
+	require.Empty(t, plugin.Apply(m))
+	require.Equal(t, 1, delivered)
+}
@@ -0,0 +1,167 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package relabel
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/model/relabel"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/processors"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type Relabel struct {
+	Rules []*rule         `toml:"rule"`
+	Log   telegraf.Logger `toml:"-"`
+
+	configs []*relabel.Config
+	builder *labels.Builder
+}
+
+// rule mirrors the Prometheus relabel config. Pointers are used to
+// distinguish unset options, which use the Prometheus defaults, from empty
+// values.
+type rule struct {
+	Action       string   `toml:"action"`
+	SourceLabels []string `toml:"source_labels"`
+	Separator    *string  `toml:"separator"`
+	Regex        *string  `toml:"regex"`
+	Modulus      uint64   `toml:"modulus"`
+	TargetLabel  string   `toml:"target_label"`
+	Replacement  *string  `toml:"replacement"`
+}
+
+var actions = []relabel.Action{
+	relabel.Replace,
+	relabel.Keep,
+	relabel.Drop,
+	relabel.KeepEqual,
+	relabel.DropEqual,
+	relabel.HashMod,
+	relabel.LabelMap,
+	relabel.LabelDrop,
+	relabel.LabelKeep,
+	relabel.Lowercase,
+	relabel.Uppercase,
+}
+
+func (*Relabel) SampleConfig() string {
+	return sampleConfig
+}
+
+func (r *Relabel) Init() error {
+	if len(r.Rules) == 0 {
+		return errors.New("no rules configured")
+	}
+
+	r.configs = make([]*relabel.Config, 0, len(r.Rules))
+	for i, rl := range r.Rules {
+		cfg, err := rl.config()
+		if err != nil {
+			return fmt.Errorf("rule %d: %w", i+1, err)
+		}
+		r.configs = append(r.configs, cfg)
+	}
+	r.builder = labels.NewBuilder(labels.EmptyLabels())
+
+	return nil
+}
+
+func (r *Relabel) Apply(in ...telegraf.Metric) []telegraf.Metric {
+	out := make([]telegraf.Metric, 0, len(in))
+	for _, m := range in {
+		r.builder.Reset(labels.EmptyLabels())
+		for _, tag := range m.TagList() {
+			r.builder.Set(tag.Key, tag.Value)
+		}
+		r.builder.Set(model.MetricNameLabel, m.Name())
+
+		if !relabel.ProcessBuilder(r.builder, r.configs...) {
+			m.Drop()
+			continue
+		}
+
+		name := r.builder.Get(model.MetricNameLabel)
+		if name == "" {
+			r.Log.Debugf("Dropping metric %q without name after relabeling", m.Name())
+			m.Drop()
+			continue
+		}
+		m.SetName(name)
+
+		// Replace the tags by the resulting labels
+		var remove []string
+		for _, tag := range m.TagList() {
+			if r.builder.Get(tag.Key) == "" {
+				remove = append(remove, tag.Key)
+			}
+		}
+		for _, key := range remove {
+			m.RemoveTag(key)
+		}
+		r.builder.Range(func(l labels.Label) {
+			if l.Name != model.MetricNameLabel {
+				m.AddTag(l.Name, l.Value)
+			}
+		})
+
+		out = append(out, m)
+	}
+	return out
+}
+
+// config converts the rule to a Prometheus relabel config applying the
+// Prometheus defaults for unset options
+func (rl *rule) config() (*relabel.Config, error) {
+	cfg := relabel.DefaultRelabelConfig
+	cfg.NameValidationScheme = model.UTF8Validation
+
+	if rl.Action != "" {
+		action := relabel.Action(strings.ToLower(rl.Action))
+		if !slices.Contains(actions, action) {
+			return nil, fmt.Errorf("unknown relabel action %q", rl.Action)
+		}
+		cfg.Action = action
+	}
+	if len(rl.SourceLabels) > 0 {
+		cfg.SourceLabels = make(model.LabelNames, 0, len(rl.SourceLabels))
+		for _, l := range rl.SourceLabels {
+			cfg.SourceLabels = append(cfg.SourceLabels, model.LabelName(l))
+		}
+	}
+	if rl.Separator != nil {
+		cfg.Separator = *rl.Separator
+	}
+	if rl.Regex != nil {
+		re, err := relabel.NewRegexp(*rl.Regex)
+		if err != nil {
+			return nil, fmt.Errorf("compiling regex failed: %w", err)
+		}
+		cfg.Regex = re
+	}
+	cfg.Modulus = rl.Modulus
+	cfg.TargetLabel = rl.TargetLabel
+	if rl.Replacement != nil {
+		cfg.Replacement = *rl.Replacement
+	}
+
+	if err := cfg.Validate(model.UTF8Validation); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+func init() {
+	processors.Add("relabel", func() telegraf.Processor {
+		return &Relabel{}
+	})
+}
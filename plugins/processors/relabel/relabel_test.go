@@ -0,0 +1,333 @@
+package relabel
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func str(s string) *string {
+	return &s
+}
+
+func TestInitInvalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		rules    []*rule
+		expected string
+	}{
+		{
+			name:     "no rules",
+			expected: "no rules configured",
+		},
+		{
+			name:     "unknown action",
+			rules:    []*rule{{Action: "rename"}},
+			expected: `rule 1: unknown relabel action "rename"`,
+		},
+		{
+			name:     "invalid regex",
+			rules:    []*rule{{Action: "keep", Regex: str("(")}},
+			expected: "rule 1: compiling regex failed",
+		},
+		{
+			name:     "replace without target",
+			rules:    []*rule{{SourceLabels: []string{"a"}}},
+			expected: "rule 1: relabel configuration for replace action requires 'target_label' value",
+		},
+		{
+			name:     "hashmod without modulus",
+			rules:    []*rule{{Action: "hashmod", SourceLabels: []string{"a"}, TargetLabel: "b"}},
+			expected: "rule 1: relabel configuration for hashmod requires non-zero modulus",
+		},
+		{
+			name:     "labeldrop with target",
+			rules:    []*rule{{Action: "labeldrop", Regex: str("a"), TargetLabel: "b"}},
+			expected: "rule 1: labeldrop action requires only 'regex', and no other fields",
+		},
+		{
+			name:     "keepequal with regex",
+			rules:    []*rule{{Action: "keepequal", SourceLabels: []string{"a"}, TargetLabel: "b", Regex: str("(.*)")}},
+			expected: "rule 1: keepequal action requires only 'source_labels' and `target_label`, and no other fields",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := &Relabel{Rules: tt.rules, Log: testutil.Logger{}}
+			require.ErrorContains(t, plugin.Init(), tt.expected)
+		})
+	}
+}
+
+// The test vectors are ported from the Prometheus relabel tests with the
+// labels used as tags of a "test" metric
+func TestRelabel(t *testing.T) {
+	tests := []struct {
+		name     string
+		rules    []*rule
+		input    map[string]string
+		expected map[string]string
+		drop     bool
+	}{
+		{
+			name: "replace with capture group",
+			rules: []*rule{
+				{SourceLabels: []string{"a"}, Regex: str("f(.*)"), TargetLabel: "d", Replacement: str("ch${1}-ch${1}")},
+			},
+			input:    map[string]string{"a": "foo", "b": "bar", "c": "baz"},
+			expected: map[string]string{"a": "foo", "b": "bar", "c": "baz", "d": "choo-choo"},
+		},
+		{
+			name: "replace chained with multiple source labels",
+			rules: []*rule{
+				{SourceLabels: []string{"a", "b"}, Regex: str("f(.*);(.*)r"), TargetLabel: "a", Replacement: str("b${1}${2}m")},
+				{SourceLabels: []string{"c", "a"}, Regex: str("(b).*b(.*)ba(.*)"), TargetLabel: "d", Replacement: str("$1$2$2$3")},
+			},
+			input:    map[string]string{"a": "foo", "b": "bar", "c": "baz"},
+			expected: map[string]string{"a": "boobam", "b": "bar", "c": "baz", "d": "boooom"},
+		},
+		{
+			name: "drop stops processing",
+			rules: []*rule{
+				{Action: "drop", SourceLabels: []string{"a"}, Regex: str(".*o.*")},
+				{SourceLabels: []string{"a"}, Regex: str("f(.*)"), TargetLabel: "d", Replacement: str("ch$1-ch$1")},
+			},
+			input: map[string]string{"a": "foo"},
+			drop:  true,
+		},
+		{
+			name: "regex is anchored",
+			rules: []*rule{
+				{SourceLabels: []string{"a"}, Regex: str(".*(b).*"), TargetLabel: "d"},
+			},
+			input:    map[string]string{"a": "abc"},
+			expected: map[string]string{"a": "abc", "d": "b"},
+		},
+		{
+			name: "no match does not replace",
+			rules: []*rule{
+				{SourceLabels: []string{"a"}, Regex: str("no-match"), TargetLabel: "d"},
+			},
+			input:    map[string]string{"a": "foo"},
+			expected: map[string]string{"a": "foo"},
+		},
+		{
+			name: "keep non-matching",
+			rules: []*rule{
+				{Action: "keep", SourceLabels: []string{"a"}, Regex: str("f")},
+			},
+			input: map[string]string{"a": "foo"},
+			drop:  true,
+		},
+		{
+			name: "keep matching",
+			rules: []*rule{
+				{Action: "keep", SourceLabels: []string{"a"}, Regex: str("foo")},
+			},
+			input:    map[string]string{"a": "foo"},
+			expected: map[string]string{"a": "foo"},
+		},
+		{
+			name: "replace with empty value removes label",
+			rules: []*rule{
+				{SourceLabels: []string{"a"}, Regex: str("f(.*)"), TargetLabel: "b", Replacement: str("")},
+			},
+			input:    map[string]string{"a": "foo", "b": "bar"},
+			expected: map[string]string{"a": "foo"},
+		},
+		{
+			name: "replace from missing label",
+			rules: []*rule{
+				{SourceLabels: []string{"x"}, TargetLabel: "b", Replacement: str("static")},
+			},
+			input:    map[string]string{"a": "foo"},
+			expected: map[string]string{"a": "foo", "b": "static"},
+		},
+		{
+			name: "replace with target label from capture group",
+			rules: []*rule{
+				{SourceLabels: []string{"a"}, Regex: str("some-([^-]+)-([^,]+)"), TargetLabel: "${1}", Replacement: str("${2}")},
+			},
+			input:    map[string]string{"a": "some-name-value"},
+			expected: map[string]string{"a": "some-name-value", "name": "value"},
+		},
+		{
+			name: "hashmod",
+			rules: []*rule{
+				{Action: "hashmod", SourceLabels: []string{"c"}, TargetLabel: "d", Modulus: 1000},
+			},
+			input:    map[string]string{"a": "foo", "b": "bar", "c": "baz"},
+			expected: map[string]string{"a": "foo", "b": "bar", "c": "baz", "d": "976"},
+		},
+		{
+			name: "hashmod with newline",
+			rules: []*rule{
+				{Action: "hashmod", SourceLabels: []string{"a"}, TargetLabel: "b", Modulus: 1000},
+			},
+			input:    map[string]string{"a": "foo\nbar"},
+			expected: map[string]string{"a": "foo\nbar", "b": "734"},
+		},
+		{
+			name: "labelmap",
+			rules: []*rule{
+				{Action: "labelmap", Regex: str("(b.*)"), Replacement: str("bar_${1}")},
+			},
+			input:    map[string]string{"a": "foo", "b1": "bar", "b2": "baz"},
+			expected: map[string]string{"a": "foo", "b1": "bar", "b2": "baz", "bar_b1": "bar", "bar_b2": "baz"},
+		},
+		{
+			name: "labelmap with meta labels",
+			rules: []*rule{
+				{Action: "labelmap", Regex: str("__meta_(my.*)"), Replacement: str("${1}")},
+			},
+			input: map[string]string{"a": "foo", "__meta_my_bar": "aaa", "__meta_my_baz": "bbb", "__meta_other": "ccc"},
+			expected: map[string]string{
+				"a":             "foo",
+				"__meta_my_bar": "aaa",
+				"__meta_my_baz": "bbb",
+				"__meta_other":  "ccc",
+				"my_bar":        "aaa",
+				"my_baz":        "bbb",
+			},
+		},
+		{
+			name: "labeldrop",
+			rules: []*rule{
+				{Action: "labeldrop", Regex: str("b.*")},
+			},
+			input:    map[string]string{"a": "foo", "b1": "bar", "b2": "baz"},
+			expected: map[string]string{"a": "foo"},
+		},
+		{
+			name: "labelkeep",
+			rules: []*rule{
+				{Action: "labelkeep", Regex: str("b.*|__name__")},
+			},
+			input:    map[string]string{"a": "foo", "b1": "bar", "b2": "baz"},
+			expected: map[string]string{"b1": "bar", "b2": "baz"},
+		},
+		{
+			name: "lowercase and uppercase",
+			rules: []*rule{
+				{Action: "uppercase", SourceLabels: []string{"foo"}, TargetLabel: "foo_uppercase"},
+				{Action: "lowercase", SourceLabels: []string{"foo"}, TargetLabel: "foo_lowercase"},
+			},
+			input:    map[string]string{"foo": "bAr123Foo"},
+			expected: map[string]string{"foo": "bAr123Foo", "foo_lowercase": "bar123foo", "foo_uppercase": "BAR123FOO"},
+		},
+		{
+			name: "keepequal",
+			rules: []*rule{
+				{Action: "keepequal", SourceLabels: []string{"__tmp_port"}, TargetLabel: "__port1"},
+			},
+			input:    map[string]string{"__tmp_port": "1234", "__port1": "1234", "__port2": "5678"},
+			expected: map[string]string{"__tmp_port": "1234", "__port1": "1234", "__port2": "5678"},
+		},
+		{
+			name: "keepequal not equal",
+			rules: []*rule{
+				{Action: "keepequal", SourceLabels: []string{"__tmp_port"}, TargetLabel: "__port2"},
+			},
+			input: map[string]string{"__tmp_port": "1234", "__port1": "1234", "__port2": "5678"},
+			drop:  true,
+		},
+		{
+			name: "dropequal",
+			rules: []*rule{
+				{Action: "dropequal", SourceLabels: []string{"__tmp_port"}, TargetLabel: "__port1"},
+			},
+			input: map[string]string{"__tmp_port": "1234", "__port1": "1234", "__port2": "5678"},
+			drop:  true,
+		},
+		{
+			name: "dropequal not equal",
+			rules: []*rule{
+				{Action: "dropequal", SourceLabels: []string{"__tmp_port"}, TargetLabel: "__port2"},
+			},
+			input:    map[string]string{"__tmp_port": "1234", "__port1": "1234", "__port2": "5678"},
+			expected: map[string]string{"__tmp_port": "1234", "__port1": "1234", "__port2": "5678"},
+		},
+		{
+			name: "case-insensitive action",
+			rules: []*rule{
+				{Action: "Drop", SourceLabels: []string{"a"}, Regex: str("foo")},
+			},
+			input: map[string]string{"a": "foo"},
+			drop:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := &Relabel{Rules: tt.rules, Log: testutil.Logger{}}
+			require.NoError(t, plugin.Init())
+
+			now := time.Now()
+			input := metric.New("test", tt.input, map[string]interface{}{"value": 42}, now)
+			actual := plugin.Apply(input)
+
+			var expected []telegraf.Metric
+			if !tt.drop {
+				expected = append(expected, metric.New("test", tt.expected, map[string]interface{}{"value": 42}, now))
+			}
+			testutil.RequireMetricsEqual(t, expected, actual)
+		})
+	}
+}
+
+func TestMetricName(t *testing.T) {
+	plugin := &Relabel{
+		Rules: []*rule{
+			{Action: "drop", SourceLabels: []string{"__name__"}, Regex: str("go_.*")},
+			{SourceLabels: []string{"__name__", "job"}, Regex: str("(.*);node"), TargetLabel: "__name__", Replacement: str("node_${1}")},
+			{SourceLabels: []string{"__name__"}, Regex: str("unnamed"), TargetLabel: "__name__", Replacement: str("")},
+		},
+		Log: testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	now := time.Now()
+	input := []telegraf.Metric{
+		metric.New("go_goroutines", map[string]string{"job": "node"}, map[string]interface{}{"gauge": 42}, now),
+		metric.New("cpu_seconds", map[string]string{"job": "node"}, map[string]interface{}{"counter": 23}, now),
+		metric.New("cpu_seconds", map[string]string{"job": "other"}, map[string]interface{}{"counter": 23}, now),
+		metric.New("unnamed", nil, map[string]interface{}{"value": 1}, now),
+	}
+	expected := []telegraf.Metric{
+		metric.New("node_cpu_seconds", map[string]string{"job": "node"}, map[string]interface{}{"counter": 23}, now),
+		metric.New("cpu_seconds", map[string]string{"job": "other"}, map[string]interface{}{"counter": 23}, now),
+	}
+	testutil.RequireMetricsEqual(t, expected, plugin.Apply(input...))
+}
+
+func TestTracking(t *testing.T) {
+	plugin := &Relabel{
+		Rules: []*rule{{Action: "drop", SourceLabels: []string{"a"}, Regex: str("drop")}},
+		Log:   testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var delivered int
+	notify := func(telegraf.DeliveryInfo) {
+		delivered++
+	}
+	now := time.Now()
+	input := []telegraf.Metric{
+		metric.New("test", map[string]string{"a": "drop"}, map[string]interface{}{"value": 1}, now),
+		metric.New("test", map[string]string{"a": "keep"}, map[string]interface{}{"value": 2}, now),
+	}
+	for i, m := range input {
+		input[i], _ = metric.WithTracking(m, notify)
+	}
+
+	actual := plugin.Apply(input...)
+	require.Len(t, actual, 1)
+	for _, m := range actual {
+		m.Accept()
+	}
+	require.Eventually(t, func() bool { return delivered == 2 }, time.Second, 10*time.Millisecond)
+}
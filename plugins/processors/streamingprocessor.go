@@ -25,6 +25,12 @@ func (sp *streamingProcessor) SampleConfig() string {
 
 func (sp *streamingProcessor) Start(acc telegraf.Accumulator) error {
 	sp.acc = acc
+	// Start processors running background tasks
+	if p, ok := sp.processor.(interface {
+		Start(telegraf.Accumulator) error
+	}); ok {
+		return p.Start(acc)
+	}
 	return nil
 }
 
@@ -35,7 +41,10 @@ func (sp *streamingProcessor) Add(m telegraf.Metric, acc telegraf.Accumulator) e
 	return nil
 }
 
-func (*streamingProcessor) Stop() {
+func (sp *streamingProcessor) Stop() {
+	if p, ok := sp.processor.(interface{ Stop() }); ok {
+		p.Stop()
+	}
 }
 
 // Init makes the streamingProcessor of type Initializer to be able to call the Init method of the wrapped processor if needed.
@@ -0,0 +1,125 @@
+package units
+
+// unit describes a unit of a physical or technical dimension. Values are
+// converted to the base unit of the dimension via
+//
+//	base = value * scale + offset
+type unit struct {
+	symbol    string
+	dimension string
+	scale     float64
+	offset    float64
+}
+
+func (u *unit) convert(value float64, to *unit) float64 {
+	if u.symbol == to.symbol {
+		return value
+	}
+	return (value*u.scale + u.offset - to.offset) / to.scale
+}
+
+// defaultTargets contains the unit each dimension is converted to if not
+// configured otherwise. The units follow the Prometheus naming conventions
+// for base units.
+var defaultTargets = map[string]string{
+	"data":        "B",
+	"data_rate":   "B/s",
+	"energy":      "J",
+	"fraction":    "ratio",
+	"frequency":   "Hz",
+	"length":      "m",
+	"power":       "W",
+	"temperature": "degC",
+	"time":        "s",
+}
+
+// registry contains all known units indexed by their symbol and aliases
+var registry = make(map[string]*unit)
+
+func register(dimension, symbol string, scale, offset float64, aliases ...string) {
+	u := &unit{symbol: symbol, dimension: dimension, scale: scale, offset: offset}
+	registry[symbol] = u
+	for _, alias := range aliases {
+		registry[alias] = u
+	}
+}
+
+func init() {
+	// Data sizes with byte as base unit, using decimal (SI) and binary (IEC)
+	// prefixes
+	register("data", "bit", 1.0/8, 0, "bits", "b")
+	register("data", "kbit", 1e3/8, 0, "Kbit")
+	register("data", "Mbit", 1e6/8, 0, "Mb")
+	register("data", "Gbit", 1e9/8, 0, "Gb")
+	register("data", "Tbit", 1e12/8, 0, "Tb")
+	register("data", "B", 1, 0, "byte", "bytes")
+	register("data", "kB", 1e3, 0, "KB", "kilobytes")
+	register("data", "MB", 1e6, 0, "megabytes")
+	register("data", "GB", 1e9, 0, "gigabytes")
+	register("data", "TB", 1e12, 0, "terabytes")
+	register("data", "PB", 1e15, 0, "petabytes")
+	register("data", "KiB", 1<<10, 0, "kibibytes")
+	register("data", "MiB", 1<<20, 0, "mebibytes")
+	register("data", "GiB", 1<<30, 0, "gibibytes")
+	register("data", "TiB", 1<<40, 0, "tebibytes")
+	register("data", "PiB", 1<<50, 0, "pebibytes")
+
+	// Data rates with bytes per second as base unit
+	register("data_rate", "bit/s", 1.0/8, 0, "bps")
+	register("data_rate", "kbit/s", 1e3/8, 0, "kbps", "Kbps")
+	register("data_rate", "Mbit/s", 1e6/8, 0, "Mbps")
+	register("data_rate", "Gbit/s", 1e9/8, 0, "Gbps")
+	register("data_rate", "B/s", 1, 0, "Bps")
+	register("data_rate", "kB/s", 1e3, 0, "KB/s")
+	register("data_rate", "MB/s", 1e6, 0)
+	register("data_rate", "GB/s", 1e9, 0)
+	register("data_rate", "KiB/s", 1<<10, 0)
+	register("data_rate", "MiB/s", 1<<20, 0)
+	register("data_rate", "GiB/s", 1<<30, 0)
+
+	// Durations with second as base unit
+	register("time", "ns", 1e-9, 0, "nanoseconds")
+	register("time", "us", 1e-6, 0, "µs", "microseconds")
+	register("time", "ms", 1e-3, 0, "milliseconds")
+	register("time", "s", 1, 0, "seconds")
+	register("time", "min", 60, 0, "minutes")
+	register("time", "h", 3600, 0, "hours")
+	register("time", "d", 86400, 0, "days")
+
+	// Fractions with ratio as base unit
+	register("fraction", "ratio", 1, 0)
+	register("fraction", "percent", 1e-2, 0, "%")
+	register("fraction", "permille", 1e-3, 0, "‰")
+	register("fraction", "ppm", 1e-6, 0)
+
+	// Frequencies with hertz as base unit
+	register("frequency", "Hz", 1, 0, "hertz")
+	register("frequency", "kHz", 1e3, 0)
+	register("frequency", "MHz", 1e6, 0)
+	register("frequency", "GHz", 1e9, 0)
+
+	// Lengths with meter as base unit
+	register("length", "mm", 1e-3, 0, "millimeters")
+	register("length", "cm", 1e-2, 0, "centimeters")
+	register("length", "m", 1, 0, "meters")
+	register("length", "km", 1e3, 0, "kilometers")
+
+	// Power with watt as base unit
+	register("power", "mW", 1e-3, 0, "milliwatts")
+	register("power", "W", 1, 0, "watts")
+	register("power", "kW", 1e3, 0, "kilowatts")
+	register("power", "MW", 1e6, 0, "megawatts")
+
+	// Energy with joule as base unit
+	register("energy", "J", 1, 0, "joules")
+	register("energy", "kJ", 1e3, 0, "kilojoules")
+	register("energy", "MJ", 1e6, 0, "megajoules")
+	register("energy", "Wh", 3600, 0)
+	register("energy", "kWh", 3.6e6, 0)
+	register("energy", "MWh", 3.6e9, 0)
+
+	// Temperatures with kelvin as base unit
+	register("temperature", "K", 1, 0, "kelvin")
+	register("temperature", "degC", 1, 273.15, "°C", "celsius")
+	register("temperature", "degF", 5.0/9, 273.15-32*5.0/9, "°F", "fahrenheit")
+}
@@ -0,0 +1,232 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package units
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/filter"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/plugins/processors"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type Units struct {
+	Targets     map[string]string `toml:"targets"`
+	UnitTag     string            `toml:"unit_tag"`
+	TagPerField bool              `toml:"tag_per_field"`
+	Rules       []rule            `toml:"rule"`
+	Log         telegraf.Logger   `toml:"-"`
+
+	targets      map[string]*unit
+	unknownUnits map[string]bool
+}
+
+type rule struct {
+	Fields      []string `toml:"fields"`
+	Unit        string   `toml:"unit"`
+	UnitFromTag string   `toml:"unit_from_tag"`
+	Target      string   `toml:"target"`
+	Suffix      string   `toml:"suffix"`
+	Replacement string   `toml:"suffix_replacement"`
+
+	filter filter.Filter
+	source *unit
+	target *unit
+}
+
+func (*Units) SampleConfig() string {
+	return sampleConfig
+}
+
+func (u *Units) Init() error {
+	if len(u.Rules) == 0 {
+		return errors.New("no rules defined")
+	}
+
+	// Determine the target unit for each dimension
+	u.targets = make(map[string]*unit, len(defaultTargets))
+	for dimension, symbol := range defaultTargets {
+		u.targets[dimension] = registry[symbol]
+	}
+	for dimension, symbol := range u.Targets {
+		if _, found := defaultTargets[dimension]; !found {
+			return fmt.Errorf("unknown dimension %q in targets", dimension)
+		}
+		target, err := lookupUnit(symbol, dimension)
+		if err != nil {
+			return fmt.Errorf("invalid target for dimension %q: %w", dimension, err)
+		}
+		u.targets[dimension] = target
+	}
+
+	for i := range u.Rules {
+		if err := u.Rules[i].init(); err != nil {
+			return fmt.Errorf("rule %d: %w", i+1, err)
+		}
+	}
+
+	u.unknownUnits = make(map[string]bool)
+
+	return nil
+}
+
+func (u *Units) Apply(in ...telegraf.Metric) []telegraf.Metric {
+	for _, m := range in {
+		u.convert(m)
+	}
+	return in
+}
+
+func (u *Units) convert(m telegraf.Metric) {
+	units := make(map[string]string)
+	renames := make(map[string]string)
+	for _, field := range m.FieldList() {
+		idx := slices.IndexFunc(u.Rules, func(r rule) bool { return r.filter.Match(field.Key) })
+		if idx < 0 {
+			continue
+		}
+		r := &u.Rules[idx]
+
+		source := r.source
+		if source == nil {
+			symbol, found := m.GetTag(r.UnitFromTag)
+			if !found {
+				continue
+			}
+			source = registry[symbol]
+			if source == nil {
+				// Only warn once per unit to not flood the log
+				if !u.unknownUnits[symbol] {
+					u.Log.Warnf("Unknown unit %q in tag %q", symbol, r.UnitFromTag)
+					u.unknownUnits[symbol] = true
+				}
+				continue
+			}
+		}
+
+		target := r.target
+		if target == nil {
+			target = u.targets[source.dimension]
+		} else if target.dimension != source.dimension {
+			u.Log.Errorf("Cannot convert field %q from %q to %q", field.Key, source.symbol, target.symbol)
+			continue
+		}
+
+		if source.symbol != target.symbol {
+			v, err := internal.ToFloat64(field.Value)
+			if err != nil {
+				u.Log.Errorf("Error converting %q to float: %v", field.Key, err)
+				continue
+			}
+			field.Value = source.convert(v, target)
+		}
+
+		name := field.Key
+		if r.Suffix != "" && strings.HasSuffix(name, r.Suffix) {
+			renamed := strings.TrimSuffix(name, r.Suffix) + r.Replacement
+			if m.HasField(renamed) {
+				u.Log.Errorf("Cannot rename field %q to existing field %q", name, renamed)
+			} else {
+				renames[name] = renamed
+				name = renamed
+			}
+		}
+		units[name] = target.symbol
+	}
+
+	for name, renamed := range renames {
+		if v, found := m.GetField(name); found {
+			m.RemoveField(name)
+			m.AddField(renamed, v)
+		}
+	}
+
+	if u.UnitTag == "" || len(units) == 0 {
+		return
+	}
+
+	// Use a single tag if all fields share the same unit and fall back to
+	// per-field tags otherwise
+	symbols := make([]string, 0, len(units))
+	for _, symbol := range units {
+		symbols = append(symbols, symbol)
+	}
+	sort.Strings(symbols)
+	if !u.TagPerField && symbols[0] == symbols[len(symbols)-1] {
+		m.AddTag(u.UnitTag, symbols[0])
+		return
+	}
+	for field, symbol := range units {
+		m.AddTag(field+"_"+u.UnitTag, symbol)
+	}
+}
+
+func (r *rule) init() error {
+	if len(r.Fields) == 0 {
+		return errors.New("no fields defined")
+	}
+
+	f, err := filter.Compile(r.Fields)
+	if err != nil {
+		return fmt.Errorf("could not compile fields filter: %w", err)
+	}
+	r.filter = f
+
+	switch {
+	case r.Unit != "" && r.UnitFromTag != "":
+		return errors.New("'unit' and 'unit_from_tag' are mutually exclusive")
+	case r.Unit != "":
+		source, err := lookupUnit(r.Unit, "")
+		if err != nil {
+			return err
+		}
+		r.source = source
+	case r.UnitFromTag == "":
+		return errors.New("either 'unit' or 'unit_from_tag' must be set")
+	}
+
+	if r.Replacement != "" && r.Suffix == "" {
+		return errors.New("'suffix_replacement' requires 'suffix' to be set")
+	}
+
+	if r.Target != "" {
+		dimension := ""
+		if r.source != nil {
+			dimension = r.source.dimension
+		}
+		target, err := lookupUnit(r.Target, dimension)
+		if err != nil {
+			return fmt.Errorf("invalid target: %w", err)
+		}
+		r.target = target
+	}
+
+	return nil
+}
+
+// lookupUnit returns the unit for the given symbol and optionally checks the
+// unit to be of the given dimension.
+func lookupUnit(symbol, dimension string) (*unit, error) {
+	u, found := registry[symbol]
+	if !found {
+		return nil, fmt.Errorf("unknown unit %q", symbol)
+	}
+	if dimension != "" && u.dimension != dimension {
+		return nil, fmt.Errorf("unit %q is not of dimension %q", symbol, dimension)
+	}
+	return u, nil
+}
+
+func init() {
+	processors.Add("units", func() telegraf.Processor {
+		return &Units{UnitTag: "unit"}
+	})
+}
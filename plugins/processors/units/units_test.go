@@ -0,0 +1,261 @@
+package units
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *Units
+		expected string
+	}{
+		{
+			name:     "no rules",
+			plugin:   &Units{},
+			expected: "no rules defined",
+		},
+		{
+			name:     "no fields",
+			plugin:   &Units{Rules: []rule{{Unit: "ms"}}},
+			expected: "no fields defined",
+		},
+		{
+			name:     "no unit",
+			plugin:   &Units{Rules: []rule{{Fields: []string{"*"}}}},
+			expected: "either 'unit' or 'unit_from_tag' must be set",
+		},
+		{
+			name:     "unit and tag",
+			plugin:   &Units{Rules: []rule{{Fields: []string{"*"}, Unit: "ms", UnitFromTag: "unit"}}},
+			expected: "mutually exclusive",
+		},
+		{
+			name:     "unknown unit",
+			plugin:   &Units{Rules: []rule{{Fields: []string{"*"}, Unit: "parsec"}}},
+			expected: "unknown unit \"parsec\"",
+		},
+		{
+			name:     "target of different dimension",
+			plugin:   &Units{Rules: []rule{{Fields: []string{"*"}, Unit: "ms", Target: "MB"}}},
+			expected: "unit \"MB\" is not of dimension \"time\"",
+		},
+		{
+			name:     "replacement without suffix",
+			plugin:   &Units{Rules: []rule{{Fields: []string{"*"}, Unit: "ms", Replacement: "_s"}}},
+			expected: "'suffix_replacement' requires 'suffix' to be set",
+		},
+		{
+			name: "unknown dimension",
+			plugin: &Units{
+				Targets: map[string]string{"mass": "kg"},
+				Rules:   []rule{{Fields: []string{"*"}, Unit: "ms"}},
+			},
+			expected: "unknown dimension \"mass\"",
+		},
+		{
+			name: "dimension target mismatch",
+			plugin: &Units{
+				Targets: map[string]string{"time": "MB"},
+				Rules:   []rule{{Fields: []string{"*"}, Unit: "ms"}},
+			},
+			expected: "invalid target for dimension \"time\"",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.ErrorContains(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestConversions(t *testing.T) {
+	tests := []struct {
+		source   string
+		target   string
+		value    interface{}
+		expected float64
+	}{
+		{source: "ms", target: "s", value: int64(1500), expected: 1.5},
+		{source: "us", target: "ms", value: 250.0, expected: 0.25},
+		{source: "h", target: "min", value: uint64(2), expected: 120},
+		{source: "kB", target: "B", value: int64(4), expected: 4000},
+		{source: "KiB", target: "B", value: int64(4), expected: 4096},
+		{source: "B", target: "MiB", value: 3 * 1048576.0, expected: 3},
+		{source: "bit", target: "B", value: 16.0, expected: 2},
+		{source: "Mbps", target: "B/s", value: 8.0, expected: 1e6},
+		{source: "%", target: "ratio", value: 42.0, expected: 0.42},
+		{source: "ratio", target: "percent", value: 0.5, expected: 50},
+		{source: "degF", target: "degC", value: 212.0, expected: 100},
+		{source: "degC", target: "K", value: -273.15, expected: 0},
+		{source: "kWh", target: "J", value: 1.0, expected: 3.6e6},
+		{source: "GHz", target: "Hz", value: 2.5, expected: 2.5e9},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.source+" to "+tt.target, func(t *testing.T) {
+			plugin := &Units{
+				UnitTag: "unit",
+				Rules:   []rule{{Fields: []string{"value"}, Unit: tt.source, Target: tt.target}},
+				Log:     testutil.Logger{},
+			}
+			require.NoError(t, plugin.Init())
+
+			input := metric.New("test", map[string]string{}, map[string]interface{}{"value": tt.value}, time.Unix(0, 0))
+			expected := []telegraf.Metric{
+				metric.New(
+					"test",
+					map[string]string{"unit": registry[tt.target].symbol},
+					map[string]interface{}{"value": tt.expected},
+					time.Unix(0, 0),
+				),
+			}
+
+			actual := plugin.Apply(input)
+			testutil.RequireMetricsEqual(t, expected, actual, cmpopts.EquateApprox(0, 1e-9))
+		})
+	}
+}
+
+func TestUnitTags(t *testing.T) {
+	plugin := &Units{
+		UnitTag: "unit",
+		Targets: map[string]string{"data": "MiB"},
+		Rules: []rule{
+			{Fields: []string{"*_ms"}, Unit: "ms"},
+			{Fields: []string{"usage_*"}, Unit: "percent"},
+			{Fields: []string{"mem_*"}, Unit: "KiB"},
+		},
+		Log: testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	input := []telegraf.Metric{
+		metric.New(
+			"app",
+			map[string]string{"host": "a"},
+			map[string]interface{}{"latency_ms": int64(250), "usage_cpu": 42.5, "status": "ok"},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"mem",
+			map[string]string{"host": "a"},
+			map[string]interface{}{"mem_used": int64(2048), "mem_free": int64(1024), "count": int64(3)},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"other",
+			map[string]string{"host": "a"},
+			map[string]interface{}{"value": int64(3)},
+			time.Unix(0, 0),
+		),
+	}
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"app",
+			map[string]string{"host": "a", "latency_ms_unit": "s", "usage_cpu_unit": "ratio"},
+			map[string]interface{}{"latency_ms": 0.25, "usage_cpu": 0.425, "status": "ok"},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"mem",
+			map[string]string{"host": "a", "unit": "MiB"},
+			map[string]interface{}{"mem_used": 2.0, "mem_free": 1.0, "count": int64(3)},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"other",
+			map[string]string{"host": "a"},
+			map[string]interface{}{"value": int64(3)},
+			time.Unix(0, 0),
+		),
+	}
+
+	actual := plugin.Apply(input...)
+	testutil.RequireMetricsEqual(t, expected, actual)
+}
+
+func TestUnitFromTag(t *testing.T) {
+	plugin := &Units{
+		UnitTag: "unit",
+		Rules:   []rule{{Fields: []string{"value"}, UnitFromTag: "unit"}},
+		Log:     testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	input := []telegraf.Metric{
+		metric.New("sensor", map[string]string{"unit": "°F"}, map[string]interface{}{"value": 50.0}, time.Unix(0, 0)),
+		metric.New("sensor", map[string]string{"unit": "ms"}, map[string]interface{}{"value": int64(10)}, time.Unix(0, 0)),
+		metric.New("sensor", map[string]string{"unit": "furlong"}, map[string]interface{}{"value": 1.0}, time.Unix(0, 0)),
+		metric.New("sensor", map[string]string{}, map[string]interface{}{"value": 1.0}, time.Unix(0, 0)),
+	}
+
+	expected := []telegraf.Metric{
+		metric.New("sensor", map[string]string{"unit": "degC"}, map[string]interface{}{"value": 10.0}, time.Unix(0, 0)),
+		metric.New("sensor", map[string]string{"unit": "s"}, map[string]interface{}{"value": 0.01}, time.Unix(0, 0)),
+		metric.New("sensor", map[string]string{"unit": "furlong"}, map[string]interface{}{"value": 1.0}, time.Unix(0, 0)),
+		metric.New("sensor", map[string]string{}, map[string]interface{}{"value": 1.0}, time.Unix(0, 0)),
+	}
+
+	actual := plugin.Apply(input...)
+	testutil.RequireMetricsEqual(t, expected, actual, cmpopts.EquateApprox(0, 1e-9))
+}
+
+func TestTagPerField(t *testing.T) {
+	plugin := &Units{
+		UnitTag:     "unit",
+		TagPerField: true,
+		Rules:       []rule{{Fields: []string{"*"}, Unit: "s"}},
+		Log:         testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	input := metric.New("test", map[string]string{}, map[string]interface{}{"a": 1.0, "b": int64(2)}, time.Unix(0, 0))
+	expected := []telegraf.Metric{
+		metric.New(
+			"test",
+			map[string]string{"a_unit": "s", "b_unit": "s"},
+			map[string]interface{}{"a": 1.0, "b": int64(2)},
+			time.Unix(0, 0),
+		),
+	}
+
+	actual := plugin.Apply(input)
+	testutil.RequireMetricsEqual(t, expected, actual)
+}
+
+func TestSuffixReplacement(t *testing.T) {
+	plugin := &Units{
+		UnitTag: "unit",
+		Rules: []rule{
+			{Fields: []string{"*_ms"}, Unit: "ms", Suffix: "_ms", Replacement: "_s"},
+			{Fields: []string{"*_kb"}, Unit: "kB", Suffix: "_kb"},
+		},
+		Log: testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	input := []telegraf.Metric{
+		metric.New("app", map[string]string{}, map[string]interface{}{"latency_ms": int64(250), "count": int64(3)}, time.Unix(0, 0)),
+		metric.New("app", map[string]string{}, map[string]interface{}{"size_kb": 2.0}, time.Unix(0, 0)),
+		metric.New("app", map[string]string{}, map[string]interface{}{"latency_ms": int64(250), "latency_s": 1.0}, time.Unix(0, 0)),
+	}
+	expected := []telegraf.Metric{
+		metric.New("app", map[string]string{"unit": "s"}, map[string]interface{}{"latency_s": 0.25, "count": int64(3)}, time.Unix(0, 0)),
+		metric.New("app", map[string]string{"unit": "B"}, map[string]interface{}{"size": 2000.0}, time.Unix(0, 0)),
+		metric.New("app", map[string]string{"unit": "s"}, map[string]interface{}{"latency_ms": 0.25, "latency_s": 1.0}, time.Unix(0, 0)),
+	}
+
+	actual := plugin.Apply(input...)
+	testutil.RequireMetricsEqual(t, expected, actual, cmpopts.EquateApprox(0, 1e-9))
+}
@@ -7,7 +7,9 @@ import (
 	"errors"
 	"fmt"
 	"maps"
+	"reflect"
 	"slices"
+	"time"
 
 	vault "github.com/hashicorp/vault/api"
 	"github.com/hashicorp/vault/api/auth/approle"
@@ -20,16 +22,35 @@ import (
 //go:embed sample.conf
 var sampleConfig string
 
+// reauthRetryInterval is the time to wait before retrying to log in again
+// after the login lease expired
+const reauthRetryInterval = 10 * time.Second
+
 type Vault struct {
-	ID         string        `toml:"id"`
-	Address    string        `toml:"address"`
-	MountPath  string        `toml:"mount_path"`
-	SecretPath string        `toml:"secret_path"`
-	Engine     string        `toml:"engine"`
-	Token      config.Secret `toml:"token"`
-	AppRole    *appRole      `toml:"approle"`
+	ID              string          `toml:"id"`
+	Address         string          `toml:"address"`
+	MountPath       string          `toml:"mount_path"`
+	SecretPath      string          `toml:"secret_path"`
+	Engine          string          `toml:"engine"`
+	Token           config.Secret   `toml:"token"`
+	AppRole         *appRole        `toml:"approle"`
+	RefreshInterval config.Duration `toml:"refresh_interval"`
+	Log             telegraf.Logger `toml:"-"`
+
+	client  *vault.Client
+	watcher *vault.LifetimeWatcher
+
+	// Background handling of lease renewal and secret refreshes
+	notifier chan notification
+	cancel   context.CancelFunc
+	done     chan struct{}
+}
 
-	client *vault.Client
+// notification passes the function to notify about changed secrets together
+// with the secret data used as reference to the background routine
+type notification struct {
+	notify telegraf.SecretNotifyFunc
+	data   map[string]interface{}
 }
 
 type appRole struct {
@@ -81,7 +102,28 @@ func (v *Vault) Init() error {
 
 	v.client = client
 
-	return v.authenticate()
+	ctx, cancel := context.WithCancel(context.Background())
+	if err := v.authenticate(ctx); err != nil {
+		cancel()
+		return err
+	}
+
+	v.cancel = cancel
+	v.notifier = make(chan notification, 1)
+	v.done = make(chan struct{})
+	go v.run(ctx)
+
+	return nil
+}
+
+// Stop terminates the lease renewal and secret refreshes and waits for the
+// background routine to finish
+func (v *Vault) Stop() {
+	if v.cancel == nil {
+		return
+	}
+	v.cancel()
+	<-v.done
 }
 
 func (v *Vault) Get(key string) ([]byte, error) {
@@ -129,6 +171,15 @@ func (v *Vault) Set(key, value string) error {
 	return err
 }
 
+func (v *Vault) SetNotifier(notify telegraf.SecretNotifyFunc) {
+	// Remember the current state to only notify about future changes
+	data, err := v.read()
+	if err != nil {
+		v.Log.Errorf("Reading secrets failed: %v", err)
+	}
+	v.notifier <- notification{notify: notify, data: data}
+}
+
 func (v *Vault) GetResolver(key string) (telegraf.ResolveFunc, error) {
 	resolver := func() ([]byte, bool, error) {
 		s, err := v.Get(key)
@@ -137,7 +188,7 @@ func (v *Vault) GetResolver(key string) (telegraf.ResolveFunc, error) {
 	return resolver, nil
 }
 
-func (v *Vault) authenticate() error {
+func (v *Vault) authenticate(ctx context.Context) error {
 	if !v.Token.Empty() {
 		token, err := v.Token.Get()
 		if err != nil {
@@ -165,7 +216,7 @@ func (v *Vault) authenticate() error {
 		return fmt.Errorf("unable to initialize AppRole auth method: %w", err)
 	}
 
-	authInfo, err := v.client.Auth().Login(context.Background(), appRoleAuth)
+	authInfo, err := v.client.Auth().Login(ctx, appRoleAuth)
 	if err != nil {
 		return fmt.Errorf("unable to login to AppRole auth method: %w", err)
 	}
@@ -178,10 +229,131 @@ func (v *Vault) authenticate() error {
 		return fmt.Errorf("unable to initialize Vault lifetime watcher: %w", err)
 	}
 	go watcher.Start()
+	v.watcher = watcher
 
 	return nil
 }
 
+// run handles the renewal of the login lease and refreshes the secrets to
+// detect changes until the context is cancelled. If the lease cannot be
+// renewed anymore, the plugin logs in again and refreshes the secrets as the
+// token changed. Handlers are notified from this routine only.
+func (v *Vault) run(ctx context.Context) {
+	defer close(v.done)
+
+	var refresh <-chan time.Time
+	if v.RefreshInterval > 0 {
+		ticker := time.NewTicker(time.Duration(v.RefreshInterval))
+		defer ticker.Stop()
+		refresh = ticker.C
+	}
+
+	var notify telegraf.SecretNotifyFunc
+	var data map[string]interface{}
+	for {
+		var renewed <-chan *vault.RenewOutput
+		var expired <-chan error
+		if v.watcher != nil {
+			renewed = v.watcher.RenewCh()
+			expired = v.watcher.DoneCh()
+		}
+
+		select {
+		case <-ctx.Done():
+			if v.watcher != nil {
+				v.watcher.Stop()
+			}
+			return
+		case n := <-v.notifier:
+			notify, data = n.notify, n.data
+		case renewal := <-renewed:
+			v.Log.Debugf("Renewed token lease at %v", renewal.RenewedAt)
+		case err := <-expired:
+			if err != nil {
+				v.Log.Warnf("Renewing token lease failed: %v", err)
+			}
+			v.watcher.Stop()
+			v.watcher = nil
+			if !v.reauthenticate(ctx) {
+				return
+			}
+			if notify != nil {
+				data = v.refresh(notify, data)
+			}
+		case <-refresh:
+			if notify != nil {
+				data = v.refresh(notify, data)
+			}
+		}
+	}
+}
+
+// reauthenticate logs in again until it succeeds or the context is cancelled
+func (v *Vault) reauthenticate(ctx context.Context) bool {
+	for {
+		err := v.authenticate(ctx)
+		if err == nil {
+			return true
+		}
+		v.Log.Errorf("Re-authenticating failed, retrying in %s: %v", reauthRetryInterval, err)
+		select {
+		case <-ctx.Done():
+			return false
+		case <-time.After(reauthRetryInterval):
+		}
+	}
+}
+
+// refresh reads the secrets and notifies about all keys with changed values
+// compared to the previous data. The new data is returned to be used as
+// reference for the next refresh.
+func (v *Vault) refresh(notify telegraf.SecretNotifyFunc, previous map[string]interface{}) map[string]interface{} {
+	data, err := v.read()
+	if err != nil {
+		v.Log.Errorf("Refreshing secrets failed: %v", err)
+		return previous
+	}
+
+	// Without a previous read use the data as a reference only
+	if previous == nil {
+		return data
+	}
+
+	for _, key := range changedKeys(previous, data) {
+		v.Log.Debugf("Secret %q changed", key)
+		notify(key)
+	}
+	return data
+}
+
+// read returns the current secret data
+func (v *Vault) read() (map[string]interface{}, error) {
+	secret, err := v.getSecret()
+	if err != nil {
+		return nil, fmt.Errorf("unable to read secret: %w", err)
+	}
+	if secret == nil || secret.Data == nil {
+		return make(map[string]interface{}), nil
+	}
+	return secret.Data, nil
+}
+
+// changedKeys returns the keys with added, changed or removed values
+func changedKeys(previous, current map[string]interface{}) []string {
+	changed := make([]string, 0)
+	for key, value := range current {
+		if prev, found := previous[key]; !found || !reflect.DeepEqual(prev, value) {
+			changed = append(changed, key)
+		}
+	}
+	for key := range previous {
+		if _, found := current[key]; !found {
+			changed = append(changed, key)
+		}
+	}
+	return changed
+}
+
 func (v *Vault) getSecret() (*vault.KVSecret, error) {
 	if v.Engine == "kv-v1" {
 		return v.client.KVv1(v.MountPath).Get(context.Background(), v.SecretPath)
@@ -6,14 +6,19 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"net/http"
+	"net/http/httptest"
 	"path/filepath"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 	"github.com/testcontainers/testcontainers-go"
 	"github.com/testcontainers/testcontainers-go/modules/vault"
 
 	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/testutil"
 )
 
 func createContainer(t *testing.T, initCommands []string) (*vault.VaultContainer, func()) {
@@ -314,3 +319,97 @@ func TestIntegrationTokenAuth(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, secretValue, string(secret))
 }
+
+func TestRefreshNotify(t *testing.T) {
+	var response atomic.Value
+	response.Store(`{"data": {"user": "admin", "password": "secret"}}`)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/secret/my/path" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if _, err := w.Write([]byte(response.Load().(string))); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			t.Error(err)
+		}
+	}))
+	defer server.Close()
+
+	plugin := &Vault{
+		ID:              "vault",
+		Address:         server.URL,
+		MountPath:       "secret",
+		SecretPath:      "my/path",
+		Engine:          "kv-v1",
+		Token:           config.NewSecret([]byte("token")),
+		RefreshInterval: config.Duration(10 * time.Millisecond),
+		Log:             testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	defer plugin.Stop()
+
+	// The handler reads the secret to check for deadlocks with the store
+	changed := make(chan string, 10)
+	plugin.SetNotifier(func(key string) {
+		if _, err := plugin.Get(key); err != nil {
+			t.Error(err)
+		}
+		changed <- key
+	})
+
+	// Refreshing without changes should not notify
+	time.Sleep(50 * time.Millisecond)
+	require.Empty(t, changed)
+
+	// Change one secret, remove another one and add a new one
+	response.Store(`{"data": {"user": "root", "token": "foo"}}`)
+	keys := make([]string, 0, 3)
+	for len(keys) < 3 {
+		select {
+		case key := <-changed:
+			keys = append(keys, key)
+		case <-time.After(time.Second):
+			require.FailNow(t, "timeout waiting for notifications", "got %v", keys)
+		}
+	}
+	require.ElementsMatch(t, []string{"user", "password", "token"}, keys)
+
+	// The resolver must return the new value
+	resolver, err := plugin.GetResolver("user")
+	require.NoError(t, err)
+	value, dynamic, err := resolver()
+	require.NoError(t, err)
+	require.True(t, dynamic)
+	require.Equal(t, "root", string(value))
+}
+
+func TestStop(t *testing.T) {
+	var requests atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		requests.Add(1)
+		if _, err := w.Write([]byte(`{"data": {"user": "admin"}}`)); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			t.Error(err)
+		}
+	}))
+	defer server.Close()
+
+	plugin := &Vault{
+		ID:              "vault",
+		Address:         server.URL,
+		MountPath:       "secret",
+		SecretPath:      "my/path",
+		Engine:          "kv-v1",
+		Token:           config.NewSecret([]byte("token")),
+		RefreshInterval: config.Duration(time.Millisecond),
+		Log:             testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	plugin.SetNotifier(func(string) {})
+	plugin.Stop()
+
+	// No requests must be sent after stopping
+	count := requests.Load()
+	time.Sleep(20 * time.Millisecond)
+	require.Equal(t, count, requests.Load())
+}
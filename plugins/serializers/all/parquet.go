@@ -0,0 +1,7 @@
+//go:build !custom || serializers || serializers.parquet
+
+package all
+
+import (
+	_ "github.com/influxdata/telegraf/plugins/serializers/parquet" // register plugin
+)
@@ -0,0 +1,314 @@
+package avro
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/linkedin/goavro/v2"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/plugins/common/schemaregistry"
+	"github.com/influxdata/telegraf/plugins/serializers"
+)
+
+type Serializer struct {
+	SchemaRegistry     string          `toml:"avro_schema_registry"`
+	CaCertPath         string          `toml:"avro_schema_registry_cert"`
+	SchemaMode         string          `toml:"avro_schema_mode"`
+	Subject            string          `toml:"avro_subject"`
+	Namespace          string          `toml:"avro_namespace"`
+	CompatibilityCheck bool            `toml:"avro_compatibility_check"`
+	SchemaTTL          config.Duration `toml:"avro_schema_ttl"`
+	Timestamp          string          `toml:"avro_timestamp"`
+	TimestampFormat    string          `toml:"avro_timestamp_format"`
+	Log                telegraf.Logger `toml:"-"`
+
+	registry *schemaregistry.Client
+	subject  *template.Template
+
+	// Local cache of derived schemas keyed by subject and record name, and
+	// of looked-up schemas keyed by subject
+	derived map[string]*derivedSchema
+	lookup  map[string]*cachedSchema
+	mu      sync.Mutex
+}
+
+type cachedSchema struct {
+	id      int
+	codec   *goavro.Codec
+	fields  []lookupField
+	fetched time.Time
+}
+
+// derivedSchema holds the union of all columns seen for a subject and record
+type derivedSchema struct {
+	cachedSchema
+	definition string
+	columns    []column
+}
+
+func (s *Serializer) Init() error {
+	switch s.SchemaMode {
+	case "":
+		s.SchemaMode = "derive"
+	case "derive":
+	case "lookup":
+		if s.SchemaRegistry == "" {
+			return errors.New("'avro_schema_mode = \"lookup\"' requires 'avro_schema_registry' to be set")
+		}
+	default:
+		return fmt.Errorf("invalid 'avro_schema_mode' %q", s.SchemaMode)
+	}
+
+	switch s.TimestampFormat {
+	case "":
+		s.TimestampFormat = "unix_ms"
+	case "unix", "unix_ms", "unix_us", "unix_ns":
+	default:
+		return fmt.Errorf("invalid 'avro_timestamp_format' %q", s.TimestampFormat)
+	}
+
+	if s.Timestamp == "" {
+		s.Timestamp = "timestamp"
+	}
+
+	switch {
+	case s.SchemaTTL < 0:
+		return fmt.Errorf("invalid 'avro_schema_ttl' %s", time.Duration(s.SchemaTTL))
+	case s.SchemaTTL == 0:
+		s.SchemaTTL = config.Duration(5 * time.Minute)
+	}
+
+	if s.Subject != "" {
+		tmpl, err := template.New("subject").Parse(s.Subject)
+		if err != nil {
+			return fmt.Errorf("parsing 'avro_subject' failed: %w", err)
+		}
+		s.subject = tmpl
+	}
+
+	if s.SchemaRegistry != "" {
+		registry, err := schemaregistry.NewClient(s.SchemaRegistry, s.CaCertPath)
+		if err != nil {
+			return fmt.Errorf("error connecting to the schema registry %q: %w", s.SchemaRegistry, err)
+		}
+		s.registry = registry
+	}
+
+	s.derived = make(map[string]*derivedSchema)
+	s.lookup = make(map[string]*cachedSchema)
+
+	return nil
+}
+
+func (s *Serializer) Serialize(m telegraf.Metric) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := s.serialize(&buf, m); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// SerializeBatch concatenates the serialized metrics. As Avro messages are not
+// self-delimiting, most consumers expect one metric per message.
+func (s *Serializer) SerializeBatch(metrics []telegraf.Metric) ([]byte, error) {
+	var buf bytes.Buffer
+	for _, m := range metrics {
+		if err := s.serialize(&buf, m); err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+func (s *Serializer) serialize(buf *bytes.Buffer, m telegraf.Metric) error {
+	subject, err := s.subjectName(m)
+	if err != nil {
+		return err
+	}
+
+	var schema *cachedSchema
+	var record map[string]interface{}
+	switch s.SchemaMode {
+	case "derive":
+		columns, values, err := s.deriveColumns(m)
+		if err != nil {
+			return err
+		}
+		derived, err := s.derivedSchema(subject, m.Name(), columns)
+		if err != nil {
+			return err
+		}
+		schema = &derived.cachedSchema
+		record = s.deriveRecord(derived.columns, values)
+	case "lookup":
+		if schema, err = s.lookupSchema(subject); err != nil {
+			return err
+		}
+		if record, err = s.lookupRecord(m, schema.fields); err != nil {
+			return err
+		}
+	}
+
+	// Prefix the message with the Confluent wire-format header consisting
+	// of the magic byte and the schema ID.
+	if s.registry != nil {
+		var header [5]byte
+		binary.BigEndian.PutUint32(header[1:], uint32(schema.id))
+		buf.Write(header[:])
+	}
+
+	data, err := schema.codec.BinaryFromNative(nil, record)
+	if err != nil {
+		return fmt.Errorf("encoding measurement %q failed: %w", m.Name(), err)
+	}
+	buf.Write(data)
+	return nil
+}
+
+// subjectName returns the registry subject for the metric. By default, the
+// full record name is used, i.e. the record-name strategy.
+func (s *Serializer) subjectName(m telegraf.Metric) (string, error) {
+	name := sanitizeName(m.Name())
+	if s.subject == nil {
+		if s.Namespace == "" {
+			return name, nil
+		}
+		return s.Namespace + "." + name, nil
+	}
+
+	var b strings.Builder
+	data := map[string]string{"Name": name, "Namespace": s.Namespace}
+	if err := s.subject.Execute(&b, data); err != nil {
+		return "", fmt.Errorf("executing subject template failed: %w", err)
+	}
+	return b.String(), nil
+}
+
+// derivedSchema returns the schema for the subject and measurement. A new
+// schema version is only created if the metric contains a column not seen
+// before or a column changed its type.
+func (s *Serializer) derivedSchema(subject, measurement string, columns []column) (*derivedSchema, error) {
+	name := sanitizeName(measurement)
+	key := subject + "\x00" + name
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var known []column
+	current, found := s.derived[key]
+	if found {
+		known = current.columns
+	}
+	merged, changed, err := mergeColumns(measurement, known, columns)
+	if err != nil {
+		return nil, err
+	}
+	if found && !changed {
+		return current, nil
+	}
+
+	definition, err := s.deriveDefinition(name, merged)
+	if err != nil {
+		return nil, err
+	}
+	codec, err := goavro.NewCodec(definition)
+	if err != nil {
+		return nil, fmt.Errorf("creating codec for subject %q failed: %w", subject, err)
+	}
+	schema := &derivedSchema{
+		cachedSchema: cachedSchema{codec: codec},
+		definition:   definition,
+		columns:      merged,
+	}
+
+	if s.registry != nil {
+		if s.CompatibilityCheck {
+			if err := s.registry.CheckCompatibility(subject, definition); err != nil {
+				return nil, err
+			}
+		}
+		id, err := s.registry.Register(subject, definition)
+		if err != nil {
+			return nil, fmt.Errorf("registering schema for subject %q failed: %w", subject, err)
+		}
+		schema.id = id
+		s.Log.Debugf("Registered schema %d for subject %q", id, subject)
+	}
+
+	s.derived[key] = schema
+	return schema, nil
+}
+
+// lookupSchema returns the latest schema of the subject. Schemas are fetched
+// again after the configured TTL to pick up new versions. If fetching fails,
+// the previous schema is used until the next refresh.
+func (s *Serializer) lookupSchema(subject string) (*cachedSchema, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	current, found := s.lookup[subject]
+	if found && time.Since(current.fetched) < time.Duration(s.SchemaTTL) {
+		return current, nil
+	}
+
+	schema, err := s.fetchSchema(subject)
+	if err != nil {
+		if !found {
+			return nil, err
+		}
+		s.Log.Warnf("Refreshing schema for subject %q failed, using schema %d: %v", subject, current.id, err)
+		current.fetched = time.Now()
+		return current, nil
+	}
+	if found && schema.id != current.id {
+		s.Log.Debugf("Using schema %d instead of %d for subject %q", schema.id, current.id, subject)
+	}
+
+	s.lookup[subject] = schema
+	return schema, nil
+}
+
+func (s *Serializer) fetchSchema(subject string) (*cachedSchema, error) {
+	id, definition, err := s.registry.Latest(subject)
+	if err != nil {
+		return nil, fmt.Errorf("looking up schema for subject %q failed: %w", subject, err)
+	}
+	codec, err := goavro.NewCodec(definition)
+	if err != nil {
+		return nil, fmt.Errorf("creating codec for subject %q failed: %w", subject, err)
+	}
+	fields, err := parseLookupSchema(definition)
+	if err != nil {
+		return nil, fmt.Errorf("schema %d of subject %q: %w", id, subject, err)
+	}
+
+	return &cachedSchema{id: id, codec: codec, fields: fields, fetched: time.Now()}, nil
+}
+
+func (s *Serializer) timestamp(m telegraf.Metric) int64 {
+	switch s.TimestampFormat {
+	case "unix":
+		return m.Time().Unix()
+	case "unix_us":
+		return m.Time().UnixMicro()
+	case "unix_ns":
+		return m.Time().UnixNano()
+	}
+	return m.Time().UnixMilli()
+}
+
+func init() {
+	serializers.Add("avro",
+		func() telegraf.Serializer {
+			return &Serializer{}
+		},
+	)
+}
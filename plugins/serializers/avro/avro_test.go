@@ -0,0 +1,492 @@
+package avro
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/linkedin/goavro/v2"
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/plugins/parsers/avro"
+	"github.com/influxdata/telegraf/plugins/serializers"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+// registry is a minimal stand-in for the Confluent schema registry
+type registry struct {
+	schemas      []string
+	subjects     map[string][]int
+	incompatible bool
+	unavailable  bool
+	requests     int
+	sync.Mutex
+}
+
+func newRegistry() *registry {
+	return &registry{subjects: make(map[string][]int)}
+}
+
+func (r *registry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.Lock()
+	defer r.Unlock()
+	r.requests++
+	if r.unavailable {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+
+	var body struct {
+		Schema string `json:"schema"`
+	}
+	if req.Method == http.MethodPost {
+		if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+	}
+
+	parts := strings.Split(strings.Trim(req.URL.Path, "/"), "/")
+	switch {
+	case len(parts) == 3 && parts[0] == "schemas" && parts[1] == "ids":
+		id, err := strconv.Atoi(parts[2])
+		if err != nil || id < 1 || id > len(r.schemas) {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"error_code":40403,"message":"Schema not found"}`)
+			return
+		}
+		writeJSON(w, map[string]interface{}{"schema": r.schemas[id-1]})
+	case len(parts) == 3 && parts[0] == "subjects" && parts[2] == "versions" && req.Method == http.MethodPost:
+		id := 0
+		for i, s := range r.schemas {
+			if s == body.Schema {
+				id = i + 1
+			}
+		}
+		if id == 0 {
+			r.schemas = append(r.schemas, body.Schema)
+			id = len(r.schemas)
+		}
+		r.subjects[parts[1]] = append(r.subjects[parts[1]], id)
+		writeJSON(w, map[string]interface{}{"id": id})
+	case len(parts) == 4 && parts[0] == "subjects" && parts[3] == "latest":
+		versions := r.subjects[parts[1]]
+		if len(versions) == 0 {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"error_code":40401,"message":"Subject not found"}`)
+			return
+		}
+		id := versions[len(versions)-1]
+		writeJSON(w, map[string]interface{}{"id": id, "schema": r.schemas[id-1]})
+	case len(parts) == 5 && parts[0] == "compatibility":
+		if len(r.subjects[parts[2]]) == 0 {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"error_code":40401,"message":"Subject not found"}`)
+			return
+		}
+		if r.incompatible {
+			writeJSON(w, map[string]interface{}{
+				"is_compatible": false,
+				"messages":      []string{"READER_FIELD_MISSING_DEFAULT_VALUE"},
+			})
+			return
+		}
+		writeJSON(w, map[string]interface{}{"is_compatible": true})
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/vnd.schemaregistry.v1+json")
+	//nolint:errcheck // Ignore the returned error as we cannot do anything about it anyway
+	json.NewEncoder(w).Encode(v)
+}
+
+func TestSerializeRegistry(t *testing.T) {
+	reg := newRegistry()
+	server := httptest.NewServer(reg)
+	defer server.Close()
+
+	s := &Serializer{
+		SchemaRegistry: server.URL,
+		Namespace:      "telegraf",
+		Log:            testutil.Logger{},
+	}
+	require.NoError(t, s.Init())
+
+	m1 := metric.New(
+		"cpu",
+		map[string]string{"host": "a"},
+		map[string]interface{}{"usage_idle": 42.5, "count": int64(3)},
+		time.Unix(1700000000, 0),
+	)
+	m2 := metric.New(
+		"cpu",
+		map[string]string{"host": "b"},
+		map[string]interface{}{"usage_idle": 17.0, "count": int64(4)},
+		time.Unix(1700000010, 0),
+	)
+
+	buf1, err := s.Serialize(m1)
+	require.NoError(t, err)
+	buf2, err := s.Serialize(m2)
+	require.NoError(t, err)
+
+	// The schema must only be registered once
+	require.Equal(t, 1, reg.requests)
+	require.Len(t, reg.schemas, 1)
+	require.Equal(t, []int{1}, reg.subjects["telegraf.cpu"])
+
+	// Check the Confluent wire-format header and the payload
+	for i, buf := range [][]byte{buf1, buf2} {
+		require.Equal(t, byte(0), buf[0])
+		require.Equal(t, uint32(1), binary.BigEndian.Uint32(buf[1:5]))
+
+		codec, err := goavro.NewCodec(reg.schemas[0])
+		require.NoError(t, err)
+		native, _, err := codec.NativeFromBinary(buf[5:])
+		require.NoError(t, err)
+		record := native.(map[string]interface{})
+		require.Equal(t, map[string]interface{}{"string": []string{"a", "b"}[i]}, record["host"])
+		require.Equal(t, []int64{1700000000000, 1700000010000}[i], record["timestamp"])
+	}
+
+	expected := `{"type":"record","name":"cpu","namespace":"telegraf","fields":[` +
+		`{"name":"timestamp","type":"long"},` +
+		`{"name":"host","type":["null","string"],"default":null},` +
+		`{"name":"count","type":["null","long"],"default":null},` +
+		`{"name":"usage_idle","type":["null","double"],"default":null}]}`
+	require.JSONEq(t, expected, reg.schemas[0])
+}
+
+func TestRoundTrip(t *testing.T) {
+	reg := newRegistry()
+	server := httptest.NewServer(reg)
+	defer server.Close()
+
+	s := &Serializer{
+		SchemaRegistry: server.URL,
+		Log:            testutil.Logger{},
+	}
+	require.NoError(t, s.Init())
+
+	parser := &avro.Parser{
+		SchemaRegistry:  server.URL,
+		Tags:            []string{"host"},
+		Fields:          []string{"usage_idle", "active", "state"},
+		Timestamp:       "timestamp",
+		TimestampFormat: "unix_ms",
+		UnionMode:       "nullable",
+		Log:             testutil.Logger{},
+	}
+	require.NoError(t, parser.Init())
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"cpu",
+			map[string]string{"host": "a"},
+			map[string]interface{}{"usage_idle": 42.5, "active": true, "state": "ok"},
+			time.Unix(1700000000, 123000000),
+		),
+		metric.New(
+			"cpu",
+			map[string]string{"host": "b"},
+			map[string]interface{}{"usage_idle": 17.0, "active": false, "state": "degraded"},
+			time.Unix(1700000010, 0),
+		),
+	}
+
+	actual := make([]telegraf.Metric, 0, len(expected))
+	for _, m := range expected {
+		buf, err := s.Serialize(m)
+		require.NoError(t, err)
+		metrics, err := parser.Parse(buf)
+		require.NoError(t, err)
+		actual = append(actual, metrics...)
+	}
+	testutil.RequireMetricsEqual(t, expected, actual)
+}
+
+func TestCompatibilityCheck(t *testing.T) {
+	reg := newRegistry()
+	server := httptest.NewServer(reg)
+	defer server.Close()
+
+	s := &Serializer{
+		SchemaRegistry:     server.URL,
+		CompatibilityCheck: true,
+		Log:                testutil.Logger{},
+	}
+	require.NoError(t, s.Init())
+
+	// The first schema is compatible as the subject does not exist yet
+	_, err := s.Serialize(metric.New("cpu", map[string]string{}, map[string]interface{}{"value": 1.0}, time.Unix(0, 0)))
+	require.NoError(t, err)
+
+	// Adding a field without default is incompatible
+	reg.incompatible = true
+	_, err = s.Serialize(metric.New("cpu", map[string]string{}, map[string]interface{}{"value": 1.0, "other": 2.0}, time.Unix(0, 0)))
+	require.ErrorContains(t, err, `schema incompatible with latest version of subject "cpu": READER_FIELD_MISSING_DEFAULT_VALUE`)
+	require.Len(t, reg.schemas, 1)
+}
+
+func TestSchemaLookup(t *testing.T) {
+	reg := newRegistry()
+	reg.schemas = []string{`{
+		"type": "record",
+		"name": "cpu",
+		"namespace": "telegraf",
+		"fields": [
+			{"name": "timestamp", "type": {"type": "long", "logicalType": "timestamp-millis"}},
+			{"name": "host", "type": "string"},
+			{"name": "usage_idle", "type": ["null", "double"], "default": null},
+			{"name": "count", "type": ["null", "long"], "default": null},
+			{"name": "region", "type": "string", "default": "unknown"}
+		]
+	}`}
+	reg.subjects["cpu-value"] = []int{1}
+	server := httptest.NewServer(reg)
+	defer server.Close()
+
+	s := &Serializer{
+		SchemaRegistry: server.URL,
+		SchemaMode:     "lookup",
+		Subject:        "{{ .Name }}-value",
+		Log:            testutil.Logger{},
+	}
+	require.NoError(t, s.Init())
+
+	m := metric.New(
+		"cpu",
+		map[string]string{"host": "a"},
+		map[string]interface{}{"usage_idle": 42.5, "ignored": "x"},
+		time.Unix(1700000000, 0),
+	)
+	buf, err := s.Serialize(m)
+	require.NoError(t, err)
+	require.Equal(t, uint32(1), binary.BigEndian.Uint32(buf[1:5]))
+
+	codec, err := goavro.NewCodec(reg.schemas[0])
+	require.NoError(t, err)
+	native, _, err := codec.NativeFromBinary(buf[5:])
+	require.NoError(t, err)
+	record := native.(map[string]interface{})
+	require.Equal(t, "a", record["host"])
+	require.Equal(t, map[string]interface{}{"double": 42.5}, record["usage_idle"])
+	require.Nil(t, record["count"])
+	require.Equal(t, "unknown", record["region"])
+	require.Equal(t, time.UnixMilli(1700000000000).UTC(), record["timestamp"])
+
+	// A value not matching the registered type is a schema conflict
+	m = metric.New(
+		"cpu",
+		map[string]string{"host": "a"},
+		map[string]interface{}{"usage_idle": "high"},
+		time.Unix(1700000000, 0),
+	)
+	_, err = s.Serialize(m)
+	require.ErrorContains(t, err, `schema conflict in measurement "cpu"`)
+}
+
+func TestDerivedSchemaEvolution(t *testing.T) {
+	reg := newRegistry()
+	server := httptest.NewServer(reg)
+	defer server.Close()
+
+	s := &Serializer{
+		SchemaRegistry: server.URL,
+		Log:            testutil.Logger{},
+	}
+	require.NoError(t, s.Init())
+
+	decode := func(buf []byte) (uint32, map[string]interface{}) {
+		id := binary.BigEndian.Uint32(buf[1:5])
+		codec, err := goavro.NewCodec(reg.schemas[id-1])
+		require.NoError(t, err)
+		native, _, err := codec.NativeFromBinary(buf[5:])
+		require.NoError(t, err)
+		return id, native.(map[string]interface{})
+	}
+
+	// Metrics lacking some of the known tags or fields reuse the schema
+	buf, err := s.Serialize(metric.New(
+		"cpu",
+		map[string]string{"host": "a"},
+		map[string]interface{}{"usage_idle": 42.5, "count": int64(3)},
+		time.Unix(0, 0),
+	))
+	require.NoError(t, err)
+	id, record := decode(buf)
+	require.Equal(t, uint32(1), id)
+	require.Equal(t, map[string]interface{}{"long": int64(3)}, record["count"])
+
+	buf, err = s.Serialize(metric.New(
+		"cpu",
+		map[string]string{},
+		map[string]interface{}{"usage_idle": 17.0},
+		time.Unix(0, 0),
+	))
+	require.NoError(t, err)
+	id, record = decode(buf)
+	require.Equal(t, uint32(1), id)
+	require.Nil(t, record["host"])
+	require.Nil(t, record["count"])
+	require.Equal(t, map[string]interface{}{"double": 17.0}, record["usage_idle"])
+	require.Len(t, reg.schemas, 1)
+
+	// A new field extends the known columns and registers a new version
+	buf, err = s.Serialize(metric.New(
+		"cpu",
+		map[string]string{"host": "b"},
+		map[string]interface{}{"usage_user": 1.5},
+		time.Unix(0, 0),
+	))
+	require.NoError(t, err)
+	id, record = decode(buf)
+	require.Equal(t, uint32(2), id)
+	require.Nil(t, record["usage_idle"])
+	require.Equal(t, map[string]interface{}{"double": 1.5}, record["usage_user"])
+
+	expected := `{"type":"record","name":"cpu","fields":[` +
+		`{"name":"timestamp","type":"long"},` +
+		`{"name":"host","type":["null","string"],"default":null},` +
+		`{"name":"count","type":["null","long"],"default":null},` +
+		`{"name":"usage_idle","type":["null","double"],"default":null},` +
+		`{"name":"usage_user","type":["null","double"],"default":null}]}`
+	require.JSONEq(t, expected, reg.schemas[1])
+
+	// Metrics with the previous set of columns use the extended schema
+	buf, err = s.Serialize(metric.New(
+		"cpu",
+		map[string]string{"host": "a"},
+		map[string]interface{}{"usage_idle": 42.5, "count": int64(3)},
+		time.Unix(0, 0),
+	))
+	require.NoError(t, err)
+	id, _ = decode(buf)
+	require.Equal(t, uint32(2), id)
+	require.Equal(t, []int{1, 2}, reg.subjects["cpu"])
+	require.Equal(t, 2, reg.requests)
+}
+
+func TestSchemaLookupRefresh(t *testing.T) {
+	reg := newRegistry()
+	reg.schemas = []string{
+		`{"type":"record","name":"cpu","fields":[{"name":"timestamp","type":"long"},{"name":"value","type":"double"}]}`,
+		`{"type":"record","name":"cpu","fields":[{"name":"timestamp","type":"long"},{"name":"value","type":"double"},` +
+			`{"name":"host","type":["null","string"],"default":null}]}`,
+	}
+	reg.subjects["cpu"] = []int{1}
+	server := httptest.NewServer(reg)
+	defer server.Close()
+
+	s := &Serializer{
+		SchemaRegistry: server.URL,
+		SchemaMode:     "lookup",
+		Log:            testutil.Logger{},
+	}
+	require.NoError(t, s.Init())
+
+	m := metric.New("cpu", map[string]string{"host": "a"}, map[string]interface{}{"value": 1.0}, time.Unix(0, 0))
+	buf, err := s.Serialize(m)
+	require.NoError(t, err)
+	require.Equal(t, uint32(1), binary.BigEndian.Uint32(buf[1:5]))
+
+	// A new version is only used after the schema expired
+	reg.subjects["cpu"] = append(reg.subjects["cpu"], 2)
+	buf, err = s.Serialize(m)
+	require.NoError(t, err)
+	require.Equal(t, uint32(1), binary.BigEndian.Uint32(buf[1:5]))
+
+	s.lookup["cpu"].fetched = time.Now().Add(-time.Duration(s.SchemaTTL))
+	buf, err = s.Serialize(m)
+	require.NoError(t, err)
+	require.Equal(t, uint32(2), binary.BigEndian.Uint32(buf[1:5]))
+
+	// The previous schema is kept if the registry is unavailable
+	reg.unavailable = true
+	s.lookup["cpu"].fetched = time.Now().Add(-time.Duration(s.SchemaTTL))
+	buf, err = s.Serialize(m)
+	require.NoError(t, err)
+	require.Equal(t, uint32(2), binary.BigEndian.Uint32(buf[1:5]))
+	require.Equal(t, 3, reg.requests)
+}
+
+func TestSchemaConflict(t *testing.T) {
+	s := &Serializer{Log: testutil.Logger{}}
+	require.NoError(t, s.Init())
+
+	m := metric.New(
+		"cpu",
+		map[string]string{"usage-idle": "x"},
+		map[string]interface{}{"usage.idle": 42.5},
+		time.Unix(0, 0),
+	)
+	_, err := s.Serialize(m)
+	require.EqualError(t, err, `schema conflict in measurement "cpu": field "usage.idle" and tag "usage-idle" map to the same Avro field "usage_idle"`)
+}
+
+func TestSerializeWithoutRegistry(t *testing.T) {
+	s := &Serializer{
+		TimestampFormat: "unix_ns",
+		Log:             testutil.Logger{},
+	}
+	require.NoError(t, s.Init())
+
+	m := metric.New(
+		"cpu",
+		map[string]string{},
+		map[string]interface{}{"value": uint64(42)},
+		time.Unix(0, 1234),
+	)
+	buf, err := s.Serialize(m)
+	require.NoError(t, err)
+
+	require.Contains(t, s.derived, "cpu\x00cpu")
+	codec, err := goavro.NewCodec(s.derived["cpu\x00cpu"].definition)
+	require.NoError(t, err)
+	native, rest, err := codec.NativeFromBinary(buf)
+	require.NoError(t, err)
+	require.Empty(t, rest)
+	expected := map[string]interface{}{
+		"timestamp": int64(1234),
+		"value":     map[string]interface{}{"long": int64(42)},
+	}
+	require.Equal(t, expected, native)
+}
+
+func TestInitErrors(t *testing.T) {
+	s := &Serializer{SchemaMode: "lookup"}
+	require.ErrorContains(t, s.Init(), "requires 'avro_schema_registry'")
+
+	s = &Serializer{SchemaMode: "foo"}
+	require.ErrorContains(t, s.Init(), "invalid 'avro_schema_mode'")
+
+	s = &Serializer{TimestampFormat: "rfc3339"}
+	require.ErrorContains(t, s.Init(), "invalid 'avro_timestamp_format'")
+
+	s = &Serializer{SchemaTTL: config.Duration(-time.Second)}
+	require.ErrorContains(t, s.Init(), "invalid 'avro_schema_ttl'")
+}
+
+func BenchmarkSerialize(b *testing.B) {
+	s := &Serializer{Log: testutil.Logger{}}
+	require.NoError(b, s.Init())
+	metrics := serializers.BenchmarkMetrics(b)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, err := s.Serialize(metrics[i%len(metrics)])
+		require.NoError(b, err)
+	}
+}
@@ -0,0 +1,341 @@
+package avro
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/linkedin/goavro/v2"
+
+	"github.com/influxdata/telegraf"
+)
+
+type schemaField struct {
+	Name    string          `json:"name"`
+	Type    interface{}     `json:"type"`
+	Default json.RawMessage `json:"default,omitempty"`
+}
+
+type recordSchema struct {
+	Type      string        `json:"type"`
+	Name      string        `json:"name"`
+	Namespace string        `json:"namespace,omitempty"`
+	Fields    []schemaField `json:"fields"`
+}
+
+// column describes a tag or field of a derived record schema
+type column struct {
+	name string
+	typ  string
+	tag  bool
+}
+
+// sanitizeName converts the given name into a valid Avro name by replacing
+// all invalid characters with underscores.
+func sanitizeName(name string) string {
+	var b strings.Builder
+	for i, r := range name {
+		switch {
+		case r >= 'A' && r <= 'Z', r >= 'a' && r <= 'z', r == '_':
+			b.WriteRune(r)
+		case r >= '0' && r <= '9':
+			if i == 0 {
+				b.WriteRune('_')
+			}
+			b.WriteRune(r)
+		default:
+			b.WriteRune('_')
+		}
+	}
+	if b.Len() == 0 {
+		return "_"
+	}
+	return b.String()
+}
+
+// avroType returns the Avro primitive type for the given field value.
+func avroType(value interface{}) (string, error) {
+	switch value.(type) {
+	case int64:
+		return "long", nil
+	case uint64:
+		return "long", nil
+	case float64:
+		return "double", nil
+	case bool:
+		return "boolean", nil
+	case string:
+		return "string", nil
+	}
+	return "", fmt.Errorf("unsupported field type %T", value)
+}
+
+// nativeValue converts the field value into a value accepted by the Avro codec.
+func nativeValue(value interface{}) (interface{}, error) {
+	if v, ok := value.(uint64); ok {
+		if v > math.MaxInt64 {
+			return nil, fmt.Errorf("value %d overflows long", v)
+		}
+		return int64(v), nil
+	}
+	return value, nil
+}
+
+// deriveColumns returns the tag and field columns of the given metric
+// together with the native values keyed by the sanitized column name. The
+// timestamp is contained in the values but not in the columns.
+func (s *Serializer) deriveColumns(m telegraf.Metric) ([]column, map[string]interface{}, error) {
+	values := make(map[string]interface{}, len(m.TagList())+len(m.FieldList())+1)
+	origin := make(map[string]string, len(m.TagList())+len(m.FieldList())+1)
+	columns := make([]column, 0, len(m.TagList())+len(m.FieldList()))
+
+	tsName := sanitizeName(s.Timestamp)
+	origin[tsName] = "timestamp"
+	values[tsName] = s.timestamp(m)
+
+	for _, tag := range m.TagList() {
+		name := sanitizeName(tag.Key)
+		if other, found := origin[name]; found {
+			return nil, nil, fmt.Errorf("schema conflict in measurement %q: tag %q and %s map to the same Avro field %q", m.Name(), tag.Key, other, name)
+		}
+		origin[name] = fmt.Sprintf("tag %q", tag.Key)
+		values[name] = tag.Value
+		columns = append(columns, column{name: name, typ: "string", tag: true})
+	}
+
+	for _, field := range m.FieldList() {
+		name := sanitizeName(field.Key)
+		if other, found := origin[name]; found {
+			return nil, nil, fmt.Errorf("schema conflict in measurement %q: field %q and %s map to the same Avro field %q", m.Name(), field.Key, other, name)
+		}
+		origin[name] = fmt.Sprintf("field %q", field.Key)
+
+		typ, err := avroType(field.Value)
+		if err != nil {
+			return nil, nil, fmt.Errorf("field %q of measurement %q: %w", field.Key, m.Name(), err)
+		}
+		value, err := nativeValue(field.Value)
+		if err != nil {
+			return nil, nil, fmt.Errorf("field %q of measurement %q: %w", field.Key, m.Name(), err)
+		}
+		values[name] = value
+		columns = append(columns, column{name: name, typ: typ})
+	}
+
+	return columns, values, nil
+}
+
+// mergeColumns adds the columns seen in a metric to the known columns of a
+// derived schema. The second return value is true if a column was added or
+// changed its type. The result is sorted by name with tags before fields to
+// keep the schema stable independent of the order metrics are seen.
+func mergeColumns(measurement string, known, seen []column) ([]column, bool, error) {
+	merged := make([]column, len(known), len(known)+len(seen))
+	copy(merged, known)
+
+	var changed bool
+	for _, c := range seen {
+		idx := -1
+		for i, k := range merged {
+			if k.name == c.name {
+				idx = i
+				break
+			}
+		}
+		if idx < 0 {
+			merged = append(merged, c)
+			changed = true
+			continue
+		}
+		if merged[idx].tag != c.tag {
+			return nil, false, fmt.Errorf("schema conflict in measurement %q: Avro field %q is used for both a tag and a field", measurement, c.name)
+		}
+		if merged[idx].typ != c.typ {
+			merged[idx].typ = c.typ
+			changed = true
+		}
+	}
+
+	sort.Slice(merged, func(i, j int) bool {
+		if merged[i].tag != merged[j].tag {
+			return merged[i].tag
+		}
+		return merged[i].name < merged[j].name
+	})
+	return merged, changed, nil
+}
+
+// deriveDefinition creates the record schema for the given columns. All tags
+// and fields are nullable with a null default, so metrics lacking some of the
+// columns can be encoded with the same schema.
+func (s *Serializer) deriveDefinition(name string, columns []column) (string, error) {
+	schema := recordSchema{
+		Type:      "record",
+		Name:      name,
+		Namespace: s.Namespace,
+		Fields:    make([]schemaField, 0, len(columns)+1),
+	}
+	schema.Fields = append(schema.Fields, schemaField{Name: sanitizeName(s.Timestamp), Type: "long"})
+	for _, c := range columns {
+		schema.Fields = append(schema.Fields, schemaField{
+			Name:    c.name,
+			Type:    []string{"null", c.typ},
+			Default: json.RawMessage("null"),
+		})
+	}
+
+	buf, err := json.Marshal(schema)
+	if err != nil {
+		return "", err
+	}
+	return string(buf), nil
+}
+
+// deriveRecord creates the native record for the given values according to
+// the columns of a derived schema. Columns without a value are set to null.
+func (s *Serializer) deriveRecord(columns []column, values map[string]interface{}) map[string]interface{} {
+	tsName := sanitizeName(s.Timestamp)
+	record := make(map[string]interface{}, len(columns)+1)
+	record[tsName] = values[tsName]
+	for _, c := range columns {
+		if v, found := values[c.name]; found {
+			record[c.name] = goavro.Union(c.typ, v)
+		} else {
+			record[c.name] = nil
+		}
+	}
+	return record
+}
+
+// lookupField describes a field of a schema retrieved from the registry
+type lookupField struct {
+	name     string
+	types    []string
+	isUnion  bool
+	nullable bool
+}
+
+// parseLookupSchema extracts the field definitions of a record schema. Only
+// fields of primitive types and unions of primitive types are supported.
+func parseLookupSchema(schema string) ([]lookupField, error) {
+	var record struct {
+		Type   string `json:"type"`
+		Fields []struct {
+			Name string          `json:"name"`
+			Type json.RawMessage `json:"type"`
+		} `json:"fields"`
+	}
+	if err := json.Unmarshal([]byte(schema), &record); err != nil {
+		return nil, fmt.Errorf("unmarshalling schema failed: %w", err)
+	}
+	if record.Type != "record" {
+		return nil, fmt.Errorf("schema type %q is not a record", record.Type)
+	}
+
+	fields := make([]lookupField, 0, len(record.Fields))
+	for _, f := range record.Fields {
+		field := lookupField{name: f.Name}
+
+		var union []interface{}
+		if err := json.Unmarshal(f.Type, &union); err == nil {
+			field.isUnion = true
+			for _, t := range union {
+				name := primitiveName(t)
+				if name == "null" {
+					field.nullable = true
+					continue
+				}
+				field.types = append(field.types, name)
+			}
+		} else {
+			var t interface{}
+			if err := json.Unmarshal(f.Type, &t); err != nil {
+				return nil, fmt.Errorf("unmarshalling type of field %q failed: %w", f.Name, err)
+			}
+			field.types = []string{primitiveName(t)}
+		}
+		fields = append(fields, field)
+	}
+	return fields, nil
+}
+
+// primitiveName returns the name of a primitive type given either as plain
+// string or as object, e.g. with a logical type.
+func primitiveName(t interface{}) string {
+	switch v := t.(type) {
+	case string:
+		return v
+	case map[string]interface{}:
+		if name, ok := v["type"].(string); ok {
+			return name
+		}
+	}
+	return ""
+}
+
+// compatible returns true if the value can be encoded as the given Avro type.
+func compatible(typ string, value interface{}) bool {
+	switch value.(type) {
+	case string:
+		return typ == "string" || typ == "bytes"
+	case bool:
+		return typ == "boolean"
+	case int64:
+		return typ == "long" || typ == "int" || typ == "double" || typ == "float"
+	case float64:
+		return typ == "double" || typ == "float"
+	}
+	return false
+}
+
+// lookupRecord creates the native record for the given metric according to
+// the fields of a schema retrieved from the registry. Fields not present in
+// the metric are omitted to let the codec use the schema's default value.
+func (s *Serializer) lookupRecord(m telegraf.Metric, fields []lookupField) (map[string]interface{}, error) {
+	values := make(map[string]interface{}, len(m.TagList())+len(m.FieldList())+1)
+	for _, tag := range m.TagList() {
+		values[sanitizeName(tag.Key)] = tag.Value
+	}
+	for _, field := range m.FieldList() {
+		v, err := nativeValue(field.Value)
+		if err != nil {
+			return nil, fmt.Errorf("field %q of measurement %q: %w", field.Key, m.Name(), err)
+		}
+		values[sanitizeName(field.Key)] = v
+	}
+	values[sanitizeName(s.Timestamp)] = s.timestamp(m)
+
+	record := make(map[string]interface{}, len(fields))
+	for _, f := range fields {
+		value, found := values[f.name]
+		if !found {
+			if f.nullable {
+				record[f.name] = nil
+			}
+			continue
+		}
+
+		if !f.isUnion {
+			if len(f.types) == 1 && !compatible(f.types[0], value) {
+				return nil, fmt.Errorf("schema conflict in measurement %q: value %v of %q is not compatible with type %q", m.Name(), value, f.name, f.types[0])
+			}
+			record[f.name] = value
+			continue
+		}
+
+		var branch string
+		for _, t := range f.types {
+			if compatible(t, value) {
+				branch = t
+				break
+			}
+		}
+		if branch == "" {
+			return nil, fmt.Errorf("schema conflict in measurement %q: value %v of %q is not compatible with any of %v", m.Name(), value, f.name, f.types)
+		}
+		record[f.name] = goavro.Union(branch, value)
+	}
+	return record, nil
+}
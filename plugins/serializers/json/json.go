@@ -21,6 +21,7 @@ type Serializer struct {
 	Transformation      string          `toml:"json_transformation"`
 	NestedFieldsInclude []string        `toml:"json_nested_fields_include"`
 	NestedFieldsExclude []string        `toml:"json_nested_fields_exclude"`
+	Layout              string          `toml:"json_layout"`
 
 	nestedFields filter.Filter
 }
@@ -43,6 +44,14 @@ func (s *Serializer) Init() error {
 	}
 	s.TimestampUnits = config.Duration(t)
 
+	switch s.Layout {
+	case "":
+		s.Layout = "default"
+	case "default", "flattened", "field", "ecs", "columnar":
+	default:
+		return fmt.Errorf("invalid 'json_layout' %q", s.Layout)
+	}
+
 	if len(s.NestedFieldsInclude) > 0 || len(s.NestedFieldsExclude) > 0 {
 		f, err := filter.NewIncludeExcludeFilter(s.NestedFieldsInclude, s.NestedFieldsExclude)
 		if err != nil {
@@ -55,38 +64,45 @@ func (s *Serializer) Init() error {
 }
 
 func (s *Serializer) Serialize(metric telegraf.Metric) ([]byte, error) {
-	var obj interface{}
-	obj = s.createObject(metric)
-
-	if s.Transformation != "" {
-		var err error
-		if obj, err = s.transform(obj); err != nil {
-			if errors.Is(err, jsonata.ErrUndefined) {
-				return nil, fmt.Errorf("%w (maybe configured for batch mode?)", err)
+	// Some layouts produce multiple documents per metric, so output one
+	// document per line
+	var serialized []byte
+	for _, obj := range s.createObjects(metric) {
+		if s.Transformation != "" {
+			var err error
+			if obj, err = s.transform(obj); err != nil {
+				if errors.Is(err, jsonata.ErrUndefined) {
+					return nil, fmt.Errorf("%w (maybe configured for batch mode?)", err)
+				}
+				return nil, err
 			}
-			return nil, err
 		}
-	}
 
-	serialized, err := json.Marshal(obj)
-	if err != nil {
-		return nil, err
+		buf, err := json.Marshal(obj)
+		if err != nil {
+			return nil, err
+		}
+		serialized = append(serialized, buf...)
+		serialized = append(serialized, '\n')
 	}
-	serialized = append(serialized, '\n')
 
 	return serialized, nil
 }
 
 func (s *Serializer) SerializeBatch(metrics []telegraf.Metric) ([]byte, error) {
-	objects := make([]interface{}, 0, len(metrics))
-	for _, metric := range metrics {
-		m := s.createObject(metric)
-		objects = append(objects, m)
-	}
-
 	var obj interface{}
-	obj = map[string]interface{}{
-		"metrics": objects,
+	if s.Layout == "columnar" {
+		obj = map[string]interface{}{
+			"metrics": s.createColumns(metrics),
+		}
+	} else {
+		objects := make([]interface{}, 0, len(metrics))
+		for _, metric := range metrics {
+			objects = append(objects, s.createObjects(metric)...)
+		}
+		obj = map[string]interface{}{
+			"metrics": objects,
+		}
 	}
 
 	if s.Transformation != "" {
@@ -108,6 +124,21 @@ func (s *Serializer) SerializeBatch(metrics []telegraf.Metric) ([]byte, error) {
 	return serialized, nil
 }
 
+// createObjects returns the document(s) of the metric in the configured layout
+func (s *Serializer) createObjects(metric telegraf.Metric) []interface{} {
+	switch s.Layout {
+	case "flattened":
+		return []interface{}{s.createFlattenedObject(metric)}
+	case "field":
+		return s.createFieldObjects(metric)
+	case "ecs":
+		return []interface{}{s.createECSObject(metric)}
+	case "columnar":
+		return []interface{}{map[string]interface{}{"metrics": s.createColumns([]telegraf.Metric{metric})}}
+	}
+	return []interface{}{s.createObject(metric)}
+}
+
 func (s *Serializer) createObject(metric telegraf.Metric) map[string]interface{} {
 	m := make(map[string]interface{}, 4)
 
@@ -119,36 +150,47 @@ func (s *Serializer) createObject(metric telegraf.Metric) map[string]interface{}
 
 	fields := make(map[string]interface{}, len(metric.FieldList()))
 	for _, field := range metric.FieldList() {
-		val := field.Value
-		switch fv := field.Value.(type) {
-		case float64:
-			// JSON does not support these special values
-			if math.IsNaN(fv) || math.IsInf(fv, 0) {
-				continue
-			}
-		case string:
-			// Check for nested fields if any
-			if s.nestedFields != nil && s.nestedFields.Match(field.Key) {
-				bv := []byte(fv)
-				if json.Valid(bv) {
-					var nested interface{}
-					if err := json.Unmarshal(bv, &nested); err == nil {
-						val = nested
-					}
-				}
-			}
+		if val, ok := s.fieldValue(field); ok {
+			fields[field.Key] = val
 		}
-		fields[field.Key] = val
 	}
 	m["fields"] = fields
 
 	m["name"] = metric.Name()
+	m["timestamp"] = s.timestamp(metric)
+	return m
+}
+
+// fieldValue returns the JSON value of the field, decoding nested JSON if
+// configured. Values not representable in JSON are skipped.
+func (s *Serializer) fieldValue(field *telegraf.Field) (interface{}, bool) {
+	val := field.Value
+	switch fv := field.Value.(type) {
+	case float64:
+		// JSON does not support these special values
+		if math.IsNaN(fv) || math.IsInf(fv, 0) {
+			return nil, false
+		}
+	case string:
+		// Check for nested fields if any
+		if s.nestedFields != nil && s.nestedFields.Match(field.Key) {
+			bv := []byte(fv)
+			if json.Valid(bv) {
+				var nested interface{}
+				if err := json.Unmarshal(bv, &nested); err == nil {
+					val = nested
+				}
+			}
+		}
+	}
+	return val, true
+}
+
+func (s *Serializer) timestamp(metric telegraf.Metric) interface{} {
 	if s.TimestampFormat == "" {
-		m["timestamp"] = metric.Time().UnixNano() / int64(s.TimestampUnits)
-	} else {
-		m["timestamp"] = metric.Time().UTC().Format(s.TimestampFormat)
+		return metric.Time().UnixNano() / int64(s.TimestampUnits)
 	}
-	return m
+	return metric.Time().UTC().Format(s.TimestampFormat)
 }
 
 func (s *Serializer) transform(obj interface{}) (interface{}, error) {
@@ -6,6 +6,7 @@ import (
 	"math"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"testing"
 	"time"
@@ -453,6 +454,191 @@ func TestSerializeNesting(t *testing.T) {
 	}
 }
 
+func TestSerializeLayouts(t *testing.T) {
+	// Add the fields one by one to get a deterministic order for the
+	// field layout
+	m1 := metric.New("cpu", map[string]string{"host": "a", "cpu": "cpu0"}, map[string]interface{}{}, time.Unix(1700000000, 0))
+	m1.AddField("usage_idle", 91.5)
+	m1.AddField("usage_user", int64(3))
+	m1.AddField("inf", math.Inf(1))
+	m2 := metric.New("cpu", map[string]string{"host": "b"}, map[string]interface{}{}, time.Unix(1700000010, 0))
+	m2.AddField("throttled", true)
+	m2.AddField("usage_idle", 17.0)
+	input := []telegraf.Metric{m1, m2}
+
+	tests := []struct {
+		name     string
+		layout   string
+		single   string
+		expected string
+	}{
+		{
+			name:   "flattened",
+			layout: "flattened",
+			single: `{"name":"cpu","timestamp":1700000000,"tags.cpu":"cpu0","tags.host":"a","fields.usage_idle":91.5,"fields.usage_user":3}`,
+			expected: `{"metrics":[
+				{"name":"cpu","timestamp":1700000000,"tags.cpu":"cpu0","tags.host":"a","fields.usage_idle":91.5,"fields.usage_user":3},
+				{"name":"cpu","timestamp":1700000010,"tags.host":"b","fields.usage_idle":17,"fields.throttled":true}
+			]}`,
+		},
+		{
+			name:   "field",
+			layout: "field",
+			single: `{"name":"cpu","tags":{"cpu":"cpu0","host":"a"},"field":"usage_idle","value":91.5,"timestamp":1700000000}
+{"name":"cpu","tags":{"cpu":"cpu0","host":"a"},"field":"usage_user","value":3,"timestamp":1700000000}`,
+			expected: `{"metrics":[
+				{"name":"cpu","tags":{"cpu":"cpu0","host":"a"},"field":"usage_idle","value":91.5,"timestamp":1700000000},
+				{"name":"cpu","tags":{"cpu":"cpu0","host":"a"},"field":"usage_user","value":3,"timestamp":1700000000},
+				{"name":"cpu","tags":{"host":"b"},"field":"throttled","value":true,"timestamp":1700000010},
+				{"name":"cpu","tags":{"host":"b"},"field":"usage_idle","value":17,"timestamp":1700000010}
+			]}`,
+		},
+		{
+			name:   "ecs",
+			layout: "ecs",
+			single: `{"@timestamp":"2023-11-14T22:13:20Z","event":{"dataset":"cpu","module":"telegraf"},"metricset":{"name":"cpu"},` +
+				`"host":{"name":"a"},"labels":{"cpu":"cpu0"},"telegraf":{"cpu":{"usage_idle":91.5,"usage_user":3}}}`,
+			expected: `{"metrics":[
+				{"@timestamp":"2023-11-14T22:13:20Z","event":{"dataset":"cpu","module":"telegraf"},"metricset":{"name":"cpu"},
+				 "host":{"name":"a"},"labels":{"cpu":"cpu0"},"telegraf":{"cpu":{"usage_idle":91.5,"usage_user":3}}},
+				{"@timestamp":"2023-11-14T22:13:30Z","event":{"dataset":"cpu","module":"telegraf"},"metricset":{"name":"cpu"},
+				 "host":{"name":"b"},"telegraf":{"cpu":{"usage_idle":17,"throttled":true}}}
+			]}`,
+		},
+		{
+			name:   "columnar",
+			layout: "columnar",
+			single: `{"metrics":{"cpu":{"timestamp":[1700000000],"tags":{"cpu":["cpu0"],"host":["a"]},"fields":{"usage_idle":[91.5],"usage_user":[3]}}}}`,
+			expected: `{"metrics":{"cpu":{
+				"timestamp":[1700000000,1700000010],
+				"tags":{"cpu":["cpu0",null],"host":["a","b"]},
+				"fields":{"usage_idle":[91.5,17],"usage_user":[3,null],"throttled":[null,true]}
+			}}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := Serializer{Layout: tt.layout}
+			require.NoError(t, s.Init())
+
+			buf, err := s.Serialize(input[0])
+			require.NoError(t, err)
+			actual := strings.Split(strings.TrimSuffix(string(buf), "\n"), "\n")
+			expected := strings.Split(tt.single, "\n")
+			require.Len(t, actual, len(expected))
+			for i := range expected {
+				require.JSONEq(t, expected[i], actual[i])
+			}
+
+			buf, err = s.SerializeBatch(input)
+			require.NoError(t, err)
+			require.JSONEq(t, tt.expected, string(buf))
+		})
+	}
+}
+
+func TestSerializeLayoutFlattenedNested(t *testing.T) {
+	m := metric.New(
+		"data",
+		map[string]string{},
+		map[string]interface{}{"status": `{"state":"ok","ports":[80,443]}`},
+		time.Unix(0, 0),
+	)
+
+	s := Serializer{Layout: "flattened", NestedFieldsInclude: []string{"status"}}
+	require.NoError(t, s.Init())
+	buf, err := s.Serialize(m)
+	require.NoError(t, err)
+	require.JSONEq(t,
+		`{"name":"data","timestamp":0,"fields.status.state":"ok","fields.status.ports.0":80,"fields.status.ports.1":443}`,
+		string(buf),
+	)
+}
+
+func TestSerializeLayoutECSReservedKeys(t *testing.T) {
+	s := Serializer{Layout: "ecs"}
+	require.NoError(t, s.Init())
+
+	for _, name := range []string{"host", "event", "labels", "metricset", "@timestamp"} {
+		t.Run(name, func(t *testing.T) {
+			m := metric.New(
+				name,
+				map[string]string{"host": "a", "region": "eu"},
+				map[string]interface{}{"value": int64(42)},
+				time.Unix(1700000000, 0),
+			)
+			buf, err := s.Serialize(m)
+			require.NoError(t, err)
+
+			expected := fmt.Sprintf(
+				`{"@timestamp":"2023-11-14T22:13:20Z","event":{"dataset":%[1]q,"module":"telegraf"},"metricset":{"name":%[1]q},`+
+					`"host":{"name":"a"},"labels":{"region":"eu"},"telegraf":{%[1]q:{"value":42}}}`,
+				name,
+			)
+			require.JSONEq(t, expected, string(buf))
+		})
+	}
+}
+
+// Expressions producing the same output as the built-in layouts, used to
+// verify the layouts and to compare their performance
+var layoutTransformations = map[string]string{
+	"flattened": `{"metrics": [metrics.$merge([
+		{"name": name, "timestamp": timestamp},
+		$each(tags, function($v, $k) {{"tags." & $k: $v}}),
+		$each(fields, function($v, $k) {{"fields." & $k: $v}})
+	])]}`,
+	"field": `{"metrics": [metrics.(
+		$m := $;
+		$each(fields, function($v, $k) {
+			{"name": $m.name, "tags": $m.tags, "field": $k, "value": $v, "timestamp": $m.timestamp}
+		})
+	)]}`,
+}
+
+func TestSerializeLayoutsMatchTransformation(t *testing.T) {
+	input := []telegraf.Metric{
+		metric.New(
+			"cpu",
+			map[string]string{"host": "a", "cpu": "cpu0"},
+			map[string]interface{}{"usage_idle": 91.5, "usage_user": int64(3)},
+			time.Unix(1700000000, 0),
+		),
+		metric.New(
+			"mem",
+			map[string]string{"host": "a"},
+			map[string]interface{}{"used": int64(1024), "state": "ok"},
+			time.Unix(1700000010, 0),
+		),
+	}
+
+	for layout, transformation := range layoutTransformations {
+		t.Run(layout, func(t *testing.T) {
+			s := Serializer{Layout: layout}
+			require.NoError(t, s.Init())
+			expected, err := s.SerializeBatch(input)
+			require.NoError(t, err)
+
+			s = Serializer{Transformation: transformation}
+			require.NoError(t, s.Init())
+			actual, err := s.SerializeBatch(input)
+			require.NoError(t, err)
+
+			// The order of the field documents depends on the field order
+			var e, a map[string][]interface{}
+			require.NoError(t, json.Unmarshal(expected, &e))
+			require.NoError(t, json.Unmarshal(actual, &a))
+			require.ElementsMatch(t, e["metrics"], a["metrics"])
+		})
+	}
+}
+
+func TestSerializeLayoutInvalid(t *testing.T) {
+	s := Serializer{Layout: "foo"}
+	require.ErrorContains(t, s.Init(), `invalid 'json_layout' "foo"`)
+}
+
 type Config struct {
 	TimestampUnits          time.Duration `toml:"json_timestamp_units"`
 	TimestampFormat         string        `toml:"json_timestamp_format"`
@@ -512,3 +698,45 @@ func BenchmarkSerializeBatch(b *testing.B) {
 		require.NoError(b, err)
 	}
 }
+
+func benchmarkBatch(b *testing.B) []telegraf.Metric {
+	metrics := make([]telegraf.Metric, 0, 1000)
+	for i := range 1000 {
+		metrics = append(metrics, metric.New(
+			"cpu",
+			map[string]string{"host": "localhost", "cpu": "cpu" + strconv.Itoa(i%8)},
+			map[string]interface{}{"usage_idle": float64(i), "usage_user": 1.5, "usage_system": int64(i)},
+			time.Unix(int64(i), 0),
+		))
+	}
+	b.ResetTimer()
+	return metrics
+}
+
+func BenchmarkSerializeBatchLayout(b *testing.B) {
+	for _, layout := range []string{"default", "flattened", "field", "ecs", "columnar"} {
+		b.Run(layout, func(b *testing.B) {
+			s := &Serializer{Layout: layout}
+			require.NoError(b, s.Init())
+			metrics := benchmarkBatch(b)
+			for i := 0; i < b.N; i++ {
+				_, err := s.SerializeBatch(metrics)
+				require.NoError(b, err)
+			}
+		})
+	}
+}
+
+func BenchmarkSerializeBatchLayoutTransformation(b *testing.B) {
+	for _, layout := range []string{"flattened", "field"} {
+		b.Run(layout, func(b *testing.B) {
+			s := &Serializer{Transformation: layoutTransformations[layout]}
+			require.NoError(b, s.Init())
+			metrics := benchmarkBatch(b)
+			for i := 0; i < b.N; i++ {
+				_, err := s.SerializeBatch(metrics)
+				require.NoError(b, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,176 @@
+package json
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/influxdata/telegraf"
+)
+
+// createFlattenedObject returns a single-level document using dotted keys for
+// tags and fields. Nested JSON fields are flattened further.
+func (s *Serializer) createFlattenedObject(metric telegraf.Metric) map[string]interface{} {
+	m := make(map[string]interface{}, 2+len(metric.TagList())+len(metric.FieldList()))
+	m["name"] = metric.Name()
+	m["timestamp"] = s.timestamp(metric)
+	for _, tag := range metric.TagList() {
+		m["tags."+tag.Key] = tag.Value
+	}
+	for _, field := range metric.FieldList() {
+		if val, ok := s.fieldValue(field); ok {
+			flatten(m, "fields."+field.Key, val)
+		}
+	}
+	return m
+}
+
+func flatten(m map[string]interface{}, prefix string, value interface{}) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for k, nested := range v {
+			flatten(m, prefix+"."+k, nested)
+		}
+	case []interface{}:
+		for i, nested := range v {
+			flatten(m, prefix+"."+strconv.Itoa(i), nested)
+		}
+	default:
+		m[prefix] = value
+	}
+}
+
+// createFieldObjects returns one document per field of the metric
+func (s *Serializer) createFieldObjects(metric telegraf.Metric) []interface{} {
+	tags := make(map[string]string, len(metric.TagList()))
+	for _, tag := range metric.TagList() {
+		tags[tag.Key] = tag.Value
+	}
+	timestamp := s.timestamp(metric)
+
+	objects := make([]interface{}, 0, len(metric.FieldList()))
+	for _, field := range metric.FieldList() {
+		val, ok := s.fieldValue(field)
+		if !ok {
+			continue
+		}
+		objects = append(objects, map[string]interface{}{
+			"name":      metric.Name(),
+			"tags":      tags,
+			"field":     field.Key,
+			"value":     val,
+			"timestamp": timestamp,
+		})
+	}
+	return objects
+}
+
+// createECSObject returns a document following the Elastic Common Schema
+// conventions used by Metricbeat. The "host" tag is mapped to "host.name",
+// all other tags become labels and the fields are nested below the module
+// and measurement name, i.e. "telegraf.<name>", so measurements cannot
+// overwrite the reserved ECS keys.
+func (s *Serializer) createECSObject(metric telegraf.Metric) map[string]interface{} {
+	m := make(map[string]interface{}, 6)
+
+	if s.TimestampFormat == "" {
+		m["@timestamp"] = metric.Time().UTC().Format(time.RFC3339Nano)
+	} else {
+		m["@timestamp"] = metric.Time().UTC().Format(s.TimestampFormat)
+	}
+	m["event"] = map[string]interface{}{
+		"dataset": metric.Name(),
+		"module":  "telegraf",
+	}
+	m["metricset"] = map[string]interface{}{
+		"name": metric.Name(),
+	}
+
+	labels := make(map[string]string, len(metric.TagList()))
+	for _, tag := range metric.TagList() {
+		if tag.Key == "host" {
+			m["host"] = map[string]interface{}{"name": tag.Value}
+			continue
+		}
+		labels[tag.Key] = tag.Value
+	}
+	if len(labels) > 0 {
+		m["labels"] = labels
+	}
+
+	fields := make(map[string]interface{}, len(metric.FieldList()))
+	for _, field := range metric.FieldList() {
+		if val, ok := s.fieldValue(field); ok {
+			fields[field.Key] = val
+		}
+	}
+	m["telegraf"] = map[string]interface{}{metric.Name(): fields}
+
+	return m
+}
+
+// columns holds the values of a measurement in column-oriented form
+type columns struct {
+	timestamps []interface{}
+	tags       map[string][]interface{}
+	fields     map[string][]interface{}
+}
+
+// createColumns groups the metrics by measurement and returns one array per
+// tag and field. Values missing in a metric are set to null, so all arrays
+// of a measurement have the same length as its timestamp array.
+func (s *Serializer) createColumns(metrics []telegraf.Metric) map[string]interface{} {
+	groups := make(map[string]*columns)
+	for _, metric := range metrics {
+		c, found := groups[metric.Name()]
+		if !found {
+			c = &columns{
+				tags:   make(map[string][]interface{}),
+				fields: make(map[string][]interface{}),
+			}
+			groups[metric.Name()] = c
+		}
+		row := len(c.timestamps)
+		c.timestamps = append(c.timestamps, s.timestamp(metric))
+
+		for _, tag := range metric.TagList() {
+			c.tags[tag.Key] = appendColumn(c.tags[tag.Key], row, tag.Value)
+		}
+		for _, field := range metric.FieldList() {
+			if val, ok := s.fieldValue(field); ok {
+				c.fields[field.Key] = appendColumn(c.fields[field.Key], row, val)
+			}
+		}
+	}
+
+	result := make(map[string]interface{}, len(groups))
+	for name, c := range groups {
+		rows := len(c.timestamps)
+		tags := make(map[string]interface{}, len(c.tags))
+		for k, v := range c.tags {
+			tags[k] = padColumn(v, rows)
+		}
+		fields := make(map[string]interface{}, len(c.fields))
+		for k, v := range c.fields {
+			fields[k] = padColumn(v, rows)
+		}
+		result[name] = map[string]interface{}{
+			"timestamp": c.timestamps,
+			"tags":      tags,
+			"fields":    fields,
+		}
+	}
+	return result
+}
+
+// appendColumn sets the value of the given row, filling skipped rows with null
+func appendColumn(column []interface{}, row int, value interface{}) []interface{} {
+	column = padColumn(column, row)
+	return append(column, value)
+}
+
+func padColumn(column []interface{}, rows int) []interface{} {
+	for len(column) < rows {
+		column = append(column, nil)
+	}
+	return column
+}
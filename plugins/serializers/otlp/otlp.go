@@ -0,0 +1,106 @@
+package otlp
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/influxdata/influxdb-observability/common"
+	"github.com/influxdata/influxdb-observability/influx2otel"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/serializers"
+)
+
+type Serializer struct {
+	Encoding           string            `toml:"otlp_encoding"`
+	ResourceAttributes map[string]string `toml:"otlp_resource_attributes"`
+	Log                telegraf.Logger   `toml:"-"`
+
+	converter  *influx2otel.LineProtocolToOtelMetrics
+	marshaller pmetric.Marshaler
+}
+
+func (s *Serializer) Init() error {
+	switch s.Encoding {
+	case "", "protobuf":
+		s.Encoding = "protobuf"
+		s.marshaller = &pmetric.ProtoMarshaler{}
+	case "json":
+		s.marshaller = &pmetric.JSONMarshaler{}
+	default:
+		return fmt.Errorf("invalid 'otlp_encoding' %q", s.Encoding)
+	}
+
+	converter, err := influx2otel.NewLineProtocolToOtelMetrics(&otelLogger{s.Log})
+	if err != nil {
+		return err
+	}
+	s.converter = converter
+
+	return nil
+}
+
+func (s *Serializer) Serialize(metric telegraf.Metric) ([]byte, error) {
+	return s.SerializeBatch([]telegraf.Metric{metric})
+}
+
+// SerializeBatch converts all metrics into a single OTLP metrics message.
+// Metrics sharing the same resource and scope tags are grouped together.
+func (s *Serializer) SerializeBatch(metrics []telegraf.Metric) ([]byte, error) {
+	batch := s.converter.NewBatch()
+	for _, m := range metrics {
+		var vType common.InfluxMetricValueType
+		switch m.Type() {
+		case telegraf.Gauge:
+			vType = common.InfluxMetricValueTypeGauge
+		case telegraf.Untyped:
+			vType = common.InfluxMetricValueTypeUntyped
+		case telegraf.Counter:
+			vType = common.InfluxMetricValueTypeSum
+		case telegraf.Histogram:
+			vType = common.InfluxMetricValueTypeHistogram
+		case telegraf.Summary:
+			vType = common.InfluxMetricValueTypeSummary
+		default:
+			s.Log.Warnf("Unrecognized metric type %v", m.Type())
+			continue
+		}
+		if err := batch.AddPoint(m.Name(), m.Tags(), m.Fields(), m.Time(), vType); err != nil {
+			s.Log.Warnf("Failed to add point %q: %v", m.Name(), err)
+			continue
+		}
+	}
+
+	md := batch.GetMetrics()
+	for i := 0; i < md.ResourceMetrics().Len(); i++ {
+		attrs := md.ResourceMetrics().At(i).Resource().Attributes()
+		for k, v := range s.ResourceAttributes {
+			attrs.PutStr(k, v)
+		}
+	}
+
+	buf, err := s.marshaller.MarshalMetrics(md)
+	if err != nil {
+		return nil, fmt.Errorf("marshalling %s metrics failed: %w", s.Encoding, err)
+	}
+	return buf, nil
+}
+
+type otelLogger struct {
+	telegraf.Logger
+}
+
+// Debug logs a debug message, patterned after log.Print.
+func (l otelLogger) Debug(msg string, kv ...interface{}) {
+	format := msg + strings.Repeat(" %s=%q", len(kv)/2)
+	l.Logger.Debugf(format, kv...)
+}
+
+func init() {
+	serializers.Add("otlp",
+		func() telegraf.Serializer {
+			return &Serializer{}
+		},
+	)
+}
@@ -0,0 +1,162 @@
+package otlp
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/plugins/parsers/otlp"
+	"github.com/influxdata/telegraf/plugins/serializers"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+var testMetrics = []telegraf.Metric{
+	metric.New(
+		"process_memory_usage",
+		map[string]string{
+			"service.name":         "checkout",
+			"host.name":            "node-1",
+			"otel.library.name":    "io.opentelemetry.runtime",
+			"otel.library.version": "1.2.0",
+			"pool":                 "heap",
+		},
+		map[string]interface{}{"gauge": 52428800.0},
+		time.Unix(1700000000, 0),
+		telegraf.Gauge,
+	),
+	metric.New(
+		"http_requests",
+		map[string]string{"service.name": "checkout", "code": "200"},
+		map[string]interface{}{"counter": 1027.0},
+		time.Unix(1700000000, 0),
+		telegraf.Counter,
+	),
+	metric.New(
+		"http_request_duration",
+		map[string]string{"service.name": "checkout", "method": "GET"},
+		map[string]interface{}{
+			"0.1":   4.0,
+			"0.5":   7.0,
+			"1":     9.0,
+			"+Inf":  10.0,
+			"count": 10.0,
+			"sum":   2.5,
+		},
+		time.Unix(1700000000, 0),
+		telegraf.Histogram,
+	),
+	metric.New(
+		"rpc_latency",
+		map[string]string{"service.name": "checkout"},
+		map[string]interface{}{
+			"0.5":   0.2,
+			"0.99":  1.5,
+			"count": 100.0,
+			"sum":   25.5,
+		},
+		time.Unix(1700000000, 0),
+		telegraf.Summary,
+	),
+}
+
+func TestRoundTrip(t *testing.T) {
+	for _, encoding := range []string{"protobuf", "json"} {
+		t.Run(encoding, func(t *testing.T) {
+			s := &Serializer{Encoding: encoding, Log: testutil.Logger{}}
+			require.NoError(t, s.Init())
+
+			buf, err := s.SerializeBatch(testMetrics)
+			require.NoError(t, err)
+
+			parser := &otlp.Parser{Encoding: encoding, Log: testutil.Logger{}}
+			require.NoError(t, parser.Init())
+			actual, err := parser.Parse(buf)
+			require.NoError(t, err)
+
+			testutil.RequireMetricsEqual(t, testMetrics, actual, testutil.SortMetrics())
+		})
+	}
+}
+
+func TestSerializeStructure(t *testing.T) {
+	s := &Serializer{
+		ResourceAttributes: map[string]string{"deployment.environment": "production"},
+		Log:                testutil.Logger{},
+	}
+	require.NoError(t, s.Init())
+
+	buf, err := s.Serialize(testMetrics[0])
+	require.NoError(t, err)
+
+	md, err := (&pmetric.ProtoUnmarshaler{}).UnmarshalMetrics(buf)
+	require.NoError(t, err)
+	require.Equal(t, 1, md.ResourceMetrics().Len())
+
+	// Resource attributes and scope are taken from the tags
+	rm := md.ResourceMetrics().At(0)
+	require.Equal(t, map[string]interface{}{
+		"service.name":           "checkout",
+		"host.name":              "node-1",
+		"deployment.environment": "production",
+	}, rm.Resource().Attributes().AsRaw())
+	require.Equal(t, 1, rm.ScopeMetrics().Len())
+	sm := rm.ScopeMetrics().At(0)
+	require.Equal(t, "io.opentelemetry.runtime", sm.Scope().Name())
+	require.Equal(t, "1.2.0", sm.Scope().Version())
+
+	// The remaining tags are data-point attributes
+	require.Equal(t, 1, sm.Metrics().Len())
+	m := sm.Metrics().At(0)
+	require.Equal(t, "process_memory_usage", m.Name())
+	require.Equal(t, pmetric.MetricTypeGauge, m.Type())
+	dp := m.Gauge().DataPoints().At(0)
+	require.Equal(t, map[string]interface{}{"pool": "heap"}, dp.Attributes().AsRaw())
+	require.InDelta(t, 52428800.0, dp.DoubleValue(), 0)
+}
+
+func TestSerializeCounter(t *testing.T) {
+	s := &Serializer{Log: testutil.Logger{}}
+	require.NoError(t, s.Init())
+
+	buf, err := s.Serialize(testMetrics[1])
+	require.NoError(t, err)
+
+	md, err := (&pmetric.ProtoUnmarshaler{}).UnmarshalMetrics(buf)
+	require.NoError(t, err)
+	m := md.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics().At(0)
+	require.Equal(t, pmetric.MetricTypeSum, m.Type())
+	require.True(t, m.Sum().IsMonotonic())
+	require.Equal(t, pmetric.AggregationTemporalityCumulative, m.Sum().AggregationTemporality())
+}
+
+func TestInitErrors(t *testing.T) {
+	s := &Serializer{Encoding: "xml"}
+	require.ErrorContains(t, s.Init(), `invalid 'otlp_encoding' "xml"`)
+}
+
+func BenchmarkSerialize(b *testing.B) {
+	s := &Serializer{Log: testutil.Logger{}}
+	require.NoError(b, s.Init())
+	metrics := serializers.BenchmarkMetrics(b)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, err := s.Serialize(metrics[i%len(metrics)])
+		require.NoError(b, err)
+	}
+}
+
+func BenchmarkSerializeBatch(b *testing.B) {
+	s := &Serializer{Log: testutil.Logger{}}
+	require.NoError(b, s.Init())
+	m := serializers.BenchmarkMetrics(b)
+	metrics := m[:]
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, err := s.SerializeBatch(metrics)
+		require.NoError(b, err)
+	}
+}
@@ -0,0 +1,267 @@
+package parquet
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/apache/arrow-go/v18/parquet"
+	"github.com/apache/arrow-go/v18/parquet/compress"
+	"github.com/apache/arrow-go/v18/parquet/pqarrow"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/serializers"
+)
+
+var codecs = map[string]compress.Compression{
+	"none":   compress.Codecs.Uncompressed,
+	"snappy": compress.Codecs.Snappy,
+	"gzip":   compress.Codecs.Gzip,
+	"brotli": compress.Codecs.Brotli,
+	"zstd":   compress.Codecs.Zstd,
+	"lz4":    compress.Codecs.Lz4Raw,
+}
+
+type Serializer struct {
+	Compression       string `toml:"parquet_compression"`
+	MeasurementColumn string `toml:"parquet_measurement_column"`
+	TimestampColumn   string `toml:"parquet_timestamp_column"`
+
+	properties *parquet.WriterProperties
+}
+
+func (s *Serializer) Init() error {
+	if s.Compression == "" {
+		s.Compression = "snappy"
+	}
+	codec, found := codecs[s.Compression]
+	if !found {
+		return fmt.Errorf("invalid 'parquet_compression' %q", s.Compression)
+	}
+	s.properties = parquet.NewWriterProperties(parquet.WithCompression(codec))
+
+	if s.MeasurementColumn == "" {
+		s.MeasurementColumn = "measurement"
+	}
+	if s.TimestampColumn == "" {
+		s.TimestampColumn = "timestamp"
+	}
+	if s.MeasurementColumn == s.TimestampColumn {
+		return errors.New("'parquet_measurement_column' and 'parquet_timestamp_column' must differ")
+	}
+
+	return nil
+}
+
+func (s *Serializer) Serialize(metric telegraf.Metric) ([]byte, error) {
+	return s.SerializeBatch([]telegraf.Metric{metric})
+}
+
+// SerializeBatch writes all metrics into a single Parquet file. The schema
+// of the file is the union of the schemas inferred for each measurement.
+func (s *Serializer) SerializeBatch(metrics []telegraf.Metric) ([]byte, error) {
+	if len(metrics) == 0 {
+		return nil, nil
+	}
+
+	schema, err := s.createSchema(metrics)
+	if err != nil {
+		return nil, err
+	}
+
+	builder := array.NewRecordBuilder(memory.DefaultAllocator, schema)
+	defer builder.Release()
+	for _, m := range metrics {
+		if err := s.appendRow(builder, schema, m); err != nil {
+			return nil, err
+		}
+	}
+	record := builder.NewRecordBatch()
+	defer record.Release()
+
+	var buf bytes.Buffer
+	writer, err := pqarrow.NewFileWriter(schema, &buf, s.properties, pqarrow.DefaultWriterProps())
+	if err != nil {
+		return nil, fmt.Errorf("creating parquet writer failed: %w", err)
+	}
+	if err := writer.Write(record); err != nil {
+		return nil, fmt.Errorf("writing parquet record failed: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return nil, fmt.Errorf("closing parquet writer failed: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// column describes a column inferred for a measurement
+type column struct {
+	dtype arrow.DataType
+	isTag bool
+}
+
+// inferSchema determines the columns of a single measurement. A key used
+// both as tag and field or fields with varying types are reported as
+// conflict.
+func (s *Serializer) inferSchema(name string, metrics []telegraf.Metric) (map[string]column, error) {
+	columns := make(map[string]column)
+	for _, m := range metrics {
+		for _, tag := range m.TagList() {
+			if err := s.checkReserved(name, tag.Key); err != nil {
+				return nil, err
+			}
+			if c, found := columns[tag.Key]; found && !c.isTag {
+				return nil, fmt.Errorf("schema conflict in measurement %q: %q is used as tag and as field", name, tag.Key)
+			}
+			columns[tag.Key] = column{dtype: arrow.BinaryTypes.String, isTag: true}
+		}
+		for _, field := range m.FieldList() {
+			if err := s.checkReserved(name, field.Key); err != nil {
+				return nil, err
+			}
+			dtype, err := arrowType(field.Value)
+			if err != nil {
+				return nil, fmt.Errorf("field %q of measurement %q: %w", field.Key, name, err)
+			}
+			if c, found := columns[field.Key]; found {
+				if c.isTag {
+					return nil, fmt.Errorf("schema conflict in measurement %q: %q is used as tag and as field", name, field.Key)
+				}
+				if !arrow.TypeEqual(c.dtype, dtype) {
+					return nil, fmt.Errorf("schema conflict in measurement %q: field %q has type %s and %s", name, field.Key, c.dtype, dtype)
+				}
+				continue
+			}
+			columns[field.Key] = column{dtype: dtype}
+		}
+	}
+	return columns, nil
+}
+
+func (s *Serializer) checkReserved(name, key string) error {
+	if key == s.MeasurementColumn || key == s.TimestampColumn {
+		return fmt.Errorf("schema conflict in measurement %q: %q collides with the measurement or timestamp column", name, key)
+	}
+	return nil
+}
+
+// createSchema infers the schema for each measurement and merges them into
+// the schema of the file. Columns are sorted by name after the measurement and
+// timestamp columns.
+func (s *Serializer) createSchema(metrics []telegraf.Metric) (*arrow.Schema, error) {
+	var names []string
+	groups := make(map[string][]telegraf.Metric)
+	for _, m := range metrics {
+		if _, found := groups[m.Name()]; !found {
+			names = append(names, m.Name())
+		}
+		groups[m.Name()] = append(groups[m.Name()], m)
+	}
+
+	merged := make(map[string]arrow.DataType)
+	origin := make(map[string]string)
+	for _, name := range names {
+		columns, err := s.inferSchema(name, groups[name])
+		if err != nil {
+			return nil, err
+		}
+		for key, c := range columns {
+			if dtype, found := merged[key]; found {
+				if !arrow.TypeEqual(dtype, c.dtype) {
+					return nil, fmt.Errorf("schema conflict: column %q has type %s in measurement %q but %s in measurement %q",
+						key, dtype, origin[key], c.dtype, name)
+				}
+				continue
+			}
+			merged[key] = c.dtype
+			origin[key] = name
+		}
+	}
+
+	keys := make([]string, 0, len(merged))
+	for key := range merged {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	fields := make([]arrow.Field, 0, len(keys)+2)
+	fields = append(fields,
+		arrow.Field{Name: s.MeasurementColumn, Type: arrow.BinaryTypes.String},
+		arrow.Field{Name: s.TimestampColumn, Type: &arrow.TimestampType{Unit: arrow.Nanosecond, TimeZone: "UTC"}},
+	)
+	for _, key := range keys {
+		fields = append(fields, arrow.Field{Name: key, Type: merged[key], Nullable: true})
+	}
+
+	return arrow.NewSchema(fields, nil), nil
+}
+
+func (s *Serializer) appendRow(builder *array.RecordBuilder, schema *arrow.Schema, m telegraf.Metric) error {
+	for index, col := range schema.Fields() {
+		switch col.Name {
+		case s.MeasurementColumn:
+			builder.Field(index).(*array.StringBuilder).Append(m.Name())
+			continue
+		case s.TimestampColumn:
+			builder.Field(index).(*array.TimestampBuilder).Append(arrow.Timestamp(m.Time().UnixNano()))
+			continue
+		}
+
+		// Try to get the value from a field first, then from a tag.
+		value, ok := m.GetField(col.Name)
+		if !ok {
+			var tag string
+			if tag, ok = m.GetTag(col.Name); ok {
+				value = tag
+			}
+		}
+		if !ok {
+			builder.Field(index).AppendNull()
+			continue
+		}
+
+		switch b := builder.Field(index).(type) {
+		case *array.Int64Builder:
+			b.Append(value.(int64))
+		case *array.Uint64Builder:
+			b.Append(value.(uint64))
+		case *array.Float64Builder:
+			b.Append(value.(float64))
+		case *array.StringBuilder:
+			b.Append(value.(string))
+		case *array.BooleanBuilder:
+			b.Append(value.(bool))
+		default:
+			return fmt.Errorf("unsupported column type %s", col.Type)
+		}
+	}
+	return nil
+}
+
+func arrowType(value interface{}) (arrow.DataType, error) {
+	switch value.(type) {
+	case int64:
+		return arrow.PrimitiveTypes.Int64, nil
+	case uint64:
+		return arrow.PrimitiveTypes.Uint64, nil
+	case float64:
+		return arrow.PrimitiveTypes.Float64, nil
+	case string:
+		return arrow.BinaryTypes.String, nil
+	case bool:
+		return arrow.FixedWidthTypes.Boolean, nil
+	}
+	return nil, fmt.Errorf("unsupported type %T", value)
+}
+
+func init() {
+	serializers.Add("parquet",
+		func() telegraf.Serializer {
+			return &Serializer{}
+		},
+	)
+}
@@ -0,0 +1,207 @@
+package parquet
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/apache/arrow-go/v18/parquet"
+	"github.com/apache/arrow-go/v18/parquet/file"
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	parsers_parquet "github.com/influxdata/telegraf/plugins/parsers/parquet"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestRoundTrip(t *testing.T) {
+	s := &Serializer{}
+	require.NoError(t, s.Init())
+
+	input := []telegraf.Metric{
+		metric.New(
+			"cpu",
+			map[string]string{"host": "a"},
+			map[string]interface{}{"usage_idle": 42.5, "throttled": false},
+			time.Unix(1700000000, 1),
+		),
+		metric.New(
+			"mem",
+			map[string]string{"host": "a", "zone": "eu"},
+			map[string]interface{}{"used": int64(1024), "state": "ok"},
+			time.Unix(1700000000, 2),
+		),
+		metric.New(
+			"cpu",
+			map[string]string{"host": "b"},
+			map[string]interface{}{"usage_idle": 17.0},
+			time.Unix(1700000010, 3),
+		),
+	}
+
+	buf, err := s.SerializeBatch(input)
+	require.NoError(t, err)
+
+	parser := &parsers_parquet.Parser{
+		MeasurementColumn: "measurement",
+		TagColumns:        []string{"host", "zone"},
+		TimestampColumn:   "timestamp",
+		TimestampFormat:   "unix_ns",
+	}
+	require.NoError(t, parser.Init())
+	actual, err := parser.Parse(buf)
+	require.NoError(t, err)
+
+	// Missing columns are null and therefore skipped by the parser
+	testutil.RequireMetricsEqual(t, input, actual)
+}
+
+func TestSchema(t *testing.T) {
+	s := &Serializer{}
+	require.NoError(t, s.Init())
+
+	input := []telegraf.Metric{
+		metric.New(
+			"disk",
+			map[string]string{"path": "/"},
+			map[string]interface{}{"free": uint64(1), "used_percent": 12.5, "ro": true},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"system",
+			map[string]string{},
+			map[string]interface{}{"load1": 0.5, "uptime": int64(100), "path": "/bin"},
+			time.Unix(0, 0),
+		),
+	}
+	buf, err := s.SerializeBatch(input)
+	require.NoError(t, err)
+
+	reader, err := file.NewParquetReader(bytes.NewReader(buf))
+	require.NoError(t, err)
+	defer reader.Close()
+
+	require.EqualValues(t, 2, reader.NumRows())
+	schema := reader.MetaData().Schema
+	expected := []struct {
+		name     string
+		physical parquet.Type
+	}{
+		{"measurement", parquet.Types.ByteArray},
+		{"timestamp", parquet.Types.Int64},
+		{"free", parquet.Types.Int64},
+		{"load1", parquet.Types.Double},
+		{"path", parquet.Types.ByteArray},
+		{"ro", parquet.Types.Boolean},
+		{"uptime", parquet.Types.Int64},
+		{"used_percent", parquet.Types.Double},
+	}
+	require.Equal(t, len(expected), schema.NumColumns())
+	for i, e := range expected {
+		require.Equal(t, e.name, schema.Column(i).Name())
+		require.Equal(t, e.physical, schema.Column(i).PhysicalType())
+	}
+	require.Equal(t, "Timestamp(isAdjustedToUTC=true, timeUnit=nanoseconds, is_from_converted_type=false, force_set_converted_type=false)",
+		schema.Column(1).LogicalType().String())
+}
+
+func TestCompression(t *testing.T) {
+	m := metric.New("cpu", map[string]string{}, map[string]interface{}{"value": 42.0}, time.Unix(0, 0))
+
+	for name, codec := range codecs {
+		t.Run(name, func(t *testing.T) {
+			s := &Serializer{Compression: name}
+			require.NoError(t, s.Init())
+
+			buf, err := s.Serialize(m)
+			require.NoError(t, err)
+
+			reader, err := file.NewParquetReader(bytes.NewReader(buf))
+			require.NoError(t, err)
+			defer reader.Close()
+
+			col, err := reader.MetaData().RowGroup(0).ColumnChunk(0)
+			require.NoError(t, err)
+			require.Equal(t, codec, col.Compression())
+		})
+	}
+}
+
+func TestSchemaConflicts(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    []telegraf.Metric
+		expected string
+	}{
+		{
+			name: "field type",
+			input: []telegraf.Metric{
+				metric.New("cpu", map[string]string{}, map[string]interface{}{"value": 42.0}, time.Unix(0, 0)),
+				metric.New("cpu", map[string]string{}, map[string]interface{}{"value": int64(42)}, time.Unix(0, 0)),
+			},
+			expected: `schema conflict in measurement "cpu": field "value" has type float64 and int64`,
+		},
+		{
+			name: "tag and field",
+			input: []telegraf.Metric{
+				metric.New("cpu", map[string]string{"state": "ok"}, map[string]interface{}{"value": 42.0}, time.Unix(0, 0)),
+				metric.New("cpu", map[string]string{}, map[string]interface{}{"state": "ok"}, time.Unix(0, 0)),
+			},
+			expected: `schema conflict in measurement "cpu": "state" is used as tag and as field`,
+		},
+		{
+			name: "across measurements",
+			input: []telegraf.Metric{
+				metric.New("cpu", map[string]string{}, map[string]interface{}{"value": 42.0}, time.Unix(0, 0)),
+				metric.New("mem", map[string]string{}, map[string]interface{}{"value": true}, time.Unix(0, 0)),
+			},
+			expected: `schema conflict: column "value" has type float64 in measurement "cpu" but bool in measurement "mem"`,
+		},
+		{
+			name: "reserved column",
+			input: []telegraf.Metric{
+				metric.New("cpu", map[string]string{"measurement": "x"}, map[string]interface{}{"value": 42.0}, time.Unix(0, 0)),
+			},
+			expected: `schema conflict in measurement "cpu": "measurement" collides with the measurement or timestamp column`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &Serializer{}
+			require.NoError(t, s.Init())
+			_, err := s.SerializeBatch(tt.input)
+			require.EqualError(t, err, tt.expected)
+		})
+	}
+}
+
+func TestInitErrors(t *testing.T) {
+	s := &Serializer{Compression: "lzo"}
+	require.ErrorContains(t, s.Init(), `invalid 'parquet_compression' "lzo"`)
+
+	s = &Serializer{MeasurementColumn: "time", TimestampColumn: "time"}
+	require.ErrorContains(t, s.Init(), "must differ")
+}
+
+func BenchmarkSerializeBatch(b *testing.B) {
+	s := &Serializer{}
+	require.NoError(b, s.Init())
+
+	metrics := make([]telegraf.Metric, 0, 1000)
+	for i := range 1000 {
+		metrics = append(metrics, metric.New(
+			"cpu",
+			map[string]string{"host": "localhost", "cpu": "cpu0"},
+			map[string]interface{}{"usage_idle": float64(i), "usage_user": 1.5},
+			time.Unix(int64(i), 0),
+		))
+	}
+
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		_, err := s.SerializeBatch(metrics)
+		require.NoError(b, err)
+	}
+}
@@ -24,6 +24,7 @@ type promMetric struct {
 	labels    []labelPair
 	time      time.Time
 	addTime   time.Time
+	created   time.Time
 	scaler    *scaler
 	histogram *histogram
 	summary   *summary
@@ -35,12 +36,14 @@ type labelPair struct {
 }
 
 type scaler struct {
-	value float64
+	value    float64
+	exemplar *exemplar
 }
 
 type bucket struct {
-	bound float64
-	count uint64
+	bound    float64
+	count    uint64
+	exemplar *exemplar
 }
 
 type quantile struct {
@@ -58,6 +61,7 @@ func (h *histogram) merge(b bucket) {
 	for i := range h.buckets {
 		if h.buckets[i].bound == b.bound {
 			h.buckets[i].count = b.count
+			h.buckets[i].exemplar = b.exemplar
 			return
 		}
 	}
@@ -145,6 +149,11 @@ func (c *Collection) createLabels(metric telegraf.Metric) []labelPair {
 			}
 		}
 
+		// Exemplar labels are attached to the sample instead of the series.
+		if c.config.OpenMetrics && strings.HasPrefix(tag.Key, exemplarTagPrefix) {
+			continue
+		}
+
 		name, ok := c.sanitizeLabelName(tag.Key)
 		if !ok {
 			continue
@@ -192,12 +201,21 @@ func (c *Collection) createLabels(metric telegraf.Metric) []labelPair {
 func (c *Collection) Add(m telegraf.Metric, now time.Time) {
 	labels := c.createLabels(m)
 	for _, field := range m.FieldList() {
+		// Created timestamps and exemplars are attached to the sample they
+		// belong to instead of forming a series on their own.
+		if c.config.OpenMetrics && isOpenMetricsAuxField(m, field.Key) {
+			continue
+		}
+
 		metricName := MetricName(m.Name(), field.Key, m.Type())
 		metricName, ok := c.sanitizeMetricName(metricName)
 		if !ok {
 			continue
 		}
 		metricType := c.config.TypeMappings.DetermineType(metricName, m)
+		if c.config.OpenMetrics && metricType == telegraf.Counter && !strings.HasSuffix(metricName, "_total") {
+			metricName += "_total"
+		}
 
 		family := metricFamily{
 			name: metricName,
@@ -242,6 +260,10 @@ func (c *Collection) Add(m telegraf.Metric, now time.Time) {
 				addTime: now,
 				scaler:  &scaler{value: value},
 			}
+			if c.config.OpenMetrics {
+				existingMetric.created, _ = createdTime(m, field.Key)
+				existingMetric.scaler.exemplar = c.createExemplar(m, field.Key)
+			}
 
 			singleEntry.metrics[metricKey] = existingMetric
 		case telegraf.Histogram:
@@ -272,10 +294,14 @@ func (c *Collection) Add(m telegraf.Metric, now time.Time) {
 					continue
 				}
 
-				existingMetric.histogram.merge(bucket{
+				b := bucket{
 					bound: bound,
 					count: count,
-				})
+				}
+				if c.config.OpenMetrics {
+					b.exemplar = c.createExemplar(m, field.Key)
+				}
+				existingMetric.histogram.merge(b)
 			case strings.HasSuffix(field.Key, "_sum"):
 				sum, ok := SampleSum(field.Value)
 				if !ok {
@@ -283,6 +309,9 @@ func (c *Collection) Add(m telegraf.Metric, now time.Time) {
 				}
 
 				existingMetric.histogram.sum = sum
+				if created, ok := createdTime(m, strings.TrimSuffix(field.Key, "_sum")); ok && c.config.OpenMetrics {
+					existingMetric.created = created
+				}
 			case strings.HasSuffix(field.Key, "_count"):
 				count, ok := SampleCount(field.Value)
 				if !ok {
@@ -290,6 +319,9 @@ func (c *Collection) Add(m telegraf.Metric, now time.Time) {
 				}
 
 				existingMetric.histogram.count = count
+				if created, ok := createdTime(m, strings.TrimSuffix(field.Key, "_count")); ok && c.config.OpenMetrics {
+					existingMetric.created = created
+				}
 			default:
 				continue
 			}
@@ -315,6 +347,9 @@ func (c *Collection) Add(m telegraf.Metric, now time.Time) {
 				}
 
 				existingMetric.summary.sum = sum
+				if created, ok := createdTime(m, strings.TrimSuffix(field.Key, "_sum")); ok && c.config.OpenMetrics {
+					existingMetric.created = created
+				}
 			case strings.HasSuffix(field.Key, "_count"):
 				count, ok := SampleCount(field.Value)
 				if !ok {
@@ -322,6 +357,9 @@ func (c *Collection) Add(m telegraf.Metric, now time.Time) {
 				}
 
 				existingMetric.summary.count = count
+				if created, ok := createdTime(m, strings.TrimSuffix(field.Key, "_count")); ok && c.config.OpenMetrics {
+					existingMetric.created = created
+				}
 			default:
 				quantileTag, ok := m.GetTag("quantile")
 				if !ok {
@@ -433,9 +471,23 @@ func (c *Collection) GetProto() []*dto.MetricFamily {
 			mf.Help = proto.String(helpString)
 		}
 
-		for _, metric := range c.GetMetrics(entry) {
+		metrics := c.GetMetrics(entry)
+
+		var unit string
+		if c.config.OpenMetrics {
+			unit = familyUnit(entry.family, metrics)
+			if unit != "" {
+				mf.Unit = proto.String(unit)
+			}
+		}
+
+		for _, metric := range metrics {
 			l := make([]*dto.LabelPair, 0, len(metric.labels))
 			for _, label := range metric.labels {
+				// The unit is exposed as family metadata instead of a label
+				if unit != "" && label.name == unitLabel {
+					continue
+				}
 				l = append(l, &dto.LabelPair{
 					Name:  proto.String(label.name),
 					Value: proto.String(label.value),
@@ -455,6 +507,10 @@ func (c *Collection) GetProto() []*dto.MetricFamily {
 				m.Gauge = &dto.Gauge{Value: proto.Float64(metric.scaler.value)}
 			case telegraf.Counter:
 				m.Counter = &dto.Counter{Value: proto.Float64(metric.scaler.value)}
+				if c.config.OpenMetrics {
+					m.Counter.CreatedTimestamp = createdTimestamp(metric.created)
+					m.Counter.Exemplar = metric.scaler.exemplar.proto()
+				}
 			case telegraf.Untyped:
 				m.Untyped = &dto.Untyped{Value: proto.Float64(metric.scaler.value)}
 			case telegraf.Histogram:
@@ -463,6 +519,7 @@ func (c *Collection) GetProto() []*dto.MetricFamily {
 					buckets = append(buckets, &dto.Bucket{
 						UpperBound:      proto.Float64(bucket.bound),
 						CumulativeCount: proto.Uint64(bucket.count),
+						Exemplar:        bucket.exemplar.proto(),
 					})
 				}
 
@@ -471,6 +528,9 @@ func (c *Collection) GetProto() []*dto.MetricFamily {
 					SampleCount: proto.Uint64(metric.histogram.count),
 					SampleSum:   proto.Float64(metric.histogram.sum),
 				}
+				if c.config.OpenMetrics {
+					m.Histogram.CreatedTimestamp = createdTimestamp(metric.created)
+				}
 			case telegraf.Summary:
 				quantiles := make([]*dto.Quantile, 0, len(metric.summary.quantiles))
 				for _, quantile := range metric.summary.quantiles {
@@ -485,6 +545,9 @@ func (c *Collection) GetProto() []*dto.MetricFamily {
 					SampleCount: proto.Uint64(metric.summary.count),
 					SampleSum:   proto.Float64(metric.summary.sum),
 				}
+				if c.config.OpenMetrics {
+					m.Summary.CreatedTimestamp = createdTimestamp(metric.created)
+				}
 			default:
 				panic("unknown telegraf.ValueType")
 			}
@@ -0,0 +1,168 @@
+package prometheus
+
+import (
+	"math"
+	"strings"
+	"time"
+
+	dto "github.com/prometheus/client_model/go"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/influxdata/telegraf"
+)
+
+const (
+	// Tags with this prefix are turned into exemplar labels, e.g. a tag
+	// "exemplar_trace_id" becomes the exemplar label "trace_id".
+	exemplarTagPrefix = "exemplar_"
+	// Fields carrying the exemplar value and timestamp of the sample of the
+	// same name without the suffix.
+	exemplarSuffix          = "_exemplar"
+	exemplarTimestampSuffix = "_exemplar_timestamp"
+	// Field carrying the creation time of a counter, histogram or summary
+	createdSuffix = "_created"
+	// Label carrying the unit of the metric family
+	unitLabel = "unit"
+)
+
+type exemplar struct {
+	labels []labelPair
+	value  float64
+	time   time.Time
+}
+
+func (e *exemplar) proto() *dto.Exemplar {
+	if e == nil {
+		return nil
+	}
+
+	labels := make([]*dto.LabelPair, 0, len(e.labels))
+	for _, label := range e.labels {
+		labels = append(labels, &dto.LabelPair{
+			Name:  proto.String(label.name),
+			Value: proto.String(label.value),
+		})
+	}
+
+	ex := &dto.Exemplar{
+		Label: labels,
+		Value: proto.Float64(e.value),
+	}
+	if !e.time.IsZero() {
+		ex.Timestamp = timestamppb.New(e.time)
+	}
+	return ex
+}
+
+// isOpenMetricsAuxField returns true if the given field only carries
+// additional information, like the created timestamp or an exemplar, for
+// another field of the metric.
+func isOpenMetricsAuxField(m telegraf.Metric, key string) bool {
+	switch {
+	case strings.HasSuffix(key, exemplarTimestampSuffix):
+		return m.HasField(strings.TrimSuffix(key, exemplarTimestampSuffix))
+	case strings.HasSuffix(key, exemplarSuffix):
+		return m.HasField(strings.TrimSuffix(key, exemplarSuffix))
+	case strings.HasSuffix(key, createdSuffix):
+		base := strings.TrimSuffix(key, createdSuffix)
+		switch m.Type() {
+		case telegraf.Counter:
+			return m.HasField(base)
+		case telegraf.Histogram, telegraf.Summary:
+			return m.HasField(base+"_count") || m.HasField(base+"_sum")
+		}
+	}
+	return false
+}
+
+// createdTime returns the creation time stored in the "<base>_created" field
+// as seconds since epoch.
+func createdTime(m telegraf.Metric, base string) (time.Time, bool) {
+	raw, ok := m.GetField(base + createdSuffix)
+	if !ok {
+		return time.Time{}, false
+	}
+	v, ok := SampleValue(raw)
+	if !ok || math.IsNaN(v) || math.IsInf(v, 0) {
+		return time.Time{}, false
+	}
+	return secondsToTime(v), true
+}
+
+func createdTimestamp(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
+}
+
+// createExemplar returns the exemplar for the given field if the metric
+// contains a corresponding exemplar value.
+func (c *Collection) createExemplar(m telegraf.Metric, key string) *exemplar {
+	raw, ok := m.GetField(key + exemplarSuffix)
+	if !ok {
+		return nil
+	}
+	value, ok := SampleValue(raw)
+	if !ok {
+		return nil
+	}
+
+	e := &exemplar{value: value}
+	if raw, ok := m.GetField(key + exemplarTimestampSuffix); ok {
+		if ts, ok := SampleValue(raw); ok {
+			e.time = secondsToTime(ts)
+		}
+	}
+
+	for _, tag := range m.TagList() {
+		if !strings.HasPrefix(tag.Key, exemplarTagPrefix) {
+			continue
+		}
+		name, ok := c.sanitizeLabelName(strings.TrimPrefix(tag.Key, exemplarTagPrefix))
+		if !ok {
+			continue
+		}
+		e.labels = append(e.labels, labelPair{name: name, value: tag.Value})
+	}
+
+	return e
+}
+
+// familyUnit returns the unit of the family if all series share the same
+// unit label and the family name carries the unit as suffix, as required by
+// the OpenMetrics specification. Otherwise, the unit is kept as a label.
+func familyUnit(family metricFamily, metrics []*promMetric) string {
+	var unit string
+	for _, metric := range metrics {
+		var u string
+		for _, label := range metric.labels {
+			if label.name == unitLabel {
+				u = label.value
+				break
+			}
+		}
+		if u == "" || (unit != "" && u != unit) {
+			return ""
+		}
+		unit = u
+	}
+	if unit == "" {
+		return ""
+	}
+
+	name := family.name
+	if family.typ == telegraf.Counter {
+		name = strings.TrimSuffix(name, "_total")
+	}
+	if !strings.HasSuffix(name, "_"+unit) {
+		return ""
+	}
+	return unit
+}
+
+func secondsToTime(v float64) time.Time {
+	sec, frac := math.Modf(v)
+	return time.Unix(int64(sec), int64(math.Round(frac*1e9)))
+}
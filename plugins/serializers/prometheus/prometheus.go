@@ -29,6 +29,9 @@ type FormatConfig struct {
 	// NameSanitization controls how metric names and label names are sanitized.
 	// Valid values: "legacy" (ASCII-only rules), "utf8" (allows UTF-8 names).
 	NameSanitization string `toml:"prometheus_name_sanitization"`
+	// OpenMetrics enables the OpenMetrics 1.0 text format including units,
+	// created timestamps and exemplars.
+	OpenMetrics bool `toml:"prometheus_openmetrics"`
 }
 
 // MetricTypes defines the mapping of metric names to their types.
@@ -91,6 +94,18 @@ func (s *Serializer) SerializeBatch(metrics []telegraf.Metric) ([]byte, error) {
 	}
 
 	var buf bytes.Buffer
+	if s.OpenMetrics {
+		for _, mf := range coll.GetProto() {
+			if _, err := expfmt.MetricFamilyToOpenMetrics(&buf, mf, expfmt.WithCreatedLines()); err != nil {
+				return nil, err
+			}
+		}
+		if _, err := expfmt.FinalizeOpenMetrics(&buf); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+
 	for _, mf := range coll.GetProto() {
 		enc := expfmt.NewEncoder(&buf, expfmt.NewFormat(expfmt.TypeTextPlain))
 		err := enc.Encode(mf)
@@ -9,7 +9,9 @@ import (
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/plugins/parsers/openmetrics"
 	"github.com/influxdata/telegraf/plugins/serializers"
+	"github.com/influxdata/telegraf/testutil"
 )
 
 func TestSerialize(t *testing.T) {
@@ -834,3 +836,263 @@ func TestNameSanitizationValidation(t *testing.T) {
 		})
 	}
 }
+
+func TestSerializeOpenMetrics(t *testing.T) {
+	tests := []struct {
+		name     string
+		metrics  []telegraf.Metric
+		expected string
+	}{
+		{
+			name: "counter with created and exemplar",
+			metrics: []telegraf.Metric{
+				metric.New(
+					"prometheus",
+					map[string]string{
+						"code":              "200",
+						"exemplar_trace_id": "abc123",
+					},
+					map[string]interface{}{
+						"http_requests":                    42.0,
+						"http_requests_created":            1700000000.5,
+						"http_requests_exemplar":           1.0,
+						"http_requests_exemplar_timestamp": 1700000010.0,
+					},
+					time.Unix(1700000020, 0),
+					telegraf.Counter,
+				),
+			},
+			expected: `
+# HELP http_requests Telegraf collected metric
+# TYPE http_requests counter
+http_requests_total{code="200"} 42.0 # {trace_id="abc123"} 1.0 1.70000001e+09
+http_requests_created{code="200"} 1.7000000005e+09
+# EOF
+`,
+		},
+		{
+			name: "gauge with unit",
+			metrics: []telegraf.Metric{
+				metric.New(
+					"prometheus",
+					map[string]string{
+						"unit": "bytes",
+						"host": "a",
+					},
+					map[string]interface{}{
+						"memory_used_bytes": 1024.0,
+					},
+					time.Unix(0, 0),
+					telegraf.Gauge,
+				),
+			},
+			expected: `
+# HELP memory_used_bytes Telegraf collected metric
+# TYPE memory_used_bytes gauge
+# UNIT memory_used_bytes bytes
+memory_used_bytes{host="a"} 1024.0
+# EOF
+`,
+		},
+		{
+			name: "unit not matching the name is kept as label",
+			metrics: []telegraf.Metric{
+				metric.New(
+					"prometheus",
+					map[string]string{
+						"unit": "bytes",
+					},
+					map[string]interface{}{
+						"memory_used": 1024.0,
+					},
+					time.Unix(0, 0),
+					telegraf.Gauge,
+				),
+			},
+			expected: `
+# HELP memory_used Telegraf collected metric
+# TYPE memory_used gauge
+memory_used{unit="bytes"} 1024.0
+# EOF
+`,
+		},
+		{
+			name: "histogram with bucket exemplar",
+			metrics: []telegraf.Metric{
+				metric.New(
+					"prometheus",
+					map[string]string{},
+					map[string]interface{}{
+						"latency_seconds_sum":     2.5,
+						"latency_seconds_count":   3.0,
+						"latency_seconds_created": 1700000000.0,
+					},
+					time.Unix(0, 0),
+					telegraf.Histogram,
+				),
+				metric.New(
+					"prometheus",
+					map[string]string{
+						"le":                "0.5",
+						"exemplar_trace_id": "xyz",
+					},
+					map[string]interface{}{
+						"latency_seconds_bucket":          1.0,
+						"latency_seconds_bucket_exemplar": 0.25,
+					},
+					time.Unix(0, 0),
+					telegraf.Histogram,
+				),
+				metric.New(
+					"prometheus",
+					map[string]string{
+						"le": "+Inf",
+					},
+					map[string]interface{}{
+						"latency_seconds_bucket": 3.0,
+					},
+					time.Unix(0, 0),
+					telegraf.Histogram,
+				),
+			},
+			expected: `
+# HELP latency_seconds Telegraf collected metric
+# TYPE latency_seconds histogram
+latency_seconds_bucket{le="0.5"} 1 # {trace_id="xyz"} 0.25
+latency_seconds_bucket{le="+Inf"} 3
+latency_seconds_sum 2.5
+latency_seconds_count 3
+latency_seconds_created 1.7e+09
+# EOF
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &Serializer{
+				FormatConfig{
+					SortMetrics: true,
+					OpenMetrics: true,
+				},
+			}
+			require.NoError(t, s.Init())
+
+			actual, err := s.SerializeBatch(tt.metrics)
+			require.NoError(t, err)
+			require.Equal(t, strings.TrimSpace(tt.expected), strings.TrimSpace(string(actual)))
+		})
+	}
+}
+
+func TestOpenMetricsRoundTrip(t *testing.T) {
+	expected := []telegraf.Metric{
+		metric.New(
+			"prometheus",
+			map[string]string{
+				"code":              "200",
+				"exemplar_trace_id": "abc123",
+			},
+			map[string]interface{}{
+				"http_requests":                    42.0,
+				"http_requests_created":            1700000000.5,
+				"http_requests_exemplar":           1.0,
+				"http_requests_exemplar_timestamp": 1700000010.0,
+			},
+			time.Unix(1700000020, 0),
+			telegraf.Counter,
+		),
+		metric.New(
+			"prometheus",
+			map[string]string{
+				"host": "a",
+				"unit": "bytes",
+			},
+			map[string]interface{}{
+				"memory_used_bytes": 1024.0,
+			},
+			time.Unix(1700000020, 0),
+			telegraf.Gauge,
+		),
+		metric.New(
+			"prometheus",
+			map[string]string{"unit": "seconds"},
+			map[string]interface{}{
+				"latency_seconds_sum":     2.5,
+				"latency_seconds_count":   3.0,
+				"latency_seconds_created": 1700000000.0,
+			},
+			time.Unix(1700000020, 0),
+			telegraf.Histogram,
+		),
+		metric.New(
+			"prometheus",
+			map[string]string{
+				"le":                "0.5",
+				"unit":              "seconds",
+				"exemplar_trace_id": "xyz",
+			},
+			map[string]interface{}{
+				"latency_seconds_bucket":          1.0,
+				"latency_seconds_bucket_exemplar": 0.25,
+			},
+			time.Unix(1700000020, 0),
+			telegraf.Histogram,
+		),
+		metric.New(
+			"prometheus",
+			map[string]string{
+				"le":   "+Inf",
+				"unit": "seconds",
+			},
+			map[string]interface{}{
+				"latency_seconds_bucket": 3.0,
+			},
+			time.Unix(1700000020, 0),
+			telegraf.Histogram,
+		),
+		metric.New(
+			"prometheus",
+			map[string]string{},
+			map[string]interface{}{
+				"rpc_duration_sum":     17.5,
+				"rpc_duration_count":   7.0,
+				"rpc_duration_created": 1700000001.5,
+			},
+			time.Unix(1700000020, 0),
+			telegraf.Summary,
+		),
+		metric.New(
+			"prometheus",
+			map[string]string{"quantile": "0.5"},
+			map[string]interface{}{
+				"rpc_duration": 2.0,
+			},
+			time.Unix(1700000020, 0),
+			telegraf.Summary,
+		),
+	}
+
+	s := &Serializer{
+		FormatConfig{
+			SortMetrics:     true,
+			ExportTimestamp: true,
+			OpenMetrics:     true,
+		},
+	}
+	require.NoError(t, s.Init())
+	buf, err := s.SerializeBatch(expected)
+	require.NoError(t, err)
+
+	parser := &openmetrics.Parser{IncludeExemplars: true}
+	require.NoError(t, parser.Init())
+	actual, err := parser.Parse(buf)
+	require.NoError(t, err)
+
+	// The parser always names the metrics "openmetric" and relies on the
+	// input plugin to set the name.
+	for _, m := range actual {
+		m.SetName("prometheus")
+	}
+
+	testutil.RequireMetricsEqual(t, expected, actual, testutil.SortMetrics())
+}
@@ -1,7 +1,6 @@
 package prometheusremotewrite
 
 import (
-	"bytes"
 	"fmt"
 	"hash/fnv"
 	"sort"
@@ -10,10 +9,10 @@ import (
 	"time"
 
 	"github.com/golang/snappy"
-	"github.com/prometheus/prometheus/model/histogram"
 	"github.com/prometheus/prometheus/prompb"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/common/nativehistogram"
 	"github.com/influxdata/telegraf/plugins/serializers"
 	"github.com/influxdata/telegraf/plugins/serializers/prometheus"
 )
@@ -21,16 +20,61 @@ import (
 type Serializer struct {
 	SortMetrics   bool            `toml:"prometheus_sort_metrics"`
 	StringAsLabel bool            `toml:"prometheus_string_as_label"`
+	Version       int             `toml:"prometheus_remote_write_version"`
+	UnitTag       string          `toml:"prometheus_unit_tag"`
 	Log           telegraf.Logger `toml:"-"`
+
+	starts *startTracker
 }
 
 type metricKey uint64
 
+// series is a converted time-series along with the type of the originating
+// metric, required for the metadata of Remote-Write 2.0
+type series struct {
+	prompb.TimeSeries
+	valueType telegraf.ValueType
+	unit      string
+}
+
+func (s *Serializer) Init() error {
+	switch s.Version {
+	case 0:
+		s.Version = 1
+	case 1, 2:
+	default:
+		return fmt.Errorf("invalid prometheus_remote_write_version %d", s.Version)
+	}
+	s.starts = newStartTracker()
+
+	return nil
+}
+
 func (s *Serializer) Serialize(metric telegraf.Metric) ([]byte, error) {
 	return s.SerializeBatch([]telegraf.Metric{metric})
 }
 
 func (s *Serializer) SerializeBatch(metrics []telegraf.Metric) ([]byte, error) {
+	entries, err := s.convert(metrics)
+	if err != nil {
+		return nil, err
+	}
+
+	var data []byte
+	if s.Version == 2 {
+		data, err = s.marshalV2(entries)
+	} else {
+		data, err = marshalV1(entries)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to marshal protobuf: %w", err)
+	}
+	return snappy.Encode(nil, data), nil
+}
+
+// convert transforms the metrics into Prometheus time-series optionally
+// sorted by their labels
+func (s *Serializer) convert(metrics []telegraf.Metric) ([]series, error) {
 	var lastErr error
 	// traceAndKeepErr logs on Trace level every passed error.
 	// with each call it updates lastErr, so it can be logged later with higher level.
@@ -39,11 +83,15 @@ func (s *Serializer) SerializeBatch(metrics []telegraf.Metric) ([]byte, error) {
 		s.Log.Trace(lastErr)
 	}
 
-	var buf bytes.Buffer
-	var entries = make(map[metricKey]prompb.TimeSeries)
+	var entries = make(map[metricKey]series)
+	var classics = make(map[metricKey]*classicHistogram)
 	var labels = make([]prompb.Label, 0)
 	for _, metric := range metrics {
 		labels = s.appendCommonLabels(labels[:0], metric)
+		var unit string
+		if s.Version == 2 && s.UnitTag != "" {
+			unit, _ = metric.GetTag(s.UnitTag)
+		}
 		var metrickey metricKey
 		var promts prompb.TimeSeries
 
@@ -60,11 +108,21 @@ func (s *Serializer) SerializeBatch(metrics []telegraf.Metric) ([]byte, error) {
 						continue
 					}
 				}
-				entries[metrickey] = *data
+				entries[metrickey] = series{TimeSeries: *data, valueType: metric.Type(), unit: unit}
 				continue
 			}
 		}
 
+		// Remote-Write 2.0 supports histograms with custom buckets, so collect
+		// the buckets, sum and count of classic histograms into a single
+		// histogram sample instead of separate series.
+		if s.Version == 2 && metric.Type() == telegraf.Histogram {
+			if err := addClassicHistogram(classics, metric, labels, unit); err != nil {
+				traceAndKeepErr("%w", err)
+			}
+			continue
+		}
+
 		// If it's not a native histogram, we parse field by field as per normal.
 		for _, field := range metric.FieldList() {
 			rawName := prometheus.MetricName(metric.Name(), field.Key, metric.Type())
@@ -92,11 +150,11 @@ func (s *Serializer) SerializeBatch(metrics []telegraf.Metric) ([]byte, error) {
 					// if bucket only, init sum, count, inf
 					metrickeysum, promtssum := getPromTS(metricName+"_sum", labels, float64(0), metric.Time())
 					if _, ok = entries[metrickeysum]; !ok {
-						entries[metrickeysum] = promtssum
+						entries[metrickeysum] = series{TimeSeries: promtssum, valueType: metric.Type(), unit: unit}
 					}
 					metrickeycount, promtscount := getPromTS(metricName+"_count", labels, float64(0), metric.Time())
 					if _, ok = entries[metrickeycount]; !ok {
-						entries[metrickeycount] = promtscount
+						entries[metrickeycount] = series{TimeSeries: promtscount, valueType: metric.Type(), unit: unit}
 					}
 					extraLabel := prompb.Label{
 						Name:  "le",
@@ -104,7 +162,7 @@ func (s *Serializer) SerializeBatch(metrics []telegraf.Metric) ([]byte, error) {
 					}
 					metrickeyinf, promtsinf := getPromTS(metricName+"_bucket", labels, float64(0), metric.Time(), extraLabel)
 					if _, ok = entries[metrickeyinf]; !ok {
-						entries[metrickeyinf] = promtsinf
+						entries[metrickeyinf] = series{TimeSeries: promtsinf, valueType: metric.Type(), unit: unit}
 					}
 
 					le, ok := metric.GetTag("le")
@@ -150,7 +208,7 @@ func (s *Serializer) SerializeBatch(metrics []telegraf.Metric) ([]byte, error) {
 					}
 					metrickeyinf, promtsinf := getPromTS(metricName+"_bucket", labels, float64(count), metric.Time(), extraLabel)
 					if minf, ok := entries[metrickeyinf]; !ok || minf.Samples[0].Value == 0 {
-						entries[metrickeyinf] = promtsinf
+						entries[metrickeyinf] = series{TimeSeries: promtsinf, valueType: metric.Type(), unit: unit}
 					}
 
 					metrickey, promts = getPromTS(metricName+"_count", labels, float64(count), metric.Time())
@@ -212,21 +270,28 @@ func (s *Serializer) SerializeBatch(metrics []telegraf.Metric) ([]byte, error) {
 					continue
 				}
 			}
-			entries[metrickey] = promts
+			entries[metrickey] = series{TimeSeries: promts, valueType: metric.Type(), unit: unit}
 		}
 	}
 
+	for key, h := range classics {
+		data, err := h.convert()
+		if err != nil {
+			traceAndKeepErr("%w", err)
+			continue
+		}
+		entries[key] = series{TimeSeries: *data, valueType: telegraf.Histogram, unit: h.unit}
+	}
+
 	if lastErr != nil {
 		// log only the last recorded error in the batch, as it could have many errors and logging each one
 		// could be too verbose. The following log line still provides enough info for user to act on.
 		s.Log.Warnf("some series were dropped, %d series left to send; last recorded error: %v", len(entries), lastErr)
 	}
 
-	var promTS = make([]prompb.TimeSeries, len(entries))
-	var i int
+	var promTS = make([]series, 0, len(entries))
 	for _, promts := range entries {
-		promTS[i] = promts
-		i++
+		promTS = append(promTS, promts)
 	}
 
 	if s.SortMetrics {
@@ -253,14 +318,16 @@ func (s *Serializer) SerializeBatch(metrics []telegraf.Metric) ([]byte, error) {
 			return false
 		})
 	}
-	pb := &prompb.WriteRequest{Timeseries: promTS}
-	data, err := pb.Marshal()
-	if err != nil {
-		return nil, fmt.Errorf("unable to marshal protobuf: %w", err)
+	return promTS, nil
+}
+
+func marshalV1(entries []series) ([]byte, error) {
+	promTS := make([]prompb.TimeSeries, 0, len(entries))
+	for _, entry := range entries {
+		promTS = append(promTS, entry.TimeSeries)
 	}
-	encoded := snappy.Encode(nil, data)
-	buf.Write(encoded)
-	return buf.Bytes(), nil
+	pb := &prompb.WriteRequest{Timeseries: promTS}
+	return pb.Marshal()
 }
 
 func hasLabel(name string, labels []prompb.Label) bool {
@@ -286,6 +353,11 @@ func (s *Serializer) appendCommonLabels(labels []prompb.Label, metric telegraf.M
 			}
 		}
 
+		// The unit is part of the metadata for Remote-Write 2.0
+		if s.Version == 2 && s.UnitTag != "" && tag.Key == s.UnitTag {
+			continue
+		}
+
 		name, ok := prometheus.SanitizeLabelName(tag.Key)
 		if !ok {
 			continue
@@ -359,156 +431,10 @@ func getPromTS(name string, labels []prompb.Label, value float64, ts time.Time,
 }
 
 func tryConvertToNativeHistogram(metric telegraf.Metric, labels []prompb.Label) (metricKey, *prompb.TimeSeries) {
-	fields := metric.Fields()
-
-	// Native histograms have count, sum, schema, counter_reset_hint, zero_threshold, zero_count
-	// If any of these are missing, we can't convert to a native histogram and short-circuit.
-	count, found := fields["count"]
-	if !found {
-		return 0, nil
-	}
-	countFloat, ok := count.(float64)
-	if !ok {
-		return 0, nil
-	}
-	sum, found := fields["sum"]
-	if !found {
-		return 0, nil
-	}
-	sumFloat, ok := sum.(float64)
-	if !ok {
-		return 0, nil
-	}
-	schema, found := fields["schema"]
-	if !found {
-		return 0, nil
-	}
-	schemaInt, ok := schema.(int64)
-	if !ok {
-		return 0, nil
-	}
-	counterResetHint, found := fields["counter_reset_hint"]
-	if !found {
-		return 0, nil
-	}
-	counterResetHintInt, ok := counterResetHint.(uint64)
-	if !ok {
-		return 0, nil
-	}
-	zeroThreshold, found := fields["zero_threshold"]
-	if !found {
-		return 0, nil
-	}
-	zeroThresholdFloat, ok := zeroThreshold.(float64)
+	floatHistogram, ok := nativehistogram.FromFields(metric.Fields())
 	if !ok {
 		return 0, nil
 	}
-	zeroCount, found := fields["zero_count"]
-	if !found {
-		return 0, nil
-	}
-	zeroCountFloat, ok := zeroCount.(float64)
-	if !ok {
-		return 0, nil
-	}
-
-	floatHistogram := &histogram.FloatHistogram{
-		Count:            countFloat,
-		Sum:              sumFloat,
-		Schema:           int32(schemaInt),
-		CounterResetHint: histogram.CounterResetHint(counterResetHintInt),
-		ZeroThreshold:    zeroThresholdFloat,
-		ZeroCount:        zeroCountFloat,
-		PositiveSpans:    make([]histogram.Span, 0),
-		NegativeSpans:    make([]histogram.Span, 0),
-		PositiveBuckets:  make([]float64, 0),
-		NegativeBuckets:  make([]float64, 0),
-	}
-
-	// Span (offset, length pair) define bucket boundaries.
-	// A native histogram can have 0 or multiple positive spans.
-	// We do not know how many spans there are, so iterate from 0 until we break.
-	i := 0
-	for {
-		offset, offsetFound := fields[fmt.Sprintf("positive_span_%d_offset", i)]
-		length, lengthFound := fields[fmt.Sprintf("positive_span_%d_length", i)]
-		if !offsetFound || !lengthFound {
-			break
-		}
-		offsetInt, offsetOk := offset.(int64)
-		lengthInt, lengthOk := length.(uint64)
-		if !offsetOk || !lengthOk {
-			break
-		}
-		floatHistogram.PositiveSpans = append(floatHistogram.PositiveSpans,
-			histogram.Span{
-				Offset: int32(offsetInt),
-				Length: uint32(lengthInt),
-			},
-		)
-		i++
-	}
-
-	// Do the same for negative spans
-	i = 0
-	for {
-		offset, offsetFound := fields[fmt.Sprintf("negative_span_%d_offset", i)]
-		length, lengthFound := fields[fmt.Sprintf("negative_span_%d_length", i)]
-		if !offsetFound || !lengthFound {
-			break
-		}
-		offsetInt, offsetOk := offset.(int64)
-		lengthInt, lengthOk := length.(uint64)
-		if !offsetOk || !lengthOk {
-			break
-		}
-		floatHistogram.NegativeSpans = append(floatHistogram.NegativeSpans,
-			histogram.Span{
-				Offset: int32(offsetInt),
-				Length: uint32(lengthInt),
-			},
-		)
-		i++
-	}
-
-	// Bucket defines count in each bucket.
-	// Similarly, there can be 0 or multiple positive bucket fields.
-	// Similarly, we do not know how many bucket fields there are, so iterate from 0 until we break.
-	// Note that length of bucket array can be more than the length of spans due to delta encoding of bucket boundaries.
-	i = 0
-	for {
-		bucket, found := fields[fmt.Sprintf("positive_bucket_%d", i)]
-		if !found {
-			break
-		}
-		bucketFloat, ok := bucket.(float64)
-		if !ok {
-			break
-		}
-		floatHistogram.PositiveBuckets = append(floatHistogram.PositiveBuckets, bucketFloat)
-		i++
-	}
-
-	// Do the same for negative buckets
-	i = 0
-	for {
-		bucket, found := fields[fmt.Sprintf("negative_bucket_%d", i)]
-		if !found {
-			break
-		}
-		bucketFloat, ok := bucket.(float64)
-		if !ok {
-			break
-		}
-		floatHistogram.NegativeBuckets = append(floatHistogram.NegativeBuckets, bucketFloat)
-		i++
-	}
-
-	// Validate the floatHistogram
-	err := floatHistogram.Validate()
-	if err != nil {
-		return 0, nil
-	}
 
 	// Now we have a valid floatHistogram, we convert it to a prompb.TimeSeries
 	labelscopy := make([]prompb.Label, len(labels), len(labels)+1)
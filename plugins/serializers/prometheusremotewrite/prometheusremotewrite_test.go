@@ -9,7 +9,9 @@ import (
 
 	"github.com/golang/snappy"
 	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/histogram"
 	"github.com/prometheus/prometheus/prompb"
+	writev2 "github.com/prometheus/prometheus/prompb/io/prometheus/write/v2"
 	"github.com/stretchr/testify/require"
 
 	"github.com/influxdata/telegraf"
@@ -926,3 +928,209 @@ func BenchmarkSerializeBatch(b *testing.B) {
 		require.NoError(b, err)
 	}
 }
+
+func TestRemoteWriteSerializeV2(t *testing.T) {
+	s := &Serializer{
+		Version:     2,
+		UnitTag:     "unit",
+		SortMetrics: true,
+		Log:         &testutil.CaptureLogger{},
+	}
+	require.NoError(t, s.Init())
+
+	metrics := []telegraf.Metric{
+		metric.New(
+			"http:requests",
+			map[string]string{"code": "200"},
+			map[string]interface{}{"total": 10.0},
+			time.Unix(10, 0),
+			telegraf.Counter,
+		),
+		metric.New(
+			"cpu",
+			map[string]string{"host": "example.org", "unit": "ratio"},
+			map[string]interface{}{"usage": 0.25},
+			time.Unix(10, 0),
+			telegraf.Gauge,
+		),
+		metric.New(
+			"rpc_duration_seconds",
+			map[string]string{"host": "example.org"},
+			map[string]interface{}{
+				"count":                  float64(8),
+				"sum":                    float64(10),
+				"schema":                 int64(0),
+				"counter_reset_hint":     uint64(0),
+				"zero_threshold":         float64(0.001),
+				"zero_count":             float64(0),
+				"positive_span_0_offset": int64(0),
+				"positive_span_0_length": uint64(2),
+				"positive_bucket_0":      float64(3),
+				"positive_bucket_1":      float64(5),
+			},
+			time.Unix(10, 0),
+			telegraf.Histogram,
+		),
+	}
+
+	data, err := s.SerializeBatch(metrics)
+	require.NoError(t, err)
+	req := decodeV2(t, data)
+	require.Equal(t, "", req.Symbols[0])
+
+	expected := map[string]struct {
+		mtype writev2.Metadata_MetricType
+		unit  string
+		start int64
+	}{
+		`cpu_usage{host="example.org"}`:            {mtype: writev2.Metadata_METRIC_TYPE_GAUGE, unit: "ratio"},
+		`http_requests_total{code="200"}`:          {mtype: writev2.Metadata_METRIC_TYPE_COUNTER, start: 10000},
+		`rpc_duration_seconds{host="example.org"}`: {mtype: writev2.Metadata_METRIC_TYPE_HISTOGRAM, start: 10000},
+	}
+	require.Len(t, req.Timeseries, len(expected))
+	for _, ts := range req.Timeseries {
+		name := seriesNameV2(t, req.Symbols, ts)
+		exp, found := expected[name]
+		require.Truef(t, found, "unexpected series %q", name)
+
+		require.Equal(t, exp.mtype, ts.Metadata.Type, name)
+		require.Equal(t, "Telegraf collected metric", req.Symbols[ts.Metadata.HelpRef], name)
+		require.Equal(t, exp.unit, req.Symbols[ts.Metadata.UnitRef], name)
+		if len(ts.Histograms) > 0 {
+			require.Equal(t, int64(10000), ts.Histograms[0].Timestamp, name)
+			require.Equal(t, exp.start, ts.Histograms[0].StartTimestamp, name)
+			require.InDelta(t, 8.0, ts.Histograms[0].GetCountFloat(), 1e-9, name)
+			continue
+		}
+		require.Len(t, ts.Samples, 1, name)
+		require.Equal(t, int64(10000), ts.Samples[0].Timestamp, name)
+		require.Equal(t, exp.start, ts.Samples[0].StartTimestamp, name)
+	}
+}
+
+func TestRemoteWriteSerializeV2StartTimestamp(t *testing.T) {
+	s := &Serializer{Version: 2, Log: &testutil.CaptureLogger{}}
+	require.NoError(t, s.Init())
+
+	// The start is kept across batches until the counter resets
+	expected := []struct {
+		timestamp int64
+		value     float64
+		start     int64
+	}{
+		{timestamp: 10, value: 5, start: 10000},
+		{timestamp: 20, value: 8, start: 10000},
+		{timestamp: 30, value: 2, start: 20001},
+		{timestamp: 40, value: 4, start: 20001},
+	}
+	for _, tt := range expected {
+		m := metric.New(
+			"requests",
+			map[string]string{},
+			map[string]interface{}{"total": tt.value},
+			time.Unix(tt.timestamp, 0),
+			telegraf.Counter,
+		)
+		data, err := s.Serialize(m)
+		require.NoError(t, err)
+		req := decodeV2(t, data)
+		require.Len(t, req.Timeseries, 1)
+		require.Len(t, req.Timeseries[0].Samples, 1)
+		require.Equal(t, tt.start, req.Timeseries[0].Samples[0].StartTimestamp)
+	}
+}
+
+func TestRemoteWriteSerializeV2ClassicHistogram(t *testing.T) {
+	s := &Serializer{Version: 2, Log: &testutil.CaptureLogger{}}
+	require.NoError(t, s.Init())
+
+	metrics := []telegraf.Metric{
+		metric.New(
+			"prometheus",
+			map[string]string{"host": "example.org"},
+			map[string]interface{}{
+				"http_request_duration_seconds_sum":   53423,
+				"http_request_duration_seconds_count": 144320,
+			},
+			time.Unix(10, 0),
+			telegraf.Histogram,
+		),
+	}
+	buckets := []struct {
+		le    string
+		count float64
+	}{
+		{le: "0.05", count: 24054},
+		{le: "0.1", count: 33444},
+		{le: "0.5", count: 129389},
+		{le: "1.0", count: 133988},
+		{le: "+Inf", count: 144320},
+	}
+	for _, b := range buckets {
+		metrics = append(metrics, metric.New(
+			"prometheus",
+			map[string]string{"host": "example.org", "le": b.le},
+			map[string]interface{}{"http_request_duration_seconds_bucket": b.count},
+			time.Unix(10, 0),
+			telegraf.Histogram,
+		))
+	}
+
+	data, err := s.SerializeBatch(metrics)
+	require.NoError(t, err)
+	req := decodeV2(t, data)
+
+	// The buckets, sum and count must result in a single histogram sample
+	// without any separate bucket, sum or count series
+	require.Len(t, req.Timeseries, 1)
+	ts := req.Timeseries[0]
+	require.Equal(t, `http_request_duration_seconds{host="example.org"}`, seriesNameV2(t, req.Symbols, ts))
+	require.Equal(t, writev2.Metadata_METRIC_TYPE_HISTOGRAM, ts.Metadata.Type)
+	require.Empty(t, ts.Samples)
+	require.Len(t, ts.Histograms, 1)
+
+	h := ts.Histograms[0]
+	require.Equal(t, int64(10000), h.Timestamp)
+	require.Equal(t, int64(10000), h.StartTimestamp)
+	require.Equal(t, histogram.CustomBucketsSchema, h.Schema)
+	require.Equal(t, []float64{0.05, 0.1, 0.5, 1.0}, h.CustomValues)
+	require.InDelta(t, 144320.0, h.GetCountFloat(), 1e-9)
+	require.InDelta(t, 53423.0, h.Sum, 1e-9)
+
+	fh := h.ToFloatHistogram()
+	require.NoError(t, fh.Validate())
+	require.Equal(t, []float64{24054, 9390, 95945, 4599, 10332}, fh.PositiveBuckets)
+}
+
+func TestRemoteWriteInvalidVersion(t *testing.T) {
+	s := &Serializer{Version: 3}
+	require.ErrorContains(t, s.Init(), "invalid prometheus_remote_write_version 3")
+}
+
+func decodeV2(t *testing.T, data []byte) *writev2.Request {
+	t.Helper()
+
+	decoded, err := snappy.Decode(nil, data)
+	require.NoError(t, err)
+
+	var req writev2.Request
+	require.NoError(t, req.Unmarshal(decoded))
+	return &req
+}
+
+func seriesNameV2(t *testing.T, symbols []string, ts writev2.TimeSeries) string {
+	t.Helper()
+
+	require.Zero(t, len(ts.LabelsRefs)%2)
+	var name string
+	labels := make([]string, 0, len(ts.LabelsRefs)/2)
+	for i := 0; i < len(ts.LabelsRefs); i += 2 {
+		key, value := symbols[ts.LabelsRefs[i]], symbols[ts.LabelsRefs[i+1]]
+		if key == "__name__" {
+			name = value
+			continue
+		}
+		labels = append(labels, fmt.Sprintf("%s=%q", key, value))
+	}
+	return name + "{" + strings.Join(labels, ", ") + "}"
+}
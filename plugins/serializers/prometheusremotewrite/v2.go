@@ -0,0 +1,272 @@
+package prometheusremotewrite
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/prometheus/model/histogram"
+	"github.com/prometheus/prometheus/prompb"
+	writev2 "github.com/prometheus/prometheus/prompb/io/prometheus/write/v2"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/serializers/prometheus"
+)
+
+const helpString = "Telegraf collected metric"
+
+// startExpiry is the duration after which the start timestamp of a series
+// not seen anymore is forgotten
+const startExpiry = time.Hour
+
+// marshalV2 encodes the series as an "io.prometheus.write.v2.Request" with
+// interned label strings, metadata and start timestamps
+func (s *Serializer) marshalV2(entries []series) ([]byte, error) {
+	s.starts.Lock()
+	defer s.starts.Unlock()
+
+	symbols := writev2.NewSymbolTable()
+	helpRef := symbols.Symbolize(helpString)
+
+	var newest int64
+	promTS := make([]writev2.TimeSeries, 0, len(entries))
+	for _, entry := range entries {
+		refs := make([]uint32, 0, 2*len(entry.Labels))
+		for _, label := range entry.Labels {
+			refs = append(refs, symbols.Symbolize(label.Name), symbols.Symbolize(label.Value))
+		}
+
+		ts := writev2.TimeSeries{
+			LabelsRefs: refs,
+			Metadata: writev2.Metadata{
+				Type:    metadataType(entry),
+				HelpRef: helpRef,
+				UnitRef: symbols.Symbolize(entry.unit),
+			},
+		}
+
+		key := makeMetricKey(entry.Labels)
+		for _, sample := range entry.Samples {
+			v2 := writev2.Sample{Value: sample.Value, Timestamp: sample.Timestamp}
+			if entry.valueType == telegraf.Counter {
+				v2.StartTimestamp = s.starts.get(key, sample.Timestamp, sample.Value)
+			}
+			ts.Samples = append(ts.Samples, v2)
+			newest = max(newest, sample.Timestamp)
+		}
+		for _, h := range entry.Histograms {
+			fh := h.ToFloatHistogram()
+			v2 := writev2.FromFloatHistogram(h.Timestamp, fh)
+			if fh.CounterResetHint != histogram.GaugeType {
+				v2.StartTimestamp = s.starts.get(key, h.Timestamp, fh.Count)
+			}
+			ts.Histograms = append(ts.Histograms, v2)
+			newest = max(newest, h.Timestamp)
+		}
+		promTS = append(promTS, ts)
+	}
+	s.starts.expire(newest)
+
+	req := &writev2.Request{Symbols: symbols.Symbols(), Timeseries: promTS}
+	return req.Marshal()
+}
+
+func metadataType(entry series) writev2.Metadata_MetricType {
+	switch entry.valueType {
+	case telegraf.Counter:
+		return writev2.Metadata_METRIC_TYPE_COUNTER
+	case telegraf.Gauge:
+		return writev2.Metadata_METRIC_TYPE_GAUGE
+	case telegraf.Histogram:
+		if len(entry.Histograms) > 0 && entry.Histograms[0].ResetHint == prompb.Histogram_GAUGE {
+			return writev2.Metadata_METRIC_TYPE_GAUGEHISTOGRAM
+		}
+		return writev2.Metadata_METRIC_TYPE_HISTOGRAM
+	case telegraf.Summary:
+		return writev2.Metadata_METRIC_TYPE_SUMMARY
+	default:
+		return writev2.Metadata_METRIC_TYPE_UNSPECIFIED
+	}
+}
+
+// startTracker keeps track of the start timestamps of cumulative series,
+// i.e. the time the series started counting, across batches. The first
+// sample of a series starts the series. A decreasing value marks a reset of
+// the series and restarts it right after the previous sample.
+type startTracker struct {
+	entries map[metricKey]*startEntry
+	sync.Mutex
+}
+
+type startEntry struct {
+	start int64
+	last  int64
+	value float64
+}
+
+func newStartTracker() *startTracker {
+	return &startTracker{entries: make(map[metricKey]*startEntry)}
+}
+
+// get returns the start timestamp in milliseconds for the series with the
+// given key and sample
+func (t *startTracker) get(key metricKey, timestamp int64, value float64) int64 {
+	e, found := t.entries[key]
+	if !found {
+		t.entries[key] = &startEntry{start: timestamp, last: timestamp, value: value}
+		return timestamp
+	}
+
+	// Ignore out-of-order samples for tracking resets
+	if timestamp < e.last {
+		return e.start
+	}
+	if value < e.value {
+		e.start = e.last + 1
+	}
+	e.last = timestamp
+	e.value = value
+
+	return e.start
+}
+
+// expire removes all series without samples within the expiry duration
+// before the given timestamp in milliseconds
+func (t *startTracker) expire(now int64) {
+	limit := now - startExpiry.Milliseconds()
+	for key, e := range t.entries {
+		if e.last < limit {
+			delete(t.entries, key)
+		}
+	}
+}
+
+// classicHistogram collects the cumulative bucket counts, the sum and the
+// count of a classic histogram spread across multiple metrics and fields
+type classicHistogram struct {
+	name      string
+	labels    []prompb.Label
+	unit      string
+	timestamp int64
+	buckets   map[float64]float64
+	sum       float64
+	count     float64
+	hasCount  bool
+}
+
+// addClassicHistogram adds the "_bucket", "_sum" and "_count" fields of the
+// metric to the histogram of the corresponding series. Only the newest
+// values are kept if the batch contains multiple values for a histogram.
+func addClassicHistogram(histograms map[metricKey]*classicHistogram, metric telegraf.Metric, labels []prompb.Label, unit string) error {
+	timestamp := metric.Time().UnixMilli()
+	for _, field := range metric.FieldList() {
+		rawName := prometheus.MetricName(metric.Name(), field.Key, metric.Type())
+		name, ok := prometheus.SanitizeMetricName(rawName)
+		if !ok {
+			return fmt.Errorf("failed to parse metric name %q", rawName)
+		}
+
+		key, promts := getPromTS(name, labels, 0, metric.Time())
+		h, found := histograms[key]
+		switch {
+		case !found || timestamp > h.timestamp:
+			h = &classicHistogram{
+				name:      name,
+				labels:    promts.Labels,
+				unit:      unit,
+				timestamp: timestamp,
+				buckets:   make(map[float64]float64),
+			}
+			histograms[key] = h
+		case timestamp < h.timestamp:
+			return fmt.Errorf("metric %q has histograms with timestamp %v older than already registered before", metric.Name(), metric.Time())
+		}
+
+		switch {
+		case strings.HasSuffix(field.Key, "_bucket"):
+			le, ok := metric.GetTag("le")
+			if !ok {
+				return fmt.Errorf("failed to parse %q: can't find `le` label", name)
+			}
+			bound, err := strconv.ParseFloat(le, 64)
+			if err != nil {
+				return fmt.Errorf("failed to parse %q: can't parse %q value: %w", name, le, err)
+			}
+			count, ok := prometheus.SampleCount(field.Value)
+			if !ok {
+				return fmt.Errorf("failed to parse %q: bad sample value %#v", name, field.Value)
+			}
+			h.buckets[bound] = float64(count)
+		case strings.HasSuffix(field.Key, "_sum"):
+			sum, ok := prometheus.SampleSum(field.Value)
+			if !ok {
+				return fmt.Errorf("failed to parse %q: bad sample value %#v", name, field.Value)
+			}
+			h.sum = sum
+		case strings.HasSuffix(field.Key, "_count"):
+			count, ok := prometheus.SampleCount(field.Value)
+			if !ok {
+				return fmt.Errorf("failed to parse %q: bad sample value %#v", name, field.Value)
+			}
+			h.count = float64(count)
+			h.hasCount = true
+		default:
+			return fmt.Errorf("failed to parse %q: series %q should have `_count`, `_sum` or `_bucket` suffix", name, field.Key)
+		}
+	}
+	return nil
+}
+
+// convert creates a time-series with a single histogram sample using custom
+// buckets with the finite upper bounds of the classic histogram
+func (h *classicHistogram) convert() (*prompb.TimeSeries, error) {
+	bounds := make([]float64, 0, len(h.buckets))
+	for bound := range h.buckets {
+		if !math.IsInf(bound, 1) {
+			bounds = append(bounds, bound)
+		}
+	}
+	slices.Sort(bounds)
+
+	// The total count is taken from the count or the +Inf bucket and falls
+	// back to the largest bucket
+	count := h.count
+	if !h.hasCount {
+		if inf, found := h.buckets[math.Inf(1)]; found {
+			count = inf
+		} else if len(bounds) > 0 {
+			count = h.buckets[bounds[len(bounds)-1]]
+		}
+	}
+
+	// Convert the cumulative counts to the counts per bucket with the last
+	// bucket reaching up to +Inf
+	counts := make([]float64, 0, len(bounds)+1)
+	var previous float64
+	for _, bound := range bounds {
+		counts = append(counts, h.buckets[bound]-previous)
+		previous = h.buckets[bound]
+	}
+	counts = append(counts, count-previous)
+
+	fh := &histogram.FloatHistogram{
+		Schema:          histogram.CustomBucketsSchema,
+		Count:           count,
+		Sum:             h.sum,
+		PositiveSpans:   []histogram.Span{{Offset: 0, Length: uint32(len(counts))}},
+		PositiveBuckets: counts,
+		CustomValues:    bounds,
+	}
+	if err := fh.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid histogram %q: %w", h.name, err)
+	}
+
+	return &prompb.TimeSeries{
+		Labels:     h.labels,
+		Histograms: []prompb.Histogram{prompb.FromFloatHistogram(h.timestamp, fh)},
+	}, nil
+}
@@ -0,0 +1,222 @@
+package protobuf
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	"github.com/influxdata/telegraf/internal"
+)
+
+const timestampMessage = "google.protobuf.Timestamp"
+
+// fieldPath is the chain of field descriptors leading from the root message
+// to the target field. All elements but the last are singular message fields.
+type fieldPath []protoreflect.FieldDescriptor
+
+// resolvePath resolves a dot-separated path of field names, e.g.
+// "source.host", relative to the given message descriptor.
+func resolvePath(desc protoreflect.MessageDescriptor, p string) (fieldPath, error) {
+	if p == "" {
+		return nil, errors.New("empty path")
+	}
+
+	parts := strings.Split(p, ".")
+	fp := make(fieldPath, 0, len(parts))
+	for i, part := range parts {
+		fd := desc.Fields().ByName(protoreflect.Name(part))
+		if fd == nil {
+			return nil, fmt.Errorf("message %q has no field %q", desc.FullName(), part)
+		}
+		fp = append(fp, fd)
+
+		if i == len(parts)-1 {
+			break
+		}
+		if fd.Kind() != protoreflect.MessageKind && fd.Kind() != protoreflect.GroupKind {
+			return nil, fmt.Errorf("field %q of %q is not a message", part, p)
+		}
+		if fd.Cardinality() == protoreflect.Repeated {
+			return nil, fmt.Errorf("field %q of %q is repeated", part, p)
+		}
+		desc = fd.Message()
+	}
+	return fp, nil
+}
+
+func (fp fieldPath) target() protoreflect.FieldDescriptor {
+	return fp[len(fp)-1]
+}
+
+// checkScalar ensures the path ends at a scalar or enum field, or at a list
+// of those.
+func (fp fieldPath) checkScalar() error {
+	fd := fp.target()
+	if fd.IsMap() {
+		return fmt.Errorf("field %q is a map", fd.FullName())
+	}
+	if fd.Kind() == protoreflect.MessageKind || fd.Kind() == protoreflect.GroupKind {
+		return fmt.Errorf("field %q is a message", fd.FullName())
+	}
+	return nil
+}
+
+// checkMap ensures the path ends at a map with string keys and scalar values.
+func (fp fieldPath) checkMap() error {
+	fd := fp.target()
+	if !fd.IsMap() {
+		return fmt.Errorf("field %q is not a map", fd.FullName())
+	}
+	if fd.MapKey().Kind() != protoreflect.StringKind {
+		return fmt.Errorf("key of map %q is not a string", fd.FullName())
+	}
+	if k := fd.MapValue().Kind(); k == protoreflect.MessageKind || k == protoreflect.GroupKind {
+		return fmt.Errorf("value of map %q is a message", fd.FullName())
+	}
+	return nil
+}
+
+// checkTime ensures the path ends at a field able to hold a timestamp.
+func (fp fieldPath) checkTime() error {
+	fd := fp.target()
+	if fd.Cardinality() == protoreflect.Repeated {
+		return fmt.Errorf("field %q is repeated", fd.FullName())
+	}
+	switch fd.Kind() {
+	case protoreflect.MessageKind:
+		if fd.Message().FullName() != timestampMessage {
+			return fmt.Errorf("field %q is not a %s", fd.FullName(), timestampMessage)
+		}
+	case protoreflect.EnumKind, protoreflect.BoolKind, protoreflect.GroupKind:
+		return fmt.Errorf("field %q cannot hold a timestamp", fd.FullName())
+	}
+	return nil
+}
+
+// parent returns the message holding the target field, creating all
+// intermediate messages on the way.
+func (fp fieldPath) parent(msg protoreflect.Message) protoreflect.Message {
+	for _, fd := range fp[:len(fp)-1] {
+		msg = msg.Mutable(fd).Message()
+	}
+	return msg
+}
+
+// set converts the value to the type of the target field and sets the field.
+// Values for repeated fields are appended.
+func (fp fieldPath) set(msg protoreflect.Message, value interface{}) error {
+	fd := fp.target()
+	v, err := convert(fd, value)
+	if err != nil {
+		return err
+	}
+
+	parent := fp.parent(msg)
+	if fd.Cardinality() == protoreflect.Repeated {
+		parent.Mutable(fd).List().Append(v)
+		return nil
+	}
+	parent.Set(fd, v)
+	return nil
+}
+
+// setMapEntry adds the key-value pair to the target map field.
+func (fp fieldPath) setMapEntry(msg protoreflect.Message, key string, value interface{}) error {
+	fd := fp.target()
+	v, err := convert(fd.MapValue(), value)
+	if err != nil {
+		return err
+	}
+	fp.parent(msg).Mutable(fd).Map().Set(protoreflect.ValueOfString(key).MapKey(), v)
+	return nil
+}
+
+// setTime sets the target field to the given time. Timestamp messages are
+// filled directly, numeric fields use the unix format and string fields use
+// either the unix format or the format as Go reference-time layout.
+func (fp fieldPath) setTime(msg protoreflect.Message, t time.Time, format string) error {
+	fd := fp.target()
+	parent := fp.parent(msg)
+
+	if fd.Kind() == protoreflect.MessageKind {
+		ts := parent.Mutable(fd).Message()
+		fields := ts.Descriptor().Fields()
+		ts.Set(fields.ByName("seconds"), protoreflect.ValueOfInt64(t.Unix()))
+		ts.Set(fields.ByName("nanos"), protoreflect.ValueOfInt32(int32(t.Nanosecond())))
+		return nil
+	}
+
+	var value interface{}
+	switch format {
+	case "unix":
+		value = t.Unix()
+	case "unix_ms":
+		value = t.UnixMilli()
+	case "unix_us":
+		value = t.UnixMicro()
+	case "unix_ns":
+		value = t.UnixNano()
+	default:
+		if fd.Kind() != protoreflect.StringKind {
+			return fmt.Errorf("format %q requires a string field", format)
+		}
+		value = t.Format(format)
+	}
+
+	v, err := convert(fd, value)
+	if err != nil {
+		return err
+	}
+	parent.Set(fd, v)
+	return nil
+}
+
+// convert converts the value to a protocol-buffer value of the field's kind.
+func convert(fd protoreflect.FieldDescriptor, value interface{}) (protoreflect.Value, error) {
+	switch fd.Kind() {
+	case protoreflect.BoolKind:
+		v, err := internal.ToBool(value)
+		return protoreflect.ValueOfBool(v), err
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		v, err := internal.ToInt32(value)
+		return protoreflect.ValueOfInt32(v), err
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		v, err := internal.ToInt64(value)
+		return protoreflect.ValueOfInt64(v), err
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		v, err := internal.ToUint32(value)
+		return protoreflect.ValueOfUint32(v), err
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		v, err := internal.ToUint64(value)
+		return protoreflect.ValueOfUint64(v), err
+	case protoreflect.FloatKind:
+		v, err := internal.ToFloat32(value)
+		return protoreflect.ValueOfFloat32(v), err
+	case protoreflect.DoubleKind:
+		v, err := internal.ToFloat64(value)
+		return protoreflect.ValueOfFloat64(v), err
+	case protoreflect.StringKind:
+		v, err := internal.ToString(value)
+		return protoreflect.ValueOfString(v), err
+	case protoreflect.BytesKind:
+		v, err := internal.ToString(value)
+		return protoreflect.ValueOfBytes([]byte(v)), err
+	case protoreflect.EnumKind:
+		// Enums can be given by name or number
+		if s, ok := value.(string); ok {
+			if ev := fd.Enum().Values().ByName(protoreflect.Name(s)); ev != nil {
+				return protoreflect.ValueOfEnum(ev.Number()), nil
+			}
+			if _, err := strconv.ParseInt(s, 10, 32); err != nil {
+				return protoreflect.Value{}, fmt.Errorf("%q is not a value of enum %q", s, fd.Enum().FullName())
+			}
+		}
+		v, err := internal.ToInt32(value)
+		return protoreflect.ValueOfEnum(protoreflect.EnumNumber(v)), err
+	}
+	return protoreflect.Value{}, fmt.Errorf("unsupported kind %q of field %q", fd.Kind(), fd.FullName())
+}
@@ -0,0 +1,266 @@
+package protobuf
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+
+	"github.com/bufbuild/protocompile"
+	"google.golang.org/protobuf/encoding/protodelim"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/dynamicpb"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/serializers"
+)
+
+type Serializer struct {
+	MessageFiles    []string          `toml:"protobuf_files"`
+	MessageType     string            `toml:"protobuf_type"`
+	ImportPaths     []string          `toml:"protobuf_import_paths"`
+	Name            string            `toml:"protobuf_name"`
+	Time            string            `toml:"protobuf_time"`
+	TimeFormat      string            `toml:"protobuf_time_format"`
+	Tags            map[string]string `toml:"protobuf_tags"`
+	TagsMap         string            `toml:"protobuf_tags_map"`
+	Fields          map[string]string `toml:"protobuf_fields"`
+	FieldsMap       string            `toml:"protobuf_fields_map"`
+	LengthDelimited bool              `toml:"protobuf_length_delimited"`
+	Log             telegraf.Logger   `toml:"-"`
+
+	msgType   protoreflect.MessageType
+	name      fieldPath
+	time      fieldPath
+	tags      map[string]fieldPath
+	tagsMap   fieldPath
+	fields    map[string]fieldPath
+	fieldsMap fieldPath
+}
+
+func (s *Serializer) Init() error {
+	// Check the message definition and type
+	if len(s.MessageFiles) == 0 {
+		return errors.New("'protobuf_files' not set")
+	}
+	if s.MessageType == "" {
+		return errors.New("'protobuf_type' not set")
+	}
+
+	if s.TimeFormat == "" {
+		s.TimeFormat = "unix_ns"
+	}
+
+	desc, err := s.loadDescriptor()
+	if err != nil {
+		return err
+	}
+	s.msgType = dynamicpb.NewMessageType(desc)
+
+	// Resolve the paths of the mapping
+	if s.Name != "" {
+		if s.name, err = resolvePath(desc, s.Name); err != nil {
+			return fmt.Errorf("resolving 'protobuf_name' failed: %w", err)
+		}
+		if err := s.name.checkScalar(); err != nil {
+			return fmt.Errorf("resolving 'protobuf_name' failed: %w", err)
+		}
+	}
+	if s.Time != "" {
+		if s.time, err = resolvePath(desc, s.Time); err != nil {
+			return fmt.Errorf("resolving 'protobuf_time' failed: %w", err)
+		}
+		if err := s.time.checkTime(); err != nil {
+			return fmt.Errorf("resolving 'protobuf_time' failed: %w", err)
+		}
+	}
+
+	s.tags = make(map[string]fieldPath, len(s.Tags))
+	for key, p := range s.Tags {
+		fp, err := resolvePath(desc, p)
+		if err == nil {
+			err = fp.checkScalar()
+		}
+		if err != nil {
+			return fmt.Errorf("resolving path of tag %q failed: %w", key, err)
+		}
+		s.tags[key] = fp
+	}
+	if s.TagsMap != "" {
+		if s.tagsMap, err = resolvePath(desc, s.TagsMap); err != nil {
+			return fmt.Errorf("resolving 'protobuf_tags_map' failed: %w", err)
+		}
+		if err := s.tagsMap.checkMap(); err != nil {
+			return fmt.Errorf("resolving 'protobuf_tags_map' failed: %w", err)
+		}
+	}
+
+	s.fields = make(map[string]fieldPath, len(s.Fields))
+	for key, p := range s.Fields {
+		fp, err := resolvePath(desc, p)
+		if err == nil {
+			err = fp.checkScalar()
+		}
+		if err != nil {
+			return fmt.Errorf("resolving path of field %q failed: %w", key, err)
+		}
+		s.fields[key] = fp
+	}
+	if s.FieldsMap != "" {
+		if s.fieldsMap, err = resolvePath(desc, s.FieldsMap); err != nil {
+			return fmt.Errorf("resolving 'protobuf_fields_map' failed: %w", err)
+		}
+		if err := s.fieldsMap.checkMap(); err != nil {
+			return fmt.Errorf("resolving 'protobuf_fields_map' failed: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (s *Serializer) Serialize(metric telegraf.Metric) ([]byte, error) {
+	msg, err := s.message(metric)
+	if err != nil {
+		return nil, err
+	}
+
+	if !s.LengthDelimited {
+		return proto.MarshalOptions{Deterministic: true}.Marshal(msg)
+	}
+
+	var buf bytes.Buffer
+	if _, err := (protodelim.MarshalOptions{MarshalOptions: proto.MarshalOptions{Deterministic: true}}).MarshalTo(&buf, msg); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// SerializeBatch writes the messages of all metrics prefixed with their
+// varint-encoded length, as protocol-buffer messages are not self-delimiting.
+func (s *Serializer) SerializeBatch(metrics []telegraf.Metric) ([]byte, error) {
+	opts := protodelim.MarshalOptions{MarshalOptions: proto.MarshalOptions{Deterministic: true}}
+
+	var buf bytes.Buffer
+	for _, m := range metrics {
+		msg, err := s.message(m)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := opts.MarshalTo(&buf, msg); err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// message fills a new message according to the configured mapping.
+func (s *Serializer) message(m telegraf.Metric) (proto.Message, error) {
+	msg := s.msgType.New()
+
+	if s.name != nil {
+		if err := s.name.set(msg, m.Name()); err != nil {
+			return nil, fmt.Errorf("setting name of %q failed: %w", m.Name(), err)
+		}
+	}
+	if s.time != nil {
+		if err := s.time.setTime(msg, m.Time(), s.TimeFormat); err != nil {
+			return nil, fmt.Errorf("setting time of %q failed: %w", m.Name(), err)
+		}
+	}
+
+	for _, tag := range m.TagList() {
+		if fp, found := s.tags[tag.Key]; found {
+			if err := fp.set(msg, tag.Value); err != nil {
+				return nil, fmt.Errorf("setting tag %q of %q failed: %w", tag.Key, m.Name(), err)
+			}
+			continue
+		}
+		if s.tagsMap != nil {
+			if err := s.tagsMap.setMapEntry(msg, tag.Key, tag.Value); err != nil {
+				return nil, fmt.Errorf("setting tag %q of %q failed: %w", tag.Key, m.Name(), err)
+			}
+		}
+	}
+
+	// Sort the fields by key as the order is undefined otherwise and
+	// determines the order of values in repeated fields
+	fields := slices.Clone(m.FieldList())
+	sort.Slice(fields, func(i, j int) bool { return fields[i].Key < fields[j].Key })
+	for _, field := range fields {
+		if fp, found := s.fields[field.Key]; found {
+			if err := fp.set(msg, field.Value); err != nil {
+				return nil, fmt.Errorf("setting field %q of %q failed: %w", field.Key, m.Name(), err)
+			}
+			continue
+		}
+		if s.fieldsMap != nil {
+			if err := s.fieldsMap.setMapEntry(msg, field.Key, field.Value); err != nil {
+				return nil, fmt.Errorf("setting field %q of %q failed: %w", field.Key, m.Name(), err)
+			}
+		}
+	}
+
+	return msg.Interface(), nil
+}
+
+func (s *Serializer) loadDescriptor() (protoreflect.MessageDescriptor, error) {
+	// Load the file descriptors from the given protocol-buffer definition
+	ctx := context.Background()
+	resolver := &protocompile.SourceResolver{ImportPaths: s.ImportPaths}
+	compiler := &protocompile.Compiler{
+		Resolver: protocompile.WithStandardImports(resolver),
+	}
+	files, err := compiler.Compile(ctx, s.MessageFiles...)
+	if err != nil {
+		return nil, fmt.Errorf("parsing protocol-buffer definition failed: %w", err)
+	}
+	if len(files) < 1 {
+		return nil, errors.New("files do not contain a file descriptor")
+	}
+
+	var registry protoregistry.Files
+	for _, f := range files {
+		if err := registry.RegisterFile(f); err != nil {
+			return nil, fmt.Errorf("adding file %q to registry failed: %w", f.Path(), err)
+		}
+	}
+
+	// Lookup given type in the loaded file descriptors
+	msgFullName := protoreflect.FullName(s.MessageType)
+	descriptor, err := registry.FindDescriptorByName(msgFullName)
+	if err != nil {
+		s.Log.Infof("Could not find %q... Known messages:", msgFullName)
+
+		var known []string
+		registry.RangeFiles(func(fd protoreflect.FileDescriptor) bool {
+			msgs := fd.Messages()
+			for i := 0; i < msgs.Len(); i++ {
+				known = append(known, string(msgs.Get(i).FullName()))
+			}
+			return true
+		})
+		sort.Strings(known)
+		for _, name := range known {
+			s.Log.Infof("  %s", name)
+		}
+		return nil, err
+	}
+
+	desc, ok := descriptor.(protoreflect.MessageDescriptor)
+	if !ok {
+		return nil, fmt.Errorf("%q is not a message descriptor (%T)", msgFullName, descriptor)
+	}
+	return desc, nil
+}
+
+func init() {
+	serializers.Add("protobuf",
+		func() telegraf.Serializer {
+			return &Serializer{}
+		},
+	)
+}
@@ -0,0 +1,315 @@
+package protobuf
+
+import (
+	"bufio"
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protodelim"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/plugins/parsers/xpath"
+	"github.com/influxdata/telegraf/plugins/serializers"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func newSerializer() *Serializer {
+	return &Serializer{
+		MessageFiles: []string{"metric.proto"},
+		MessageType:  "telegraf.test.Metric",
+		ImportPaths:  []string{"testcases"},
+		Name:         "name",
+		Time:         "time",
+		Tags:         map[string]string{"host": "source.host", "region": "source.region"},
+		TagsMap:      "labels",
+		Fields:       map[string]string{"value": "value", "count": "count", "severity": "severity"},
+		FieldsMap:    "values",
+		Log:          testutil.Logger{},
+	}
+}
+
+func (s *Serializer) expected(t *testing.T, js string) proto.Message {
+	msg := s.msgType.New().Interface()
+	require.NoError(t, protojson.Unmarshal([]byte(js), msg))
+	return msg
+}
+
+func (s *Serializer) decode(t *testing.T, buf []byte) proto.Message {
+	msg := s.msgType.New().Interface()
+	require.NoError(t, proto.Unmarshal(buf, msg))
+	return msg
+}
+
+func TestSerialize(t *testing.T) {
+	s := newSerializer()
+	require.NoError(t, s.Init())
+
+	m := metric.New(
+		"cpu",
+		map[string]string{"host": "a", "region": "eu", "cpu": "cpu0"},
+		map[string]interface{}{
+			"value":    42.5,
+			"count":    uint64(3),
+			"severity": "WARNING",
+			"idle":     int64(17),
+		},
+		time.Unix(1700000000, 123456789),
+	)
+
+	buf, err := s.Serialize(m)
+	require.NoError(t, err)
+
+	expected := s.expected(t, `{
+		"name": "cpu",
+		"source": {"host": "a", "region": "eu"},
+		"time": "2023-11-14T22:13:20.123456789Z",
+		"value": 42.5,
+		"count": "3",
+		"severity": "WARNING",
+		"labels": {"cpu": "cpu0"},
+		"values": {"idle": 17}
+	}`)
+	actual := s.decode(t, buf)
+	require.Truef(t, proto.Equal(expected, actual), "expected %v but got %v", expected, actual)
+}
+
+func TestSerializeTimeFormats(t *testing.T) {
+	m := metric.New("cpu", map[string]string{}, map[string]interface{}{"value": 1.0}, time.Unix(1700000000, 123456789))
+
+	tests := []struct {
+		name     string
+		path     string
+		format   string
+		expected string
+	}{
+		{
+			name:     "unix_ms",
+			path:     "timestamp_ms",
+			format:   "unix_ms",
+			expected: `{"timestamp_ms": "1700000000123", "value": 1}`,
+		},
+		{
+			name:     "layout",
+			path:     "time_text",
+			format:   time.RFC3339,
+			expected: `{"time_text": "2023-11-14T22:13:20Z", "value": 1}`,
+		},
+		{
+			name:     "unix as string",
+			path:     "time_text",
+			format:   "unix",
+			expected: `{"time_text": "1700000000", "value": 1}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &Serializer{
+				MessageFiles: []string{"metric.proto"},
+				MessageType:  "telegraf.test.Metric",
+				ImportPaths:  []string{"testcases"},
+				Time:         tt.path,
+				TimeFormat:   tt.format,
+				Fields:       map[string]string{"value": "value"},
+				Log:          testutil.Logger{},
+			}
+			require.NoError(t, s.Init())
+
+			buf, err := s.Serialize(m)
+			require.NoError(t, err)
+			expected := s.expected(t, tt.expected)
+			actual := s.decode(t, buf)
+			require.Truef(t, proto.Equal(expected, actual), "expected %v but got %v", expected, actual)
+		})
+	}
+}
+
+func TestSerializeRepeated(t *testing.T) {
+	s := &Serializer{
+		MessageFiles: []string{"metric.proto"},
+		MessageType:  "telegraf.test.Metric",
+		ImportPaths:  []string{"testcases"},
+		Fields:       map[string]string{"first": "notes", "second": "notes"},
+		Log:          testutil.Logger{},
+	}
+	require.NoError(t, s.Init())
+
+	m := metric.New("log", map[string]string{}, map[string]interface{}{"first": "a", "second": "b"}, time.Unix(0, 0))
+	buf, err := s.Serialize(m)
+	require.NoError(t, err)
+
+	expected := s.expected(t, `{"notes": ["a", "b"]}`)
+	actual := s.decode(t, buf)
+	require.Truef(t, proto.Equal(expected, actual), "expected %v but got %v", expected, actual)
+}
+
+func TestSerializeBatch(t *testing.T) {
+	s := newSerializer()
+	require.NoError(t, s.Init())
+
+	metrics := []telegraf.Metric{
+		metric.New("cpu", map[string]string{"host": "a"}, map[string]interface{}{"value": 1.0}, time.Unix(1, 0)),
+		metric.New("mem", map[string]string{"host": "b"}, map[string]interface{}{"value": 2.0}, time.Unix(2, 0)),
+		metric.New("disk", map[string]string{"host": "c"}, map[string]interface{}{"value": 3.0}, time.Unix(3, 0)),
+	}
+	buf, err := s.SerializeBatch(metrics)
+	require.NoError(t, err)
+
+	expected := []proto.Message{
+		s.expected(t, `{"name": "cpu", "source": {"host": "a"}, "time": "1970-01-01T00:00:01Z", "value": 1}`),
+		s.expected(t, `{"name": "mem", "source": {"host": "b"}, "time": "1970-01-01T00:00:02Z", "value": 2}`),
+		s.expected(t, `{"name": "disk", "source": {"host": "c"}, "time": "1970-01-01T00:00:03Z", "value": 3}`),
+	}
+
+	reader := bufio.NewReader(bytes.NewReader(buf))
+	for _, e := range expected {
+		actual := s.msgType.New().Interface()
+		require.NoError(t, protodelim.UnmarshalFrom(reader, actual))
+		require.Truef(t, proto.Equal(e, actual), "expected %v but got %v", e, actual)
+	}
+	_, err = reader.Peek(1)
+	require.Error(t, err)
+
+	// A single metric with length-delimited framing must match the batch
+	s.LengthDelimited = true
+	single, err := s.Serialize(metrics[0])
+	require.NoError(t, err)
+	require.Equal(t, single, buf[:len(single)])
+}
+
+func TestSerializeErrors(t *testing.T) {
+	s := newSerializer()
+	require.NoError(t, s.Init())
+
+	m := metric.New("cpu", map[string]string{}, map[string]interface{}{"value": "high"}, time.Unix(0, 0))
+	_, err := s.Serialize(m)
+	require.ErrorContains(t, err, `setting field "value" of "cpu" failed`)
+
+	m = metric.New("cpu", map[string]string{}, map[string]interface{}{"severity": "FATAL"}, time.Unix(0, 0))
+	_, err = s.Serialize(m)
+	require.ErrorContains(t, err, `"FATAL" is not a value of enum "telegraf.test.Severity"`)
+
+	m = metric.New("cpu", map[string]string{}, map[string]interface{}{"other": "text"}, time.Unix(0, 0))
+	_, err = s.Serialize(m)
+	require.ErrorContains(t, err, `setting field "other" of "cpu" failed`)
+}
+
+func TestInitErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		modify   func(s *Serializer)
+		expected string
+	}{
+		{
+			name:     "unknown type",
+			modify:   func(s *Serializer) { s.MessageType = "telegraf.test.Unknown" },
+			expected: "not found",
+		},
+		{
+			name:     "unknown field",
+			modify:   func(s *Serializer) { s.Tags = map[string]string{"host": "source.hostname"} },
+			expected: `resolving path of tag "host" failed: message "telegraf.test.Source" has no field "hostname"`,
+		},
+		{
+			name:     "path through scalar",
+			modify:   func(s *Serializer) { s.Name = "value.name" },
+			expected: `field "value" of "value.name" is not a message`,
+		},
+		{
+			name:     "message as field",
+			modify:   func(s *Serializer) { s.Fields = map[string]string{"value": "source"} },
+			expected: `field "telegraf.test.Metric.source" is a message`,
+		},
+		{
+			name:     "no map",
+			modify:   func(s *Serializer) { s.TagsMap = "notes" },
+			expected: `field "telegraf.test.Metric.notes" is not a map`,
+		},
+		{
+			name:     "invalid time",
+			modify:   func(s *Serializer) { s.Time = "source" },
+			expected: `field "telegraf.test.Metric.source" is not a google.protobuf.Timestamp`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newSerializer()
+			tt.modify(s)
+			require.ErrorContains(t, s.Init(), tt.expected)
+		})
+	}
+}
+
+func TestRoundTripXPath(t *testing.T) {
+	s := &Serializer{
+		MessageFiles: []string{"metric.proto"},
+		MessageType:  "telegraf.test.Metric",
+		ImportPaths:  []string{"testcases"},
+		Name:         "name",
+		Time:         "timestamp_ms",
+		TimeFormat:   "unix_ms",
+		Tags:         map[string]string{"host": "source.host"},
+		Fields:       map[string]string{"value": "value", "count": "count"},
+		Log:          testutil.Logger{},
+	}
+	require.NoError(t, s.Init())
+
+	parser := &xpath.Parser{
+		Format:               "xpath_protobuf",
+		DefaultMetricName:    "protobuf",
+		ProtobufMessageFiles: []string{"metric.proto"},
+		ProtobufMessageType:  "telegraf.test.Metric",
+		ProtobufImportPaths:  []string{"testcases"},
+		NativeTypes:          true,
+		Configs: []xpath.Config{
+			{
+				MetricQuery:  "name",
+				Timestamp:    "timestamp_ms",
+				TimestampFmt: "unix_ms",
+				Tags:         map[string]string{"host": "source/host"},
+				Fields:       map[string]string{"value": "value", "count": "count"},
+			},
+		},
+		Log: testutil.Logger{},
+	}
+	require.NoError(t, parser.Init())
+
+	expected := metric.New(
+		"cpu",
+		map[string]string{"host": "a"},
+		map[string]interface{}{"value": 42.5, "count": int64(3)},
+		time.Unix(1700000000, 123000000),
+	)
+	buf, err := s.Serialize(expected)
+	require.NoError(t, err)
+
+	actual, err := parser.Parse(buf)
+	require.NoError(t, err)
+	testutil.RequireMetricsEqual(t, []telegraf.Metric{expected}, actual)
+}
+
+func BenchmarkSerialize(b *testing.B) {
+	s := &Serializer{
+		MessageFiles: []string{"metric.proto"},
+		MessageType:  "telegraf.test.Metric",
+		ImportPaths:  []string{"testcases"},
+		Name:         "name",
+		Time:         "time",
+		TagsMap:      "labels",
+		FieldsMap:    "values",
+		Log:          testutil.Logger{},
+	}
+	require.NoError(b, s.Init())
+	metrics := serializers.BenchmarkMetrics(b)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, err := s.Serialize(metrics[i%len(metrics)])
+		require.NoError(b, err)
+	}
+}
@@ -23,3 +23,17 @@ type SecretStore interface {
 // the secret will not change over time, or dynamic (true) to handle
 // secrets that change over time (e.g. TOTP).
 type ResolveFunc func() ([]byte, bool, error)
+
+// SecretStoreNotifier is an optional interface for secret stores that are
+// able to detect changes of their secrets, e.g. due to rotation or lease
+// renewal, and want to notify the plugins using those secrets.
+type SecretStoreNotifier interface {
+	// SetNotifier is called once after linking all secrets and registers
+	// the function the store must call whenever the secret for a key
+	// changed. An empty key denotes that all secrets might have changed.
+	SetNotifier(notify SecretNotifyFunc)
+}
+
+// SecretNotifyFunc is called by secret stores to announce the change of the
+// secret with the given key.
+type SecretNotifyFunc func(key string)
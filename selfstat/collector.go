@@ -115,7 +115,11 @@ func (s *Collector) Get(measurement, field string, tags map[string]string) Stat
 
 func (s *Collector) Reset(measurement, field string, tags map[string]string) {
 	key := collectorKey(measurement, field, tags)
-	if stats := s.statistics[key]; stats != nil {
+	switch stats := s.statistics[key].(type) {
+	case nil:
+	case *stat:
+		stats.reset()
+	default:
 		stats.Set(0)
 	}
 }
@@ -0,0 +1,146 @@
+package selfstat
+
+import (
+	"bufio"
+	"io"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// OpenMetricsContentType is the content-type of the OpenMetrics text format
+const OpenMetricsContentType = "application/openmetrics-text; version=1.0.0; charset=utf-8"
+
+type openMetricsSample struct {
+	suffix string
+	series string
+	labels string
+	value  string
+}
+
+type openMetricsFamily struct {
+	name    string
+	typ     string
+	unit    string
+	samples []openMetricsSample
+}
+
+// WriteOpenMetrics writes all registered statistics to the given writer using
+// the OpenMetrics text format. In contrast to Metrics(), the averages of timing
+// statistics are not reset, so the function can be used alongside the internal
+// input plugin. Timing statistics are reported as histograms in seconds.
+// Statistics only ever incremented are reported as counters with a "_total"
+// suffix, statistics which were set or decreased are reported as gauges.
+func WriteOpenMetrics(w io.Writer) error {
+	families := make(map[string]*openMetricsFamily)
+	add := func(name, typ, unit, suffix string, tags map[string]string, bucket, value string) {
+		f, found := families[name]
+		if !found {
+			f = &openMetricsFamily{name: name, typ: typ, unit: unit}
+			families[name] = f
+		}
+		series := openMetricsLabels(tags)
+		labels := series
+		if bucket != "" {
+			bucketTags := maps.Clone(tags)
+			bucketTags["le"] = bucket
+			labels = openMetricsLabels(bucketTags)
+		}
+		f.samples = append(f.samples, openMetricsSample{suffix: suffix, series: series, labels: labels, value: value})
+	}
+
+	registry.mu.Lock()
+	for _, stats := range registry.stats {
+		for fieldname, s := range stats {
+			measurement := s.Name()
+			tags := s.Tags()
+
+			// Timings are exported as histograms in seconds
+			if ts, ok := s.(*timingStat); ok {
+				name := openMetricsName(measurement, strings.TrimSuffix(fieldname, "_ns")) + "_seconds"
+				h := ts.histogram()
+				for i, bound := range h.bounds {
+					add(name, "histogram", "seconds", "_bucket", tags, formatSeconds(bound), strconv.FormatUint(h.buckets[i], 10))
+				}
+				add(name, "histogram", "seconds", "_bucket", tags, "+Inf", strconv.FormatUint(h.count, 10))
+				add(name, "histogram", "seconds", "_sum", tags, "", formatSeconds(h.sum))
+				add(name, "histogram", "seconds", "_count", tags, "", strconv.FormatUint(h.count, 10))
+				continue
+			}
+
+			name := openMetricsName(measurement, fieldname)
+			if st, ok := s.(*stat); ok && !st.gauge.Load() {
+				add(strings.TrimSuffix(name, "_total"), "counter", "", "_total", tags, "", strconv.FormatInt(s.Get(), 10))
+				continue
+			}
+			add(name, "gauge", "", "", tags, "", strconv.FormatInt(s.Get(), 10))
+		}
+
+		// Derive the fill ratio of output buffers
+		if size, limit := stats["buffer_size"], stats["buffer_limit"]; size != nil && limit != nil && limit.Get() > 0 {
+			ratio := float64(size.Get()) / float64(limit.Get())
+			name := openMetricsName(size.Name(), "buffer_fill_ratio")
+			add(name, "gauge", "", "", size.Tags(), "", strconv.FormatFloat(ratio, 'g', -1, 64))
+		}
+	}
+	registry.mu.Unlock()
+
+	// Output the families and samples in a stable order
+	bw := bufio.NewWriter(w)
+	for _, name := range slices.Sorted(maps.Keys(families)) {
+		f := families[name]
+		bw.WriteString("# TYPE " + f.name + " " + f.typ + "\n")
+		if f.unit != "" {
+			bw.WriteString("# UNIT " + f.name + " " + f.unit + "\n")
+		}
+		// Sort by series but keep the order of histogram buckets
+		slices.SortStableFunc(f.samples, func(a, b openMetricsSample) int {
+			return strings.Compare(a.series, b.series)
+		})
+		for _, s := range f.samples {
+			bw.WriteString(f.name + s.suffix + s.labels + " " + s.value + "\n")
+		}
+	}
+	bw.WriteString("# EOF\n")
+
+	return bw.Flush()
+}
+
+// openMetricsName creates a valid metric-family name for the given statistic
+func openMetricsName(measurement, field string) string {
+	name := "telegraf_" + strings.TrimPrefix(measurement, "internal_") + "_" + field
+	return strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_' || r == ':' {
+			return r
+		}
+		return '_'
+	}, name)
+}
+
+// openMetricsLabels formats the given tags as sorted label-set
+func openMetricsLabels(tags map[string]string) string {
+	if len(tags) == 0 {
+		return ""
+	}
+
+	parts := make([]string, 0, len(tags))
+	for _, k := range slices.Sorted(maps.Keys(tags)) {
+		key := strings.Map(func(r rune) rune {
+			if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_' {
+				return r
+			}
+			return '_'
+		}, k)
+		if key == "" || key[0] >= '0' && key[0] <= '9' {
+			key = "_" + key
+		}
+		value := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(tags[k])
+		parts = append(parts, key+`="`+value+`"`)
+	}
+	return "{" + strings.Join(parts, ",") + "}"
+}
+
+func formatSeconds(ns int64) string {
+	return strconv.FormatFloat(float64(ns)/1e9, 'g', -1, 64)
+}
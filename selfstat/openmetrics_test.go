@@ -0,0 +1,87 @@
+package selfstat
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteOpenMetrics(t *testing.T) {
+	defer testCleanup()
+
+	tags := map[string]string{"output": "file", "_id": "abc"}
+	Register("write", "buffer_size", tags).Set(25)
+	Register("write", "buffer_limit", tags).Set(100)
+	Register("write", "metrics_written", tags).Incr(42)
+	Register("write", "requests_total", tags).Incr(7)
+	inflight := Register("write", "inflight", tags)
+	inflight.Incr(3)
+	inflight.Incr(-1)
+	writeTime := RegisterTiming("write", "write_time_ns", tags)
+	writeTime.Incr(int64(3 * time.Millisecond))
+	writeTime.Incr(int64(2 * time.Second))
+	Register("gather", "errors", map[string]string{"input": `we"ird`}).Incr(3)
+
+	expected := `# TYPE telegraf_gather_errors counter
+telegraf_gather_errors_total{input="we\"ird"} 3
+# TYPE telegraf_write_buffer_fill_ratio gauge
+telegraf_write_buffer_fill_ratio{_id="abc",output="file"} 0.25
+# TYPE telegraf_write_buffer_limit gauge
+telegraf_write_buffer_limit{_id="abc",output="file"} 100
+# TYPE telegraf_write_buffer_size gauge
+telegraf_write_buffer_size{_id="abc",output="file"} 25
+# TYPE telegraf_write_inflight gauge
+telegraf_write_inflight{_id="abc",output="file"} 2
+# TYPE telegraf_write_metrics_written counter
+telegraf_write_metrics_written_total{_id="abc",output="file"} 42
+# TYPE telegraf_write_requests counter
+telegraf_write_requests_total{_id="abc",output="file"} 7
+# TYPE telegraf_write_write_time_seconds histogram
+# UNIT telegraf_write_write_time_seconds seconds
+telegraf_write_write_time_seconds_bucket{_id="abc",le="0.001",output="file"} 0
+telegraf_write_write_time_seconds_bucket{_id="abc",le="0.005",output="file"} 1
+telegraf_write_write_time_seconds_bucket{_id="abc",le="0.01",output="file"} 1
+telegraf_write_write_time_seconds_bucket{_id="abc",le="0.05",output="file"} 1
+telegraf_write_write_time_seconds_bucket{_id="abc",le="0.1",output="file"} 1
+telegraf_write_write_time_seconds_bucket{_id="abc",le="0.5",output="file"} 1
+telegraf_write_write_time_seconds_bucket{_id="abc",le="1",output="file"} 1
+telegraf_write_write_time_seconds_bucket{_id="abc",le="5",output="file"} 2
+telegraf_write_write_time_seconds_bucket{_id="abc",le="10",output="file"} 2
+telegraf_write_write_time_seconds_bucket{_id="abc",le="30",output="file"} 2
+telegraf_write_write_time_seconds_bucket{_id="abc",le="60",output="file"} 2
+telegraf_write_write_time_seconds_bucket{_id="abc",le="+Inf",output="file"} 2
+telegraf_write_write_time_seconds_sum{_id="abc",output="file"} 2.003
+telegraf_write_write_time_seconds_count{_id="abc",output="file"} 2
+# EOF
+`
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteOpenMetrics(&buf))
+	require.Equal(t, expected, buf.String())
+
+	// Writing must not reset the average of timings
+	require.Equal(t, int64(1_001_500_000), writeTime.Get())
+}
+
+func TestWriteOpenMetricsCounterReset(t *testing.T) {
+	defer testCleanup()
+
+	// Resetting a counter via the collector must keep the counter type
+	c := NewCollector(nil)
+	c.Register("gather", "errors", nil).Incr(3)
+	c.Reset("gather", "errors", nil)
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteOpenMetrics(&buf))
+	require.Equal(t, "# TYPE telegraf_gather_errors counter\ntelegraf_gather_errors_total 0\n# EOF\n", buf.String())
+}
+
+func TestWriteOpenMetricsEmpty(t *testing.T) {
+	defer testCleanup()
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteOpenMetrics(&buf))
+	require.Equal(t, "# EOF\n", buf.String())
+}
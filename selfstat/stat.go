@@ -9,16 +9,30 @@ type stat struct {
 	measurement string
 	field       string
 	tags        map[string]string
+
+	// gauge is set if the value was ever set or decreased, i.e. the stat
+	// is not a monotonic counter
+	gauge atomic.Bool
 }
 
 func (s *stat) Incr(v int64) {
+	if v < 0 {
+		s.gauge.Store(true)
+	}
 	atomic.AddInt64(&s.v, v)
 }
 
 func (s *stat) Set(v int64) {
+	s.gauge.Store(true)
 	atomic.StoreInt64(&s.v, v)
 }
 
+// reset sets the value to zero without marking the stat as gauge as this
+// is a counter reset for counters
+func (s *stat) reset() {
+	atomic.StoreInt64(&s.v, 0)
+}
+
 func (s *stat) Get() int64 {
 	return atomic.LoadInt64(&s.v)
 }
@@ -2,8 +2,25 @@ package selfstat
 
 import (
 	"sync"
+	"time"
 )
 
+// timingBuckets are the upper bounds of the histogram buckets for timings in
+// nanoseconds
+var timingBuckets = []int64{
+	int64(time.Millisecond),
+	int64(5 * time.Millisecond),
+	int64(10 * time.Millisecond),
+	int64(50 * time.Millisecond),
+	int64(100 * time.Millisecond),
+	int64(500 * time.Millisecond),
+	int64(time.Second),
+	int64(5 * time.Second),
+	int64(10 * time.Second),
+	int64(30 * time.Second),
+	int64(time.Minute),
+}
+
 type timingStat struct {
 	measurement string
 	field       string
@@ -12,15 +29,56 @@ type timingStat struct {
 	prev        int64
 	count       int64
 	mu          sync.Mutex
+
+	// Histogram of all timings, in contrast to the average, those values
+	// are never reset.
+	buckets []uint64
+	sum     int64
+	total   uint64
+}
+
+// histogram contains the cumulative counts of the timings per bucket
+type histogram struct {
+	bounds  []int64
+	buckets []uint64
+	sum     int64
+	count   uint64
 }
 
 func (s *timingStat) Incr(v int64) {
 	s.mu.Lock()
 	s.v += v
 	s.count++
+
+	if s.buckets == nil {
+		s.buckets = make([]uint64, len(timingBuckets))
+	}
+	for i, bound := range timingBuckets {
+		if v <= bound {
+			s.buckets[i]++
+		}
+	}
+	s.sum += v
+	s.total++
 	s.mu.Unlock()
 }
 
+// histogram returns a snapshot of the timing histogram without resetting any
+// state
+func (s *timingStat) histogram() histogram {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	h := histogram{
+		bounds:  timingBuckets,
+		buckets: make([]uint64, len(timingBuckets)),
+		sum:     s.sum,
+		count:   s.total,
+	}
+	copy(h.buckets, s.buckets)
+	return h
+}
+
 func (s *timingStat) Set(v int64) {
 	s.Incr(v)
 }